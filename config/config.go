@@ -16,10 +16,54 @@ type DatabaseConfig struct {
 	Name     string `yaml:"name"`
 }
 
+// InventoryConfig holds the inventory configuration
+type InventoryConfig struct {
+	// AdjustmentApprovalThresholdKg 库存调整重量超过该值时需要超级管理员审核
+	AdjustmentApprovalThresholdKg float64 `yaml:"adjustment_approval_threshold_kg"`
+}
+
+// PricingConfig holds the pricing configuration
+type PricingConfig struct {
+	// PriceDeviationPercent 订单单价偏离价目表超过该百分比时标记订单，0 表示不检查
+	PriceDeviationPercent float64 `yaml:"price_deviation_percent"`
+}
+
+// ScaleConfig holds the weighbridge configuration
+type ScaleConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Transport 连接方式: serial, tcp 或 simulator
+	Transport string `yaml:"transport"`
+	// Address 串口设备 (如 /dev/ttyUSB0) 或 TCP 地址 (如 192.168.1.50:4001)
+	Address  string `yaml:"address"`
+	BaudRate int    `yaml:"baud_rate"`
+	// Protocol 仪表输出协议: yaohua, toledo 或 ascii
+	Protocol string `yaml:"protocol"`
+	// StableWindowMs 读数在该时长内波动不超过 StableToleranceKg 时视为稳定
+	StableWindowMs    int     `yaml:"stable_window_ms"`
+	StableToleranceKg float64 `yaml:"stable_tolerance_kg"`
+	// StaleAfterMs 超过该时长没有收到数据时视为地磅离线
+	StaleAfterMs int `yaml:"stale_after_ms"`
+	// TareDeviationPercent 磅单实测皮重偏离车辆登记皮重超过该百分比时标记，0 表示不检查
+	TareDeviationPercent float64 `yaml:"tare_deviation_percent"`
+}
+
+// CompanyConfig holds the company information printed on documents
+type CompanyConfig struct {
+	Name          string `yaml:"name"`
+	Address       string `yaml:"address"`
+	LicenseNo     string `yaml:"license_no"` // 危险废物经营许可证编号
+	ContactPerson string `yaml:"contact_person"`
+	Phone         string `yaml:"phone"`
+}
+
 // Config holds the application configuration
 type Config struct {
-	Database DatabaseConfig `yaml:"database"`
-	Server   struct {
+	Database  DatabaseConfig  `yaml:"database"`
+	Inventory InventoryConfig `yaml:"inventory"`
+	Pricing   PricingConfig   `yaml:"pricing"`
+	Scale     ScaleConfig     `yaml:"scale"`
+	Company   CompanyConfig   `yaml:"company"`
+	Server    struct {
 		Port string `yaml:"port"`
 		Mode string `yaml:"mode"`
 	} `yaml:"server"`
@@ -74,6 +118,10 @@ func LoadConfig() (*Config, error) {
 		panic("MODE is not set")
 	}
 
+	if config.Inventory.AdjustmentApprovalThresholdKg <= 0 {
+		config.Inventory.AdjustmentApprovalThresholdKg = 100
+	}
+
 	return &config, nil
 }
 
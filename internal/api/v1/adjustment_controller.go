@@ -0,0 +1,234 @@
+package v1
+
+import (
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdjustmentController struct {
+	adjustmentService *services.AdjustmentService
+}
+
+func NewAdjustmentController(adjustmentService *services.AdjustmentService) *AdjustmentController {
+	return &AdjustmentController{
+		adjustmentService: adjustmentService,
+	}
+}
+
+// GetAll godoc
+// @Summary      获取库存调整单列表
+// @Description  分页获取库存调整单，支持按状态和分类筛选
+// @Tags         库存调整
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page query int false "页码" default(1)
+// @Param        page_size query int false "每页数量" default(20)
+// @Param        status query string false "状态 (pending/approved/rejected)"
+// @Param        category_id query int false "分类ID"
+// @Param        warehouse_id query int false "仓库ID"
+// @Success      200 {object} models.Response{data=models.GetInventoryAdjustmentsResponse} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /inventory/adjustments [get]
+func (ctrl *AdjustmentController) GetAll(c *gin.Context) {
+	var req models.GetInventoryAdjustmentsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid query parameters",
+		})
+		return
+	}
+
+	// 设置默认值
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+
+	resp, err := ctrl.adjustmentService.GetAll(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: resp,
+	})
+}
+
+// Create godoc
+// @Summary      创建库存调整单
+// @Description  按分类增加或减少库存并记录原因，调整重量超过审核阈值时需超级管理员审核后才记入库存
+// @Tags         库存调整
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        adjustment body models.CreateInventoryAdjustmentRequest true "调整信息"
+// @Success      200 {object} models.Response{data=models.InventoryAdjustment} "创建成功"
+// @Failure      200 {object} models.Response "创建失败"
+// @Router       /inventory/adjustments [post]
+func (ctrl *AdjustmentController) Create(c *gin.Context) {
+	var req models.CreateInventoryAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid request data",
+		})
+		return
+	}
+
+	// Get current user from context
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	adjustment, err := ctrl.adjustmentService.Create(&req, userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	msg := "Adjustment posted successfully"
+	if adjustment.RequiresApproval {
+		msg = "Adjustment submitted for approval"
+	}
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  msg,
+		Data: adjustment,
+	})
+}
+
+// GetByID godoc
+// @Summary      根据ID获取库存调整单
+// @Description  根据ID获取库存调整单详情
+// @Tags         库存调整
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "调整单ID"
+// @Success      200 {object} models.Response{data=models.InventoryAdjustment} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /inventory/adjustments/{id} [get]
+func (ctrl *AdjustmentController) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid adjustment ID",
+		})
+		return
+	}
+
+	adjustment, err := ctrl.adjustmentService.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeNotFound,
+			Msg:  "Adjustment not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: adjustment,
+	})
+}
+
+// Approve godoc
+// @Summary      审核库存调整单
+// @Description  审核待审核的库存调整单并记入库存 (需要超级管理员权限)
+// @Tags         库存调整
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "调整单ID"
+// @Param        review body models.ReviewInventoryAdjustmentRequest false "审核意见"
+// @Success      200 {object} models.Response{data=models.InventoryAdjustment} "审核成功"
+// @Failure      200 {object} models.Response "审核失败"
+// @Router       /inventory/adjustments/{id}/approve [post]
+func (ctrl *AdjustmentController) Approve(c *gin.Context) {
+	ctrl.review(c, ctrl.adjustmentService.Approve, "Adjustment approved successfully")
+}
+
+// Reject godoc
+// @Summary      驳回库存调整单
+// @Description  驳回待审核的库存调整单，不调整库存 (需要超级管理员权限)
+// @Tags         库存调整
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "调整单ID"
+// @Param        review body models.ReviewInventoryAdjustmentRequest false "驳回原因"
+// @Success      200 {object} models.Response{data=models.InventoryAdjustment} "驳回成功"
+// @Failure      200 {object} models.Response "驳回失败"
+// @Router       /inventory/adjustments/{id}/reject [post]
+func (ctrl *AdjustmentController) Reject(c *gin.Context) {
+	ctrl.review(c, ctrl.adjustmentService.Reject, "Adjustment rejected successfully")
+}
+
+// review 审核或驳回库存调整单并返回处理后的调整单
+func (ctrl *AdjustmentController) review(c *gin.Context, action func(id uint, req *models.ReviewInventoryAdjustmentRequest, userID uint) error, msg string) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid adjustment ID",
+		})
+		return
+	}
+
+	// 审核意见可选，允许空请求体
+	var req models.ReviewInventoryAdjustmentRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusOK, &models.Response{
+				Code: models.CodeBadRequest,
+				Msg:  "Invalid request data",
+			})
+			return
+		}
+	}
+
+	// Get current user from context
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	if err := action(uint(id), &req, userModel.ID); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	adjustment, err := ctrl.adjustmentService.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeSuccess,
+			Msg:  msg,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  msg,
+		Data: adjustment,
+	})
+}
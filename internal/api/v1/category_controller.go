@@ -67,7 +67,11 @@ func (ctrl *CategoryController) Create(c *gin.Context) {
 		return
 	}
 
-	if err := ctrl.categoryService.Create(&category); err != nil {
+	// Get current user from context
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	if err := ctrl.categoryService.Create(&category, userModel.ID); err != nil {
 		c.JSON(http.StatusOK, &models.Response{
 			Code: models.CodeInternalError,
 			Msg:  err.Error(),
@@ -158,8 +162,8 @@ func (ctrl *CategoryController) Update(c *gin.Context) {
 	if category.Description != "" {
 		updates["description"] = category.Description
 	}
-	if category.UnitPrice > 0 {
-		updates["unit_price"] = category.UnitPrice
+	if category.WasteCode != "" {
+		updates["waste_code"] = category.WasteCode
 	}
 
 	if err := ctrl.categoryService.UpdateCategory(uint(id), updates); err != nil {
@@ -170,6 +174,31 @@ func (ctrl *CategoryController) Update(c *gin.Context) {
 		return
 	}
 
+	// 单价修改记入价格历史
+	if category.UnitPrice > 0 {
+		// Get current user from context
+		user, _ := c.Get("user")
+		userModel := user.(*models.User)
+
+		if err := ctrl.categoryService.UpdateUnitPrice(uint(id), category.UnitPrice, userModel.ID); err != nil {
+			c.JSON(http.StatusOK, &models.Response{
+				Code: models.CodeInternalError,
+				Msg:  err.Error(),
+			})
+			return
+		}
+	}
+
+	if category.CostingMethod != "" {
+		if err := ctrl.categoryService.UpdateCostingMethod(uint(id), category.CostingMethod); err != nil {
+			c.JSON(http.StatusOK, &models.Response{
+				Code: models.CodeInternalError,
+				Msg:  err.Error(),
+			})
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, &models.Response{
 		Code: models.CodeSuccess,
 		Msg:  "Category updated successfully",
@@ -0,0 +1,284 @@
+package v1
+
+import (
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CustomerController struct {
+	customerService *services.CustomerService
+}
+
+func NewCustomerController(customerService *services.CustomerService) *CustomerController {
+	return &CustomerController{
+		customerService: customerService,
+	}
+}
+
+// GetAll godoc
+// @Summary      获取客户列表
+// @Description  获取客户列表，支持按名称、联系人或电话模糊搜索
+// @Tags         客户管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        keyword query string false "名称、联系人或电话 (支持模糊搜索)"
+// @Success      200 {object} models.Response{data=[]models.Customer} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /customers [get]
+func (ctrl *CustomerController) GetAll(c *gin.Context) {
+	customers, err := ctrl.customerService.Search(c.Query("keyword"))
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: customers,
+	})
+}
+
+// Create godoc
+// @Summary      创建客户
+// @Description  创建新的客户及其送货地址，设置信用额度需要超级管理员权限
+// @Tags         客户管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        customer body models.CreateCustomerRequest true "客户信息"
+// @Success      200 {object} models.Response{data=models.Customer} "创建成功"
+// @Failure      200 {object} models.Response "创建失败"
+// @Router       /customers [post]
+func (ctrl *CustomerController) Create(c *gin.Context) {
+	var req models.CreateCustomerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid request data",
+		})
+		return
+	}
+
+	// 设置信用额度需要超级管理员权限
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+	if req.CreditLimit > 0 && userModel.Role != "super_admin" {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeForbidden,
+			Msg:  "Only super_admin can set credit limit",
+		})
+		return
+	}
+
+	customer, err := ctrl.customerService.Create(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "Customer created successfully",
+		Data: customer,
+	})
+}
+
+// GetByID godoc
+// @Summary      根据ID获取客户
+// @Description  根据客户ID获取客户信息，包含送货地址
+// @Tags         客户管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "客户ID"
+// @Success      200 {object} models.Response{data=models.Customer} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /customers/{id} [get]
+func (ctrl *CustomerController) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid customer ID",
+		})
+		return
+	}
+
+	customer, err := ctrl.customerService.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeNotFound,
+			Msg:  "Customer not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: customer,
+	})
+}
+
+// Update godoc
+// @Summary      更新客户
+// @Description  根据ID更新客户信息，提供地址列表时整体替换送货地址，修改信用额度需要超级管理员权限
+// @Tags         客户管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "客户ID"
+// @Param        customer body models.UpdateCustomerRequest true "客户信息"
+// @Success      200 {object} models.Response{data=models.Customer} "更新成功"
+// @Failure      200 {object} models.Response "更新失败"
+// @Router       /customers/{id} [put]
+func (ctrl *CustomerController) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid customer ID",
+		})
+		return
+	}
+
+	var req models.UpdateCustomerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid request data",
+		})
+		return
+	}
+
+	// 设置信用额度需要超级管理员权限
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+	if req.CreditLimit != nil && userModel.Role != "super_admin" {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeForbidden,
+			Msg:  "Only super_admin can set credit limit",
+		})
+		return
+	}
+
+	customer, err := ctrl.customerService.Update(uint(id), &req)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "Customer updated successfully",
+		Data: customer,
+	})
+}
+
+// Delete godoc
+// @Summary      删除客户
+// @Description  根据ID停用客户，历史订单保留客户名称快照
+// @Tags         客户管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "客户ID"
+// @Success      200 {object} models.Response "删除成功"
+// @Failure      200 {object} models.Response "删除失败"
+// @Router       /customers/{id} [delete]
+func (ctrl *CustomerController) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid customer ID",
+		})
+		return
+	}
+
+	if err := ctrl.customerService.Delete(uint(id)); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "Customer deleted successfully",
+	})
+}
+
+// GetOrders godoc
+// @Summary      获取客户历史订单
+// @Description  分页获取客户的出库订单，并汇总已记账订单的重量和金额
+// @Tags         客户管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "客户ID"
+// @Param        page query int false "页码" default(1)
+// @Param        page_size query int false "每页数量" default(20)
+// @Param        start_date query string false "开始日期 (YYYY-MM-DD)"
+// @Param        end_date query string false "结束日期 (YYYY-MM-DD)"
+// @Success      200 {object} models.Response{data=models.CustomerOrderHistory} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /customers/{id}/orders [get]
+func (ctrl *CustomerController) GetOrders(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid customer ID",
+		})
+		return
+	}
+
+	var req models.GetCustomerOrdersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid query parameters",
+		})
+		return
+	}
+
+	// 设置默认值
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+
+	history, err := ctrl.customerService.GetOrderHistory(uint(id), &req)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: history,
+	})
+}
@@ -0,0 +1,263 @@
+package v1
+
+import (
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CustomerReceiptController struct {
+	receiptService *services.CustomerReceiptService
+}
+
+func NewCustomerReceiptController(receiptService *services.CustomerReceiptService) *CustomerReceiptController {
+	return &CustomerReceiptController{
+		receiptService: receiptService,
+	}
+}
+
+// Create godoc
+// @Summary      登记客户收款
+// @Description  登记现金、银行转账或移动支付收款，核销同一客户的一张或多张已记账出库订单，支持部分收款
+// @Tags         应收账款
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        receipt body models.CreateCustomerReceiptRequest true "收款信息"
+// @Success      200 {object} models.Response{data=models.CustomerReceipt} "登记成功"
+// @Failure      200 {object} models.Response "登记失败"
+// @Router       /customer-receipts [post]
+func (ctrl *CustomerReceiptController) Create(c *gin.Context) {
+	var req models.CreateCustomerReceiptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid request data",
+		})
+		return
+	}
+
+	// Get current user from context
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	receipt, err := ctrl.receiptService.Create(&req, userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "Receipt recorded successfully",
+		Data: receipt,
+	})
+}
+
+// GetAll godoc
+// @Summary      获取客户收款列表
+// @Description  分页获取收款单，支持按客户、客户名称、收款方式、状态和收款日期筛选
+// @Tags         应收账款
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page query int false "页码" default(1)
+// @Param        page_size query int false "每页数量" default(20)
+// @Param        customer_id query int false "客户ID"
+// @Param        customer query string false "客户名称"
+// @Param        method query string false "收款方式 (cash/bank_transfer/mobile_pay)"
+// @Param        status query string false "状态 (posted/voided)"
+// @Param        start_date query string false "开始日期 (YYYY-MM-DD)"
+// @Param        end_date query string false "结束日期 (YYYY-MM-DD)"
+// @Success      200 {object} models.Response{data=models.GetCustomerReceiptsResponse} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /customer-receipts [get]
+func (ctrl *CustomerReceiptController) GetAll(c *gin.Context) {
+	var req models.GetCustomerReceiptsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid query parameters",
+		})
+		return
+	}
+
+	// 设置默认值
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+
+	resp, err := ctrl.receiptService.GetAll(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: resp,
+	})
+}
+
+// GetByID godoc
+// @Summary      根据ID获取客户收款
+// @Description  获取收款单及其核销到各出库订单的金额
+// @Tags         应收账款
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "收款单ID"
+// @Success      200 {object} models.Response{data=models.CustomerReceipt} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /customer-receipts/{id} [get]
+func (ctrl *CustomerReceiptController) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid receipt ID",
+		})
+		return
+	}
+
+	receipt, err := ctrl.receiptService.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeNotFound,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: receipt,
+	})
+}
+
+// Void godoc
+// @Summary      作废客户收款
+// @Description  作废收款单并冲回各出库订单的已收金额 (需要超级管理员权限)
+// @Tags         应收账款
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "收款单ID"
+// @Success      200 {object} models.Response "作废成功"
+// @Failure      200 {object} models.Response "作废失败"
+// @Router       /customer-receipts/{id}/void [post]
+func (ctrl *CustomerReceiptController) Void(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid receipt ID",
+		})
+		return
+	}
+
+	// Get current user from context
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	if err := ctrl.receiptService.Void(uint(id), userModel.ID); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "Receipt voided successfully",
+	})
+}
+
+// GetCustomerBalance godoc
+// @Summary      获取客户应收余额
+// @Description  汇总客户已记账出库订单的应收、已收和未收金额及剩余信用额度，并列出未收清的订单
+// @Tags         应收账款
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "客户ID"
+// @Success      200 {object} models.Response{data=models.CustomerBalance} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /customers/{id}/balance [get]
+func (ctrl *CustomerReceiptController) GetCustomerBalance(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid customer ID",
+		})
+		return
+	}
+
+	balance, err := ctrl.receiptService.GetCustomerBalance(uint(id))
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: balance,
+	})
+}
+
+// GetAging godoc
+// @Summary      应收账款账龄
+// @Description  按客户汇总截至指定日期的未收金额，按订单创建日期分为 0-30、31-60、61-90 和 90 天以上
+// @Tags         应收账款
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        as_of query string false "截止日期 (YYYY-MM-DD)，默认今天"
+// @Success      200 {object} models.Response{data=models.ReceivablesAgingReport} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /reports/receivables-aging [get]
+func (ctrl *CustomerReceiptController) GetAging(c *gin.Context) {
+	var req models.GetAgingRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid query parameters",
+		})
+		return
+	}
+
+	report, err := ctrl.receiptService.GetAging(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: report,
+	})
+}
@@ -147,14 +147,14 @@ func (ctrl *InboundController) GetByID(c *gin.Context) {
 
 // Update godoc
 // @Summary      更新入库订单
-// @Description  根据ID更新入库订单信息
+// @Description  根据订单ID更新入库订单，支持订单项的新增、修改和删除，并同步调整库存
 // @Tags         入库管理
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id path int true "订单ID"
-// @Param        order body models.InboundOrder true "入库订单信息"
-// @Success      200 {object} models.Response{data=models.InboundOrder} "更新成功"
+// @Param        request body models.UpdateInboundOrderRequest true "更新入库订单请求"
+// @Success      200 {object} models.Response{data=models.GetInboudOrderDetailResp} "更新成功"
 // @Failure      200 {object} models.Response "更新失败"
 // @Router       /inbound/orders/{id} [put]
 func (ctrl *InboundController) Update(c *gin.Context) {
@@ -167,34 +167,46 @@ func (ctrl *InboundController) Update(c *gin.Context) {
 		return
 	}
 
-	var order models.InboundOrder
-	if err := c.ShouldBindJSON(&order); err != nil {
+	var req models.UpdateInboundOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusOK, &models.Response{
 			Code: models.CodeBadRequest,
-			Msg:  "Invalid request data",
+			Msg:  "Invalid request data: " + err.Error(),
 		})
 		return
 	}
 
-	// 构建更新字段映射
-	updates := make(map[string]interface{})
-	if order.SupplierName != "" {
-		updates["supplier_name"] = order.SupplierName
-	}
-	if order.Status != "" {
-		updates["status"] = order.Status
-	}
-	if order.Notes != "" {
-		updates["notes"] = order.Notes
-	}
-	if order.TotalAmount > 0 {
-		updates["total_amount"] = order.TotalAmount
+	// Get current user from context
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	// 判断是否需要更新订单项
+	if len(req.Items) > 0 {
+		// 完整更新（包括订单项）
+		if err := ctrl.inboundService.UpdateOrderComplete(uint(id), &req, userModel.ID); err != nil {
+			c.JSON(http.StatusOK, &models.Response{
+				Code: models.CodeInternalError,
+				Msg:  err.Error(),
+			})
+			return
+		}
+	} else {
+		// 仅更新基本信息
+		if err := ctrl.inboundService.UpdateOrderBasic(uint(id), &req, userModel.ID); err != nil {
+			c.JSON(http.StatusOK, &models.Response{
+				Code: models.CodeInternalError,
+				Msg:  err.Error(),
+			})
+			return
+		}
 	}
 
-	if err := ctrl.inboundService.UpdateOrder(uint(id), updates); err != nil {
+	// 获取更新后的订单详情
+	updatedOrder, err := ctrl.inboundService.GetByID(uint(id))
+	if err != nil {
 		c.JSON(http.StatusOK, &models.Response{
-			Code: models.CodeInternalError,
-			Msg:  err.Error(),
+			Code: models.CodeSuccess,
+			Msg:  "Order updated successfully, but failed to retrieve updated data",
 		})
 		return
 	}
@@ -202,7 +214,7 @@ func (ctrl *InboundController) Update(c *gin.Context) {
 	c.JSON(http.StatusOK, &models.Response{
 		Code: models.CodeSuccess,
 		Msg:  "Order updated successfully",
-		Data: order,
+		Data: updatedOrder,
 	})
 }
 
@@ -227,7 +239,11 @@ func (ctrl *InboundController) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := ctrl.inboundService.Delete(uint(id)); err != nil {
+	// Get current user from context
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	if err := ctrl.inboundService.Delete(uint(id), userModel.ID); err != nil {
 		c.JSON(http.StatusOK, &models.Response{
 			Code: models.CodeInternalError,
 			Msg:  err.Error(),
@@ -240,3 +256,87 @@ func (ctrl *InboundController) Delete(c *gin.Context) {
 		Msg:  "Order deleted successfully",
 	})
 }
+
+// Confirm godoc
+// @Summary      确认入库订单
+// @Description  确认草稿订单，确认时记入库存
+// @Tags         入库管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "订单ID"
+// @Success      200 {object} models.Response "操作成功"
+// @Failure      200 {object} models.Response "操作失败"
+// @Router       /inbound/orders/{id}/confirm [post]
+func (ctrl *InboundController) Confirm(c *gin.Context) {
+	ctrl.changeStatus(c, ctrl.inboundService.Confirm, "Order confirmed successfully")
+}
+
+// Complete godoc
+// @Summary      完成入库订单
+// @Description  将已确认的订单标记为已完成
+// @Tags         入库管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "订单ID"
+// @Success      200 {object} models.Response "操作成功"
+// @Failure      200 {object} models.Response "操作失败"
+// @Router       /inbound/orders/{id}/complete [post]
+func (ctrl *InboundController) Complete(c *gin.Context) {
+	ctrl.changeStatus(c, ctrl.inboundService.Complete, "Order completed successfully")
+}
+
+// Cancel godoc
+// @Summary      取消入库订单
+// @Description  取消订单，已记入库存的订单同时冲回库存
+// @Tags         入库管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "订单ID"
+// @Success      200 {object} models.Response "操作成功"
+// @Failure      200 {object} models.Response "操作失败"
+// @Router       /inbound/orders/{id}/cancel [post]
+func (ctrl *InboundController) Cancel(c *gin.Context) {
+	ctrl.changeStatus(c, ctrl.inboundService.Cancel, "Order cancelled successfully")
+}
+
+// changeStatus 执行订单状态流转并返回流转后的订单详情
+func (ctrl *InboundController) changeStatus(c *gin.Context, transition func(id uint, userID uint) error, msg string) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid order ID",
+		})
+		return
+	}
+
+	// Get current user from context
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	if err := transition(uint(id), userModel.ID); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	order, err := ctrl.inboundService.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeSuccess,
+			Msg:  msg,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  msg,
+		Data: order,
+	})
+}
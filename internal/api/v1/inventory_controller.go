@@ -21,16 +21,22 @@ func NewInventoryController(inventoryService *services.InventoryService) *Invent
 
 // GetAll godoc
 // @Summary      获取所有库存
-// @Description  获取所有电池分类的库存信息
+// @Description  获取各仓库各电池分类的库存信息，支持按仓库筛选
 // @Tags         库存管理
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Param        warehouse_id query int false "仓库ID"
 // @Success      200 {object} models.Response{data=[]models.Inventory} "获取成功"
 // @Failure      200 {object} models.Response "获取失败"
 // @Router       /inventory [get]
 func (ctrl *InventoryController) GetAll(c *gin.Context) {
-	inventories, err := ctrl.inventoryService.GetAll()
+	warehouseID, ok := queryID(c, "warehouse_id", "Invalid warehouse ID")
+	if !ok {
+		return
+	}
+
+	inventories, err := ctrl.inventoryService.GetAll(warehouseID)
 	if err != nil {
 		c.JSON(http.StatusOK, &models.Response{
 			Code: models.CodeInternalError,
@@ -48,12 +54,13 @@ func (ctrl *InventoryController) GetAll(c *gin.Context) {
 
 // GetByCategoryID godoc
 // @Summary      根据分类ID获取库存
-// @Description  根据电池分类ID获取该分类的库存信息
+// @Description  根据电池分类ID获取该分类在指定仓库的库存，未指定仓库时返回全部仓库合计
 // @Tags         库存管理
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        categoryId path int true "分类ID"
+// @Param        warehouse_id query int false "仓库ID"
 // @Success      200 {object} models.Response{data=models.Inventory} "获取成功"
 // @Failure      200 {object} models.Response "获取失败"
 // @Router       /inventory/{categoryId} [get]
@@ -67,7 +74,12 @@ func (ctrl *InventoryController) GetByCategoryID(c *gin.Context) {
 		return
 	}
 
-	inventory, err := ctrl.inventoryService.GetByCategoryID(uint(categoryID))
+	warehouseID, ok := queryID(c, "warehouse_id", "Invalid warehouse ID")
+	if !ok {
+		return
+	}
+
+	inventory, err := ctrl.inventoryService.GetByCategoryID(uint(categoryID), warehouseID)
 	if err != nil {
 		c.JSON(http.StatusOK, &models.Response{
 			Code: models.CodeNotFound,
@@ -82,3 +94,117 @@ func (ctrl *InventoryController) GetByCategoryID(c *gin.Context) {
 		Data: inventory,
 	})
 }
+
+// GetMovements godoc
+// @Summary      获取库存流水
+// @Description  根据电池分类ID分页获取库存流水，支持按仓库和日期筛选
+// @Tags         库存管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        categoryId path int true "分类ID"
+// @Param        page query int false "页码" default(1)
+// @Param        page_size query int false "每页数量" default(20)
+// @Param        start_date query string false "开始日期 (YYYY-MM-DD)"
+// @Param        end_date query string false "结束日期 (YYYY-MM-DD)"
+// @Param        warehouse_id query int false "仓库ID"
+// @Success      200 {object} models.Response{data=models.GetInventoryMovementsResponse} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /inventory/{categoryId}/movements [get]
+func (ctrl *InventoryController) GetMovements(c *gin.Context) {
+	categoryID, err := strconv.ParseUint(c.Param("categoryId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid category ID",
+		})
+		return
+	}
+
+	var req models.GetInventoryMovementsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid query parameters",
+		})
+		return
+	}
+
+	// 设置默认值
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+
+	movements, total, err := ctrl.inventoryService.GetMovements(uint(categoryID), &req)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	resp := models.GetInventoryMovementsResponse{
+		Movements: movements,
+		Total:     total,
+	}
+
+	c.JSON(http.StatusOK, &models.Response{Code: models.CodeSuccess, Msg: "success", Data: resp})
+}
+
+// GetConsolidated godoc
+// @Summary      获取库存合计
+// @Description  按电池分类汇总全部仓库的库存重量和金额，并列出各仓库明细
+// @Tags         库存管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} models.Response{data=[]models.ConsolidatedInventory} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /inventory/consolidated [get]
+func (ctrl *InventoryController) GetConsolidated(c *gin.Context) {
+	inventories, err := ctrl.inventoryService.GetConsolidated()
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: inventories,
+	})
+}
+
+// CheckLedger godoc
+// @Summary      库存流水对账
+// @Description  根据库存流水重算各仓库各分类结存，并与当前库存比对
+// @Tags         库存管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} models.Response{data=[]models.InventoryLedgerCheck} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /inventory/ledger-check [get]
+func (ctrl *InventoryController) CheckLedger(c *gin.Context) {
+	checks, err := ctrl.inventoryService.CheckLedger()
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: checks,
+	})
+}
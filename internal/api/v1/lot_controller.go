@@ -0,0 +1,182 @@
+package v1
+
+import (
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LotController struct {
+	lotService *services.LotService
+}
+
+func NewLotController(lotService *services.LotService) *LotController {
+	return &LotController{
+		lotService: lotService,
+	}
+}
+
+// GetAll godoc
+// @Summary      获取批次列表
+// @Description  分页获取批次，支持按仓库、分类、入库订单和供应商筛选
+// @Tags         批次追溯
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page query int false "页码" default(1)
+// @Param        page_size query int false "每页数量" default(20)
+// @Param        warehouse_id query int false "仓库ID"
+// @Param        category_id query int false "分类ID"
+// @Param        inbound_order_id query int false "入库订单ID"
+// @Param        seller_id query int false "卖家ID"
+// @Param        available query bool false "仅返回有剩余重量的批次"
+// @Success      200 {object} models.Response{data=models.GetLotsResponse} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /lots [get]
+func (ctrl *LotController) GetAll(c *gin.Context) {
+	var req models.GetLotsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid query parameters",
+		})
+		return
+	}
+
+	// 设置默认值
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+
+	resp, err := ctrl.lotService.GetAll(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: resp,
+	})
+}
+
+// GetByID godoc
+// @Summary      根据ID获取批次
+// @Description  获取批次的供应商、交货时间、等级和剩余重量
+// @Tags         批次追溯
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "批次ID"
+// @Success      200 {object} models.Response{data=models.Lot} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /lots/{id} [get]
+func (ctrl *LotController) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid lot ID",
+		})
+		return
+	}
+
+	lot, err := ctrl.lotService.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeNotFound,
+			Msg:  "Lot not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: lot,
+	})
+}
+
+// GetGenealogy godoc
+// @Summary      获取批次谱系
+// @Description  上溯批次的父批次链到供应商交货，下溯调拨子批次以及消耗各批次的出库订单、调拨、库存调整和盘点
+// @Tags         批次追溯
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "批次ID"
+// @Success      200 {object} models.Response{data=models.LotGenealogy} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /lots/{id}/genealogy [get]
+func (ctrl *LotController) GetGenealogy(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid lot ID",
+		})
+		return
+	}
+
+	genealogy, err := ctrl.lotService.GetGenealogy(uint(id))
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeNotFound,
+			Msg:  "Lot not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: genealogy,
+	})
+}
+
+// GetShipmentTrace godoc
+// @Summary      获取出库订单批次来源
+// @Description  获取出库订单各订单项消耗的批次及其供应商、入库订单和交货时间
+// @Tags         批次追溯
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "出库订单ID"
+// @Success      200 {object} models.Response{data=[]models.ShipmentLotTrace} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /outbound/orders/{id}/lots [get]
+func (ctrl *LotController) GetShipmentTrace(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid order ID",
+		})
+		return
+	}
+
+	traces, err := ctrl.lotService.GetShipmentTrace(uint(id))
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: traces,
+	})
+}
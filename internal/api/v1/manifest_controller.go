@@ -0,0 +1,340 @@
+package v1
+
+import (
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/services"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ManifestController struct {
+	manifestService *services.ManifestService
+}
+
+func NewManifestController(manifestService *services.ManifestService) *ManifestController {
+	return &ManifestController{
+		manifestService: manifestService,
+	}
+}
+
+// Generate godoc
+// @Summary      开具危险废物转移联单
+// @Description  为已确认或已完成的出库订单开具转移联单，按分类汇总重量并生成年度连续编号；订单未关联客户时须填写接收单位
+// @Tags         危废联单
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "出库订单ID"
+// @Param        manifest body models.CreateWasteManifestRequest false "接收单位信息"
+// @Success      200 {object} models.Response{data=models.WasteManifest} "开具成功"
+// @Failure      200 {object} models.Response "开具失败"
+// @Router       /outbound/orders/{id}/manifest [post]
+func (ctrl *ManifestController) Generate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid order ID",
+		})
+		return
+	}
+
+	// 接收单位信息可选，允许空请求体
+	var req models.CreateWasteManifestRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusOK, &models.Response{
+				Code: models.CodeBadRequest,
+				Msg:  "Invalid request data",
+			})
+			return
+		}
+	}
+
+	// Get current user from context
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	manifest, err := ctrl.manifestService.Generate(uint(id), &req, userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "Manifest issued successfully",
+		Data: manifest,
+	})
+}
+
+// GetAll godoc
+// @Summary      获取转移联单列表
+// @Description  分页获取转移联单，支持按状态和开具日期筛选
+// @Tags         危废联单
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page query int false "页码" default(1)
+// @Param        page_size query int false "每页数量" default(20)
+// @Param        status query string false "状态 (issued/received/closed)"
+// @Param        start_date query string false "开始日期 (YYYY-MM-DD)"
+// @Param        end_date query string false "结束日期 (YYYY-MM-DD，包含当天)"
+// @Success      200 {object} models.Response{data=models.GetWasteManifestsResponse} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /manifests [get]
+func (ctrl *ManifestController) GetAll(c *gin.Context) {
+	var req models.GetWasteManifestsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid query parameters",
+		})
+		return
+	}
+
+	// 设置默认值
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+
+	resp, err := ctrl.manifestService.GetAll(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: resp,
+	})
+}
+
+// GetByID godoc
+// @Summary      根据ID获取转移联单
+// @Description  获取转移联单及废物明细
+// @Tags         危废联单
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "联单ID"
+// @Success      200 {object} models.Response{data=models.WasteManifest} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /manifests/{id} [get]
+func (ctrl *ManifestController) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid manifest ID",
+		})
+		return
+	}
+
+	manifest, err := ctrl.manifestService.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeNotFound,
+			Msg:  "Manifest not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: manifest,
+	})
+}
+
+// Receive godoc
+// @Summary      联单签收
+// @Description  登记接收单位签收，未填写签收重量时按转移重量签收
+// @Tags         危废联单
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "联单ID"
+// @Param        receipt body models.ReceiveWasteManifestRequest false "签收信息"
+// @Success      200 {object} models.Response{data=models.WasteManifest} "签收成功"
+// @Failure      200 {object} models.Response "签收失败"
+// @Router       /manifests/{id}/receive [post]
+func (ctrl *ManifestController) Receive(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid manifest ID",
+		})
+		return
+	}
+
+	// 签收信息可选，允许空请求体
+	var req models.ReceiveWasteManifestRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusOK, &models.Response{
+				Code: models.CodeBadRequest,
+				Msg:  "Invalid request data",
+			})
+			return
+		}
+	}
+
+	// Get current user from context
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	if err := ctrl.manifestService.Receive(uint(id), &req, userModel.ID); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	ctrl.respondManifest(c, uint(id), "Manifest received successfully")
+}
+
+// Close godoc
+// @Summary      办结联单
+// @Description  办结已签收的转移联单
+// @Tags         危废联单
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "联单ID"
+// @Success      200 {object} models.Response{data=models.WasteManifest} "办结成功"
+// @Failure      200 {object} models.Response "办结失败"
+// @Router       /manifests/{id}/close [post]
+func (ctrl *ManifestController) Close(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid manifest ID",
+		})
+		return
+	}
+
+	// Get current user from context
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	if err := ctrl.manifestService.Close(uint(id), userModel.ID); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	ctrl.respondManifest(c, uint(id), "Manifest closed successfully")
+}
+
+// ExportJSON godoc
+// @Summary      导出联单 JSON
+// @Description  下载联单上报用的结构化 JSON 文件
+// @Tags         危废联单
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "联单ID"
+// @Success      200 {object} models.WasteManifestExport "联单数据"
+// @Failure      200 {object} models.Response "导出失败"
+// @Router       /manifests/{id}/json [get]
+func (ctrl *ManifestController) ExportJSON(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid manifest ID",
+		})
+		return
+	}
+
+	export, err := ctrl.manifestService.ExportJSON(uint(id))
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeNotFound,
+			Msg:  "Manifest not found",
+		})
+		return
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, export.ManifestNo))
+	c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+}
+
+// ExportPDF godoc
+// @Summary      导出联单 PDF
+// @Description  下载联单打印用的 PDF 文件
+// @Tags         危废联单
+// @Produce      application/pdf
+// @Security     BearerAuth
+// @Param        id path int true "联单ID"
+// @Success      200 {file} file "联单 PDF"
+// @Failure      200 {object} models.Response "导出失败"
+// @Router       /manifests/{id}/pdf [get]
+func (ctrl *ManifestController) ExportPDF(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid manifest ID",
+		})
+		return
+	}
+
+	manifestNo, data, err := ctrl.manifestService.ExportPDF(uint(id))
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeNotFound,
+			Msg:  "Manifest not found",
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, manifestNo))
+	c.Data(http.StatusOK, "application/pdf", data)
+}
+
+// respondManifest 返回操作后的联单详情
+func (ctrl *ManifestController) respondManifest(c *gin.Context, id uint, msg string) {
+	manifest, err := ctrl.manifestService.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeSuccess,
+			Msg:  msg,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  msg,
+		Data: manifest,
+	})
+}
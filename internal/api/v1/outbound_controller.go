@@ -3,6 +3,7 @@ package v1
 import (
 	"battery-erp-backend/internal/models"
 	"battery-erp-backend/internal/services"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -29,8 +30,11 @@ func NewOutboundController(outboundService *services.OutboundService) *OutboundC
 // @Param        page query int false "页码" default(1)
 // @Param        page_size query int false "每页数量" default(20)
 // @Param        customer query string false "客户名称 (支持模糊搜索)"
+// @Param        customer_id query int false "客户ID"
+// @Param        warehouse_id query int false "仓库ID"
 // @Param        start_date query string false "开始日期 (YYYY-MM-DD)"
 // @Param        end_date query string false "结束日期 (YYYY-MM-DD)"
+// @Param        price_flagged query bool false "仅查询单价偏离价目表的订单"
 // @Success      200 {object} models.Response{data=models.GetOutboundOrderResponse} "获取成功"
 // @Failure      200 {object} models.Response "获取失败"
 // @Router       /outbound/orders [get]
@@ -73,7 +77,7 @@ func (ctrl *OutboundController) GetAll(c *gin.Context) {
 
 // Create godoc
 // @Summary      创建出库订单
-// @Description  创建新的出库订单，支持批量创建
+// @Description  创建新的出库订单，支持批量创建。客户未收金额加订单金额超过信用额度时拒绝 (40300)，超级管理员可指定 credit_override 强制下单
 // @Tags         出库管理
 // @Accept       json
 // @Produce      json
@@ -96,10 +100,23 @@ func (ctrl *OutboundController) Create(c *gin.Context) {
 	user, _ := c.Get("user")
 	userModel := user.(*models.User)
 
+	// 超过信用额度强制下单需要超级管理员权限
+	if req.CreditOverride && userModel.Role != "super_admin" {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeForbidden,
+			Msg:  "Only super_admin can override credit limit",
+		})
+		return
+	}
+
 	order, err := ctrl.outboundService.Create(&req, userModel.ID)
 	if err != nil {
+		code := models.CodeInternalError
+		if errors.Is(err, services.ErrCreditLimitExceeded) {
+			code = models.CodeForbidden
+		}
 		c.JSON(http.StatusOK, &models.Response{
-			Code: models.CodeInternalError,
+			Code: code,
 			Msg:  err.Error(),
 		})
 		return
@@ -151,7 +168,7 @@ func (ctrl *OutboundController) GetByID(c *gin.Context) {
 
 // Update godoc
 // @Summary      更新出库订单
-// @Description  根据订单ID更新出库订单
+// @Description  根据订单ID更新出库订单。已记账订单增加金额或更换客户、草稿随修改确认时重新校验客户信用额度，超过时拒绝 (40300)，超级管理员可指定 credit_override 放行
 // @Tags         出库管理
 // @Accept       json
 // @Produce      json
@@ -180,25 +197,37 @@ func (ctrl *OutboundController) Update(c *gin.Context) {
 		return
 	}
 
+	// Get current user from context
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	// 超过信用额度强制修改需要超级管理员权限
+	if req.CreditOverride && userModel.Role != "super_admin" {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeForbidden,
+			Msg:  "Only super_admin can override credit limit",
+		})
+		return
+	}
+
 	// 判断是否需要更新订单项
 	if len(req.Items) > 0 {
 		// 完整更新（包括订单项）
-		if err := ctrl.outboundService.UpdateOrderComplete(uint(id), &req); err != nil {
-			c.JSON(http.StatusOK, &models.Response{
-				Code: models.CodeInternalError,
-				Msg:  err.Error(),
-			})
-			return
-		}
+		err = ctrl.outboundService.UpdateOrderComplete(uint(id), &req, userModel.ID)
 	} else {
 		// 仅更新基本信息
-		if err := ctrl.outboundService.UpdateOrderBasic(uint(id), &req); err != nil {
-			c.JSON(http.StatusOK, &models.Response{
-				Code: models.CodeInternalError,
-				Msg:  err.Error(),
-			})
-			return
+		err = ctrl.outboundService.UpdateOrderBasic(uint(id), &req, userModel.ID)
+	}
+	if err != nil {
+		code := models.CodeInternalError
+		if errors.Is(err, services.ErrCreditLimitExceeded) {
+			code = models.CodeForbidden
 		}
+		c.JSON(http.StatusOK, &models.Response{
+			Code: code,
+			Msg:  err.Error(),
+		})
+		return
 	}
 
 	// 获取更新后的订单详情
@@ -239,7 +268,11 @@ func (ctrl *OutboundController) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := ctrl.outboundService.Delete(uint(id)); err != nil {
+	// Get current user from context
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	if err := ctrl.outboundService.Delete(uint(id), userModel.ID); err != nil {
 		c.JSON(http.StatusOK, &models.Response{
 			Code: models.CodeInternalError,
 			Msg:  err.Error(),
@@ -252,3 +285,107 @@ func (ctrl *OutboundController) Delete(c *gin.Context) {
 		Msg:  "Order deleted successfully",
 	})
 }
+
+// Confirm godoc
+// @Summary      确认出库订单
+// @Description  确认草稿订单，确认时扣减库存。客户未收金额加订单金额超过信用额度时拒绝 (40300)，超级管理员可指定 credit_override=true 放行
+// @Tags         出库管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "订单ID"
+// @Param        credit_override query bool false "超过信用额度时放行 (仅超级管理员)"
+// @Success      200 {object} models.Response "操作成功"
+// @Failure      200 {object} models.Response "操作失败"
+// @Router       /outbound/orders/{id}/confirm [post]
+func (ctrl *OutboundController) Confirm(c *gin.Context) {
+	creditOverride := c.Query("credit_override") == "true"
+	if creditOverride {
+		user, _ := c.Get("user")
+		if user.(*models.User).Role != "super_admin" {
+			c.JSON(http.StatusOK, &models.Response{
+				Code: models.CodeForbidden,
+				Msg:  "Only super_admin can override credit limit",
+			})
+			return
+		}
+	}
+
+	confirm := func(id uint, userID uint) error {
+		return ctrl.outboundService.Confirm(id, userID, creditOverride)
+	}
+	ctrl.changeStatus(c, confirm, "Order confirmed successfully")
+}
+
+// Complete godoc
+// @Summary      完成出库订单
+// @Description  将已确认的订单标记为已完成
+// @Tags         出库管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "订单ID"
+// @Success      200 {object} models.Response "操作成功"
+// @Failure      200 {object} models.Response "操作失败"
+// @Router       /outbound/orders/{id}/complete [post]
+func (ctrl *OutboundController) Complete(c *gin.Context) {
+	ctrl.changeStatus(c, ctrl.outboundService.Complete, "Order completed successfully")
+}
+
+// Cancel godoc
+// @Summary      取消出库订单
+// @Description  取消订单，已扣减库存的订单同时退回库存
+// @Tags         出库管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "订单ID"
+// @Success      200 {object} models.Response "操作成功"
+// @Failure      200 {object} models.Response "操作失败"
+// @Router       /outbound/orders/{id}/cancel [post]
+func (ctrl *OutboundController) Cancel(c *gin.Context) {
+	ctrl.changeStatus(c, ctrl.outboundService.Cancel, "Order cancelled successfully")
+}
+
+// changeStatus 执行订单状态流转并返回流转后的订单详情
+func (ctrl *OutboundController) changeStatus(c *gin.Context, transition func(id uint, userID uint) error, msg string) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid order ID",
+		})
+		return
+	}
+
+	// Get current user from context
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	if err := transition(uint(id), userModel.ID); err != nil {
+		code := models.CodeInternalError
+		if errors.Is(err, services.ErrCreditLimitExceeded) {
+			code = models.CodeForbidden
+		}
+		c.JSON(http.StatusOK, &models.Response{
+			Code: code,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	order, err := ctrl.outboundService.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeSuccess,
+			Msg:  msg,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  msg,
+		Data: order,
+	})
+}
@@ -0,0 +1,27 @@
+package v1
+
+import (
+	"battery-erp-backend/internal/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// queryID 解析可选的 ID 查询参数 (未提供时为 0)，格式错误时写入响应并返回 false
+func queryID(c *gin.Context, key, invalidMsg string) (uint, bool) {
+	raw := c.Query(key)
+	if raw == "" {
+		return 0, true
+	}
+
+	id, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  invalidMsg,
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
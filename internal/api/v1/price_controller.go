@@ -0,0 +1,146 @@
+package v1
+
+import (
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PriceController struct {
+	priceService *services.PriceService
+}
+
+func NewPriceController(priceService *services.PriceService) *PriceController {
+	return &PriceController{
+		priceService: priceService,
+	}
+}
+
+// GetHistory godoc
+// @Summary      获取分类价格历史
+// @Description  获取分类当前的收购价、销售价及按生效时间倒序的价格历史
+// @Tags         电池分类管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "分类ID"
+// @Param        price_type query string false "价格类型 (buy/sell)，为空时返回全部"
+// @Success      200 {object} models.Response{data=models.CategoryPriceHistory} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /categories/{id}/prices [get]
+func (ctrl *PriceController) GetHistory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid category ID",
+		})
+		return
+	}
+
+	priceType := c.Query("price_type")
+	if priceType != "" && priceType != models.PriceTypeBuy && priceType != models.PriceTypeSell {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid price type",
+		})
+		return
+	}
+
+	history, err := ctrl.priceService.GetHistory(uint(id), priceType)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: history,
+	})
+}
+
+// Create godoc
+// @Summary      新增分类价格
+// @Description  新增收购价或销售价，自生效时间起替代之前的价格，历史价格保留 (需要超级管理员权限)
+// @Tags         电池分类管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "分类ID"
+// @Param        price body models.CreateCategoryPriceRequest true "价格信息"
+// @Success      200 {object} models.Response{data=models.CategoryPrice} "创建成功"
+// @Failure      200 {object} models.Response "创建失败"
+// @Router       /categories/{id}/prices [post]
+func (ctrl *PriceController) Create(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid category ID",
+		})
+		return
+	}
+
+	var req models.CreateCategoryPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid request data",
+		})
+		return
+	}
+
+	// Get current user from context
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	price, err := ctrl.priceService.Create(uint(id), &req, userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "Price created successfully",
+		Data: price,
+	})
+}
+
+// GetPriceList godoc
+// @Summary      获取价目表
+// @Description  获取各分类在指定日期结束时有效的收购价和销售价
+// @Tags         电池分类管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        as_of query string false "日期 (YYYY-MM-DD)，默认为当前时间"
+// @Success      200 {object} models.Response{data=models.PriceList} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /categories/price-list [get]
+func (ctrl *PriceController) GetPriceList(c *gin.Context) {
+	list, err := ctrl.priceService.GetPriceList(c.Query("as_of"))
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: list,
+	})
+}
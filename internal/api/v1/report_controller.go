@@ -18,11 +18,28 @@ func NewReportController(reportService *services.ReportService) *ReportControlle
 	}
 }
 
+// GetSummary godoc
+// @Summary      获取报表摘要
+// @Description  获取库存总览；提供日期范围时附带入库/出库统计及按分类汇总 (仅统计已确认/已完成订单)
+// @Tags         报表
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        start_date query string false "开始日期 (YYYY-MM-DD)"
+// @Param        end_date query string false "结束日期 (YYYY-MM-DD，包含当天)"
+// @Param        warehouse_id query int false "仓库ID，为空时为全部仓库合计"
+// @Success      200 {object} models.Response{data=models.ReportSummary} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /reports/summary [get]
 func (ctrl *ReportController) GetSummary(c *gin.Context) {
 	startDate := c.Query("start_date")
 	endDate := c.Query("end_date")
+	warehouseID, ok := queryID(c, "warehouse_id", "Invalid warehouse ID")
+	if !ok {
+		return
+	}
 
-	summary, err := ctrl.reportService.GetSummary(startDate, endDate)
+	summary, err := ctrl.reportService.GetSummary(startDate, endDate, warehouseID)
 	if err != nil {
 		c.JSON(http.StatusOK, &models.Response{
 			Code: models.CodeInternalError,
@@ -37,3 +54,118 @@ func (ctrl *ReportController) GetSummary(c *gin.Context) {
 		Data: summary,
 	})
 }
+
+// GetTrend godoc
+// @Summary      获取采购/销售趋势
+// @Description  按日、ISO周或月汇总各电池分类的入库净重、出库重量、采购金额和销售金额 (仅统计已确认/已完成订单)
+// @Tags         报表
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        granularity query string false "时间粒度 (day, week, month)" default(day)
+// @Param        start_date query string false "开始日期 (YYYY-MM-DD)"
+// @Param        end_date query string false "结束日期 (YYYY-MM-DD，包含当天)"
+// @Param        category_id query int false "分类ID"
+// @Param        warehouse_id query int false "仓库ID，为空时为全部仓库合计"
+// @Success      200 {object} models.Response{data=models.TrendReport} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /reports/trend [get]
+func (ctrl *ReportController) GetTrend(c *gin.Context) {
+	categoryID, ok := queryID(c, "category_id", "Invalid category ID")
+	if !ok {
+		return
+	}
+	warehouseID, ok := queryID(c, "warehouse_id", "Invalid warehouse ID")
+	if !ok {
+		return
+	}
+
+	trend, err := ctrl.reportService.GetTrend(c.Query("granularity"), c.Query("start_date"), c.Query("end_date"), categoryID, warehouseID)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: trend,
+	})
+}
+
+// GetMargin godoc
+// @Summary      获取毛利报表
+// @Description  按分类计算期间内的销售收入、销售成本 (出库流水记录的实际成本)、毛利和毛利率
+// @Tags         报表
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        start_date query string false "开始日期 (YYYY-MM-DD)，默认为结束日期所在月初"
+// @Param        end_date query string false "结束日期 (YYYY-MM-DD，包含当天)，默认为今天"
+// @Param        category_id query int false "分类ID"
+// @Param        warehouse_id query int false "仓库ID，为空时为全部仓库合计"
+// @Success      200 {object} models.Response{data=models.MarginReport} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /reports/margin [get]
+func (ctrl *ReportController) GetMargin(c *gin.Context) {
+	categoryID, ok := queryID(c, "category_id", "Invalid category ID")
+	if !ok {
+		return
+	}
+	warehouseID, ok := queryID(c, "warehouse_id", "Invalid warehouse ID")
+	if !ok {
+		return
+	}
+
+	margin, err := ctrl.reportService.GetMargin(c.Query("start_date"), c.Query("end_date"), categoryID, warehouseID)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: margin,
+	})
+}
+
+// GetInventoryValuation godoc
+// @Summary      获取库存估值报表
+// @Description  按分类获取指定日期结束时的库存重量、平均成本和库存金额
+// @Tags         报表
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        as_of query string false "估值日期 (YYYY-MM-DD)，默认为今天"
+// @Param        warehouse_id query int false "仓库ID，为空时为全部仓库合计"
+// @Success      200 {object} models.Response{data=models.InventoryValuationReport} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /reports/inventory-valuation [get]
+func (ctrl *ReportController) GetInventoryValuation(c *gin.Context) {
+	warehouseID, ok := queryID(c, "warehouse_id", "Invalid warehouse ID")
+	if !ok {
+		return
+	}
+
+	valuation, err := ctrl.reportService.GetInventoryValuation(c.Query("as_of"), warehouseID)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: valuation,
+	})
+}
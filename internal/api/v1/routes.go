@@ -19,6 +19,21 @@ func SetupRoutes(engine *gin.Engine, services *services.Services) {
 	outboundController := NewOutboundController(services.OutboundService)
 	inventoryController := NewInventoryController(services.InventoryService)
 	reportController := NewReportController(services.ReportService)
+	sellerController := NewSellerController(services.SellerService)
+	customerController := NewCustomerController(services.CustomerService)
+	stocktakeController := NewStocktakeController(services.StocktakeService)
+	adjustmentController := NewAdjustmentController(services.AdjustmentService)
+	warehouseController := NewWarehouseController(services.WarehouseService)
+	transferController := NewTransferController(services.TransferService)
+	lotController := NewLotController(services.LotService)
+	traceController := NewTraceController(services.TraceService)
+	manifestController := NewManifestController(services.ManifestService)
+	priceController := NewPriceController(services.PriceService)
+	scaleController := NewScaleController(services.ScaleService)
+	weighTicketController := NewWeighTicketController(services.WeighTicketService)
+	vehicleController := NewVehicleController(services.VehicleService)
+	paymentController := NewSupplierPaymentController(services.PaymentService)
+	receiptController := NewCustomerReceiptController(services.ReceiptService)
 
 	// Auth routes (no middleware)
 	authRoutes := v1.Group("/auth")
@@ -46,9 +61,98 @@ func SetupRoutes(engine *gin.Engine, services *services.Services) {
 	{
 		categoryRoutes.GET("", categoryController.GetAll)
 		categoryRoutes.POST("", authMiddleware.RequireRole("super_admin"), categoryController.Create)
+		categoryRoutes.GET("/price-list", priceController.GetPriceList)
 		categoryRoutes.GET("/:id", categoryController.GetByID)
 		categoryRoutes.PUT("/:id", authMiddleware.RequireRole("super_admin"), categoryController.Update)
 		categoryRoutes.DELETE("/:id", authMiddleware.RequireRole("super_admin"), categoryController.Delete)
+		categoryRoutes.GET("/:id/prices", priceController.GetHistory)
+		categoryRoutes.POST("/:id/prices", authMiddleware.RequireRole("super_admin"), priceController.Create)
+	}
+
+	// Warehouse routes
+	warehouseRoutes := v1.Group("/warehouses")
+	warehouseRoutes.Use(authMiddleware.RequireAuth())
+	{
+		warehouseRoutes.GET("", warehouseController.GetAll)
+		warehouseRoutes.POST("", authMiddleware.RequireRole("super_admin"), warehouseController.Create)
+		warehouseRoutes.GET("/:id", warehouseController.GetByID)
+		warehouseRoutes.PUT("/:id", authMiddleware.RequireRole("super_admin"), warehouseController.Update)
+		warehouseRoutes.DELETE("/:id", authMiddleware.RequireRole("super_admin"), warehouseController.Delete)
+	}
+
+	// Transfer routes
+	transferRoutes := v1.Group("/transfers")
+	transferRoutes.Use(authMiddleware.RequireAuth())
+	{
+		transferRoutes.GET("", transferController.GetAll)
+		transferRoutes.POST("", transferController.Create)
+		transferRoutes.GET("/in-transit", transferController.GetInTransit)
+		transferRoutes.GET("/:id", transferController.GetByID)
+		transferRoutes.POST("/:id/ship", transferController.Ship)
+		transferRoutes.POST("/:id/receive", transferController.Receive)
+		transferRoutes.POST("/:id/cancel", transferController.Cancel)
+	}
+
+	// Lot routes
+	lotRoutes := v1.Group("/lots")
+	lotRoutes.Use(authMiddleware.RequireAuth())
+	{
+		lotRoutes.GET("", lotController.GetAll)
+		lotRoutes.GET("/:id", lotController.GetByID)
+		lotRoutes.GET("/:id/genealogy", lotController.GetGenealogy)
+	}
+
+	// Trace code routes
+	traceRoutes := v1.Group("/trace")
+	traceRoutes.Use(authMiddleware.RequireAuth())
+	{
+		traceRoutes.GET("/:code", traceController.Lookup)
+	}
+
+	// Seller routes
+	sellerRoutes := v1.Group("/sellers")
+	sellerRoutes.Use(authMiddleware.RequireAuth())
+	{
+		sellerRoutes.GET("", sellerController.GetAll)
+		sellerRoutes.POST("", sellerController.Create)
+		sellerRoutes.POST("/match-suppliers", authMiddleware.RequireRole("super_admin"), sellerController.MatchSuppliers)
+		sellerRoutes.GET("/:id", sellerController.GetByID)
+		sellerRoutes.PUT("/:id", sellerController.Update)
+		sellerRoutes.DELETE("/:id", sellerController.Delete)
+		sellerRoutes.GET("/:id/balance", paymentController.GetSupplierBalance)
+	}
+
+	// Customer routes
+	customerRoutes := v1.Group("/customers")
+	customerRoutes.Use(authMiddleware.RequireAuth())
+	{
+		customerRoutes.GET("", customerController.GetAll)
+		customerRoutes.POST("", customerController.Create)
+		customerRoutes.GET("/:id", customerController.GetByID)
+		customerRoutes.PUT("/:id", customerController.Update)
+		customerRoutes.DELETE("/:id", customerController.Delete)
+		customerRoutes.GET("/:id/orders", customerController.GetOrders)
+		customerRoutes.GET("/:id/balance", receiptController.GetCustomerBalance)
+	}
+
+	// Supplier payment routes
+	paymentRoutes := v1.Group("/supplier-payments")
+	paymentRoutes.Use(authMiddleware.RequireAuth())
+	{
+		paymentRoutes.GET("", paymentController.GetAll)
+		paymentRoutes.POST("", paymentController.Create)
+		paymentRoutes.GET("/:id", paymentController.GetByID)
+		paymentRoutes.POST("/:id/void", authMiddleware.RequireRole("super_admin"), paymentController.Void)
+	}
+
+	// Customer receipt routes
+	receiptRoutes := v1.Group("/customer-receipts")
+	receiptRoutes.Use(authMiddleware.RequireAuth())
+	{
+		receiptRoutes.GET("", receiptController.GetAll)
+		receiptRoutes.POST("", receiptController.Create)
+		receiptRoutes.GET("/:id", receiptController.GetByID)
+		receiptRoutes.POST("/:id/void", authMiddleware.RequireRole("super_admin"), receiptController.Void)
 	}
 
 	// Inbound routes
@@ -60,6 +164,9 @@ func SetupRoutes(engine *gin.Engine, services *services.Services) {
 		inboundRoutes.GET("/:id", inboundController.GetByID)
 		inboundRoutes.PUT("/:id", inboundController.Update)
 		inboundRoutes.DELETE("/:id", inboundController.Delete)
+		inboundRoutes.POST("/:id/confirm", inboundController.Confirm)
+		inboundRoutes.POST("/:id/complete", inboundController.Complete)
+		inboundRoutes.POST("/:id/cancel", inboundController.Cancel)
 	}
 
 	// Outbound routes
@@ -71,6 +178,66 @@ func SetupRoutes(engine *gin.Engine, services *services.Services) {
 		outboundRoutes.GET("/:id", outboundController.GetByID)
 		outboundRoutes.PUT("/:id", outboundController.Update)
 		outboundRoutes.DELETE("/:id", outboundController.Delete)
+		outboundRoutes.POST("/:id/confirm", outboundController.Confirm)
+		outboundRoutes.POST("/:id/complete", outboundController.Complete)
+		outboundRoutes.POST("/:id/cancel", outboundController.Cancel)
+		outboundRoutes.GET("/:id/lots", lotController.GetShipmentTrace)
+		outboundRoutes.POST("/:id/manifest", manifestController.Generate)
+	}
+
+	// Waste manifest routes
+	manifestRoutes := v1.Group("/manifests")
+	manifestRoutes.Use(authMiddleware.RequireAuth())
+	{
+		manifestRoutes.GET("", manifestController.GetAll)
+		manifestRoutes.GET("/:id", manifestController.GetByID)
+		manifestRoutes.POST("/:id/receive", manifestController.Receive)
+		manifestRoutes.POST("/:id/close", manifestController.Close)
+		manifestRoutes.GET("/:id/json", manifestController.ExportJSON)
+		manifestRoutes.GET("/:id/pdf", manifestController.ExportPDF)
+	}
+
+	// Weighbridge routes
+	scaleRoutes := v1.Group("/scale")
+	scaleRoutes.Use(authMiddleware.RequireAuth())
+	{
+		scaleRoutes.GET("/current", scaleController.Current)
+		scaleRoutes.POST("/capture", scaleController.Capture)
+	}
+
+	// Weigh ticket routes
+	weighTicketRoutes := v1.Group("/weigh-tickets")
+	weighTicketRoutes.Use(authMiddleware.RequireAuth())
+	{
+		weighTicketRoutes.GET("", weighTicketController.GetAll)
+		weighTicketRoutes.POST("", weighTicketController.Create)
+		weighTicketRoutes.GET("/:id", weighTicketController.GetByID)
+		weighTicketRoutes.POST("/:id/second-weigh", weighTicketController.SecondWeigh)
+		weighTicketRoutes.POST("/:id/convert", weighTicketController.Convert)
+		weighTicketRoutes.POST("/:id/cancel", weighTicketController.Cancel)
+	}
+
+	// Vehicle routes
+	vehicleRoutes := v1.Group("/vehicles")
+	vehicleRoutes.Use(authMiddleware.RequireAuth())
+	{
+		vehicleRoutes.GET("", vehicleController.GetAll)
+		vehicleRoutes.POST("", vehicleController.Create)
+		vehicleRoutes.GET("/:id", vehicleController.GetByID)
+		vehicleRoutes.PUT("/:id", vehicleController.Update)
+		vehicleRoutes.DELETE("/:id", vehicleController.Delete)
+		vehicleRoutes.GET("/:id/trips", vehicleController.GetTrips)
+	}
+
+	// Driver routes
+	driverRoutes := v1.Group("/drivers")
+	driverRoutes.Use(authMiddleware.RequireAuth())
+	{
+		driverRoutes.GET("", vehicleController.GetAllDrivers)
+		driverRoutes.POST("", vehicleController.CreateDriver)
+		driverRoutes.GET("/:id", vehicleController.GetDriverByID)
+		driverRoutes.PUT("/:id", vehicleController.UpdateDriver)
+		driverRoutes.DELETE("/:id", vehicleController.DeleteDriver)
 	}
 
 	// Inventory routes
@@ -78,7 +245,21 @@ func SetupRoutes(engine *gin.Engine, services *services.Services) {
 	inventoryRoutes.Use(authMiddleware.RequireAuth())
 	{
 		inventoryRoutes.GET("", inventoryController.GetAll)
+		inventoryRoutes.GET("/consolidated", inventoryController.GetConsolidated)
+		inventoryRoutes.GET("/ledger-check", inventoryController.CheckLedger)
+		inventoryRoutes.GET("/stocktakes", stocktakeController.GetAll)
+		inventoryRoutes.POST("/stocktakes", stocktakeController.Create)
+		inventoryRoutes.GET("/stocktakes/:id", stocktakeController.GetByID)
+		inventoryRoutes.PUT("/stocktakes/:id/counts", stocktakeController.RecordCounts)
+		inventoryRoutes.POST("/stocktakes/:id/approve", authMiddleware.RequireRole("super_admin"), stocktakeController.Approve)
+		inventoryRoutes.POST("/stocktakes/:id/cancel", stocktakeController.Cancel)
+		inventoryRoutes.GET("/adjustments", adjustmentController.GetAll)
+		inventoryRoutes.POST("/adjustments", adjustmentController.Create)
+		inventoryRoutes.GET("/adjustments/:id", adjustmentController.GetByID)
+		inventoryRoutes.POST("/adjustments/:id/approve", authMiddleware.RequireRole("super_admin"), adjustmentController.Approve)
+		inventoryRoutes.POST("/adjustments/:id/reject", authMiddleware.RequireRole("super_admin"), adjustmentController.Reject)
 		inventoryRoutes.GET("/:categoryId", inventoryController.GetByCategoryID)
+		inventoryRoutes.GET("/:categoryId/movements", inventoryController.GetMovements)
 	}
 
 	// Report routes
@@ -86,5 +267,10 @@ func SetupRoutes(engine *gin.Engine, services *services.Services) {
 	reportRoutes.Use(authMiddleware.RequireAuth())
 	{
 		reportRoutes.GET("/summary", reportController.GetSummary)
+		reportRoutes.GET("/trend", reportController.GetTrend)
+		reportRoutes.GET("/margin", reportController.GetMargin)
+		reportRoutes.GET("/inventory-valuation", reportController.GetInventoryValuation)
+		reportRoutes.GET("/payables-aging", paymentController.GetAging)
+		reportRoutes.GET("/receivables-aging", receiptController.GetAging)
 	}
 }
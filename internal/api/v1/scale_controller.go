@@ -0,0 +1,87 @@
+package v1
+
+import (
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ScaleController struct {
+	scaleService *services.ScaleService
+}
+
+func NewScaleController(scaleService *services.ScaleService) *ScaleController {
+	return &ScaleController{
+		scaleService: scaleService,
+	}
+}
+
+// Current godoc
+// @Summary      获取地磅当前读数
+// @Description  获取地磅最新读数及是否稳定，地磅未启用或离线时返回 503
+// @Tags         地磅
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} models.Response{data=models.ScaleReading} "获取成功"
+// @Failure      200 {object} models.Response "地磅不可用"
+// @Router       /scale/current [get]
+func (ctrl *ScaleController) Current(c *gin.Context) {
+	reading, err := ctrl.scaleService.Current()
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeServiceUnavailable,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: reading,
+	})
+}
+
+// Capture godoc
+// @Summary      采集地磅重量
+// @Description  把地磅当前稳定读数写入草稿入库订单项的毛重或皮重，标记为地磅采集并重新计算金额
+// @Tags         地磅
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        capture body models.ScaleCaptureRequest true "订单项和重量字段"
+// @Success      200 {object} models.Response{data=models.ScaleCaptureResponse} "采集成功"
+// @Failure      200 {object} models.Response "采集失败"
+// @Router       /scale/capture [post]
+func (ctrl *ScaleController) Capture(c *gin.Context) {
+	var req models.ScaleCaptureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid request data",
+		})
+		return
+	}
+
+	// Get current user from context
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	result, err := ctrl.scaleService.Capture(&req, userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "Weight captured successfully",
+		Data: result,
+	})
+}
@@ -0,0 +1,267 @@
+package v1
+
+import (
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SellerController struct {
+	sellerService *services.SellerService
+}
+
+func NewSellerController(sellerService *services.SellerService) *SellerController {
+	return &SellerController{
+		sellerService: sellerService,
+	}
+}
+
+// GetAll godoc
+// @Summary      获取卖家列表
+// @Description  获取卖家(供应商)列表，支持按名称或电话模糊搜索
+// @Tags         卖家管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        keyword query string false "名称或电话 (支持模糊搜索)"
+// @Success      200 {object} models.Response{data=[]models.Seller} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /sellers [get]
+func (ctrl *SellerController) GetAll(c *gin.Context) {
+	sellers, err := ctrl.sellerService.Search(c.Query("keyword"))
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: sellers,
+	})
+}
+
+// Create godoc
+// @Summary      创建卖家
+// @Description  创建新的卖家(供应商)
+// @Tags         卖家管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        seller body models.CreateSellerRequest true "卖家信息"
+// @Success      200 {object} models.Response{data=models.Seller} "创建成功"
+// @Failure      200 {object} models.Response "创建失败"
+// @Router       /sellers [post]
+func (ctrl *SellerController) Create(c *gin.Context) {
+	var req models.CreateSellerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid request data",
+		})
+		return
+	}
+
+	seller := models.Seller{
+		Name:    req.Name,
+		Phone:   req.Phone,
+		Address: req.Address,
+	}
+	if err := ctrl.sellerService.Create(&seller); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "Seller created successfully",
+		Data: seller,
+	})
+}
+
+// GetByID godoc
+// @Summary      根据ID获取卖家
+// @Description  根据卖家ID获取卖家信息
+// @Tags         卖家管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "卖家ID"
+// @Success      200 {object} models.Response{data=models.Seller} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /sellers/{id} [get]
+func (ctrl *SellerController) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid seller ID",
+		})
+		return
+	}
+
+	seller, err := ctrl.sellerService.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeNotFound,
+			Msg:  "Seller not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: seller,
+	})
+}
+
+// Update godoc
+// @Summary      更新卖家
+// @Description  根据ID更新卖家信息
+// @Tags         卖家管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "卖家ID"
+// @Param        seller body models.UpdateSellerRequest true "卖家信息"
+// @Success      200 {object} models.Response{data=models.Seller} "更新成功"
+// @Failure      200 {object} models.Response "更新失败"
+// @Router       /sellers/{id} [put]
+func (ctrl *SellerController) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid seller ID",
+		})
+		return
+	}
+
+	var req models.UpdateSellerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid request data",
+		})
+		return
+	}
+
+	// 构建更新字段映射
+	updates := make(map[string]interface{})
+	if req.Name != "" {
+		updates["name"] = req.Name
+	}
+	if req.Phone != "" {
+		updates["phone"] = req.Phone
+	}
+	if req.Address != "" {
+		updates["address"] = req.Address
+	}
+
+	if len(updates) > 0 {
+		if err := ctrl.sellerService.Update(uint(id), updates); err != nil {
+			c.JSON(http.StatusOK, &models.Response{
+				Code: models.CodeInternalError,
+				Msg:  err.Error(),
+			})
+			return
+		}
+	}
+
+	seller, err := ctrl.sellerService.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeNotFound,
+			Msg:  "Seller not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "Seller updated successfully",
+		Data: seller,
+	})
+}
+
+// Delete godoc
+// @Summary      删除卖家
+// @Description  根据ID删除卖家，已被入库订单引用的卖家不能删除
+// @Tags         卖家管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "卖家ID"
+// @Success      200 {object} models.Response "删除成功"
+// @Failure      200 {object} models.Response "删除失败"
+// @Router       /sellers/{id} [delete]
+func (ctrl *SellerController) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid seller ID",
+		})
+		return
+	}
+
+	if err := ctrl.sellerService.Delete(uint(id)); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "Seller deleted successfully",
+	})
+}
+
+// MatchSuppliers godoc
+// @Summary      匹配历史供应商
+// @Description  将未关联卖家的入库订单按供应商名称匹配到同名卖家，可选自动创建缺失的卖家
+// @Tags         卖家管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.MatchSuppliersRequest true "匹配选项"
+// @Success      200 {object} models.Response{data=models.MatchSuppliersResponse} "匹配完成"
+// @Failure      200 {object} models.Response "匹配失败"
+// @Router       /sellers/match-suppliers [post]
+func (ctrl *SellerController) MatchSuppliers(c *gin.Context) {
+	var req models.MatchSuppliersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid request data",
+		})
+		return
+	}
+
+	result, err := ctrl.sellerService.MatchSuppliers(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: result,
+	})
+}
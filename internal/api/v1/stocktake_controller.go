@@ -0,0 +1,266 @@
+package v1
+
+import (
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StocktakeController struct {
+	stocktakeService *services.StocktakeService
+}
+
+func NewStocktakeController(stocktakeService *services.StocktakeService) *StocktakeController {
+	return &StocktakeController{
+		stocktakeService: stocktakeService,
+	}
+}
+
+// GetAll godoc
+// @Summary      获取盘点单列表
+// @Description  分页获取盘点单，支持按状态筛选
+// @Tags         库存盘点
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page query int false "页码" default(1)
+// @Param        page_size query int false "每页数量" default(20)
+// @Param        status query string false "状态 (open/approved/cancelled)"
+// @Param        warehouse_id query int false "仓库ID"
+// @Success      200 {object} models.Response{data=models.GetStocktakesResponse} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /inventory/stocktakes [get]
+func (ctrl *StocktakeController) GetAll(c *gin.Context) {
+	var req models.GetStocktakesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid query parameters",
+		})
+		return
+	}
+
+	// 设置默认值
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+
+	resp, err := ctrl.stocktakeService.GetAll(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: resp,
+	})
+}
+
+// Create godoc
+// @Summary      开启盘点
+// @Description  为指定分类 (默认全部库存分类) 开启盘点并快照账面重量，盘点期间这些分类禁止出库
+// @Tags         库存盘点
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        stocktake body models.CreateStocktakeRequest true "盘点信息"
+// @Success      200 {object} models.Response{data=models.Stocktake} "创建成功"
+// @Failure      200 {object} models.Response "创建失败"
+// @Router       /inventory/stocktakes [post]
+func (ctrl *StocktakeController) Create(c *gin.Context) {
+	var req models.CreateStocktakeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid request data",
+		})
+		return
+	}
+
+	// Get current user from context
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	stocktake, err := ctrl.stocktakeService.Create(&req, userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "Stocktake created successfully",
+		Data: stocktake,
+	})
+}
+
+// GetByID godoc
+// @Summary      根据ID获取盘点单
+// @Description  获取盘点单及各分类的账面重量、实盘重量和差异
+// @Tags         库存盘点
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "盘点单ID"
+// @Success      200 {object} models.Response{data=models.Stocktake} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /inventory/stocktakes/{id} [get]
+func (ctrl *StocktakeController) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid stocktake ID",
+		})
+		return
+	}
+
+	stocktake, err := ctrl.stocktakeService.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeNotFound,
+			Msg:  "Stocktake not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: stocktake,
+	})
+}
+
+// RecordCounts godoc
+// @Summary      录入实盘重量
+// @Description  录入各分类的实盘重量和差异原因，可多次录入覆盖
+// @Tags         库存盘点
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "盘点单ID"
+// @Param        counts body models.RecordStocktakeCountsRequest true "实盘重量"
+// @Success      200 {object} models.Response{data=models.Stocktake} "录入成功"
+// @Failure      200 {object} models.Response "录入失败"
+// @Router       /inventory/stocktakes/{id}/counts [put]
+func (ctrl *StocktakeController) RecordCounts(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid stocktake ID",
+		})
+		return
+	}
+
+	var req models.RecordStocktakeCountsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid request data",
+		})
+		return
+	}
+
+	// Get current user from context
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	if err := ctrl.stocktakeService.RecordCounts(uint(id), &req, userModel.ID); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	ctrl.respondStocktake(c, uint(id), "Counts recorded successfully")
+}
+
+// Approve godoc
+// @Summary      审核盘点单
+// @Description  审核盘点单，按差异写入盘盈盘亏库存流水并解除出库锁定 (需要超级管理员权限)
+// @Tags         库存盘点
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "盘点单ID"
+// @Success      200 {object} models.Response{data=models.Stocktake} "审核成功"
+// @Failure      200 {object} models.Response "审核失败"
+// @Router       /inventory/stocktakes/{id}/approve [post]
+func (ctrl *StocktakeController) Approve(c *gin.Context) {
+	ctrl.changeStatus(c, ctrl.stocktakeService.Approve, "Stocktake approved successfully")
+}
+
+// Cancel godoc
+// @Summary      取消盘点单
+// @Description  取消进行中的盘点单，不调整库存
+// @Tags         库存盘点
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "盘点单ID"
+// @Success      200 {object} models.Response{data=models.Stocktake} "取消成功"
+// @Failure      200 {object} models.Response "取消失败"
+// @Router       /inventory/stocktakes/{id}/cancel [post]
+func (ctrl *StocktakeController) Cancel(c *gin.Context) {
+	ctrl.changeStatus(c, ctrl.stocktakeService.Cancel, "Stocktake cancelled successfully")
+}
+
+// changeStatus 执行盘点单状态流转并返回流转后的盘点单
+func (ctrl *StocktakeController) changeStatus(c *gin.Context, transition func(id uint, userID uint) error, msg string) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid stocktake ID",
+		})
+		return
+	}
+
+	// Get current user from context
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	if err := transition(uint(id), userModel.ID); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	ctrl.respondStocktake(c, uint(id), msg)
+}
+
+// respondStocktake 返回操作后的盘点单详情
+func (ctrl *StocktakeController) respondStocktake(c *gin.Context, id uint, msg string) {
+	stocktake, err := ctrl.stocktakeService.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeSuccess,
+			Msg:  msg,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  msg,
+		Data: stocktake,
+	})
+}
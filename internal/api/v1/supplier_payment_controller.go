@@ -0,0 +1,263 @@
+package v1
+
+import (
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SupplierPaymentController struct {
+	paymentService *services.SupplierPaymentService
+}
+
+func NewSupplierPaymentController(paymentService *services.SupplierPaymentService) *SupplierPaymentController {
+	return &SupplierPaymentController{
+		paymentService: paymentService,
+	}
+}
+
+// Create godoc
+// @Summary      登记供应商付款
+// @Description  登记现金、银行转账或移动支付付款，分摊到同一供应商的一张或多张已记账入库订单，支持部分付款
+// @Tags         应付账款
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        payment body models.CreateSupplierPaymentRequest true "付款信息"
+// @Success      200 {object} models.Response{data=models.SupplierPayment} "登记成功"
+// @Failure      200 {object} models.Response "登记失败"
+// @Router       /supplier-payments [post]
+func (ctrl *SupplierPaymentController) Create(c *gin.Context) {
+	var req models.CreateSupplierPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid request data",
+		})
+		return
+	}
+
+	// Get current user from context
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	payment, err := ctrl.paymentService.Create(&req, userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "Payment recorded successfully",
+		Data: payment,
+	})
+}
+
+// GetAll godoc
+// @Summary      获取供应商付款列表
+// @Description  分页获取付款单，支持按卖家、供应商名称、付款方式、状态和付款日期筛选
+// @Tags         应付账款
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page query int false "页码" default(1)
+// @Param        page_size query int false "每页数量" default(20)
+// @Param        seller_id query int false "卖家ID"
+// @Param        supplier query string false "供应商名称"
+// @Param        method query string false "付款方式 (cash/bank_transfer/mobile_pay)"
+// @Param        status query string false "状态 (posted/voided)"
+// @Param        start_date query string false "开始日期 (YYYY-MM-DD)"
+// @Param        end_date query string false "结束日期 (YYYY-MM-DD)"
+// @Success      200 {object} models.Response{data=models.GetSupplierPaymentsResponse} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /supplier-payments [get]
+func (ctrl *SupplierPaymentController) GetAll(c *gin.Context) {
+	var req models.GetSupplierPaymentsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid query parameters",
+		})
+		return
+	}
+
+	// 设置默认值
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+
+	resp, err := ctrl.paymentService.GetAll(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: resp,
+	})
+}
+
+// GetByID godoc
+// @Summary      根据ID获取供应商付款
+// @Description  获取付款单及其分摊到各入库订单的金额
+// @Tags         应付账款
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "付款单ID"
+// @Success      200 {object} models.Response{data=models.SupplierPayment} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /supplier-payments/{id} [get]
+func (ctrl *SupplierPaymentController) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid payment ID",
+		})
+		return
+	}
+
+	payment, err := ctrl.paymentService.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeNotFound,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: payment,
+	})
+}
+
+// Void godoc
+// @Summary      作废供应商付款
+// @Description  作废付款单并冲回各入库订单的已付金额 (需要超级管理员权限)
+// @Tags         应付账款
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "付款单ID"
+// @Success      200 {object} models.Response "作废成功"
+// @Failure      200 {object} models.Response "作废失败"
+// @Router       /supplier-payments/{id}/void [post]
+func (ctrl *SupplierPaymentController) Void(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid payment ID",
+		})
+		return
+	}
+
+	// Get current user from context
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	if err := ctrl.paymentService.Void(uint(id), userModel.ID); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "Payment voided successfully",
+	})
+}
+
+// GetSupplierBalance godoc
+// @Summary      获取卖家应付余额
+// @Description  汇总卖家已记账入库订单的应付、已付和未付金额，并列出未付清的订单
+// @Tags         应付账款
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "卖家ID"
+// @Success      200 {object} models.Response{data=models.SupplierBalance} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /sellers/{id}/balance [get]
+func (ctrl *SupplierPaymentController) GetSupplierBalance(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid seller ID",
+		})
+		return
+	}
+
+	balance, err := ctrl.paymentService.GetSupplierBalance(uint(id))
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: balance,
+	})
+}
+
+// GetAging godoc
+// @Summary      应付账款账龄
+// @Description  按供应商汇总截至指定日期的未付金额，按订单创建日期分为 0-30、31-60、61-90 和 90 天以上
+// @Tags         应付账款
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        as_of query string false "截止日期 (YYYY-MM-DD)，默认今天"
+// @Success      200 {object} models.Response{data=models.PayablesAgingReport} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /reports/payables-aging [get]
+func (ctrl *SupplierPaymentController) GetAging(c *gin.Context) {
+	var req models.GetAgingRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid query parameters",
+		})
+		return
+	}
+
+	report, err := ctrl.paymentService.GetAging(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: report,
+	})
+}
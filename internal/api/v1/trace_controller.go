@@ -0,0 +1,47 @@
+package v1
+
+import (
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TraceController struct {
+	traceService *services.TraceService
+}
+
+func NewTraceController(traceService *services.TraceService) *TraceController {
+	return &TraceController{
+		traceService: traceService,
+	}
+}
+
+// Lookup godoc
+// @Summary      查询电池溯源编码
+// @Description  查询溯源编码的当前登记状态 (在库/已出库) 及在本系统中的全部入库、出库和冲回记录
+// @Tags         电池溯源
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        code path string true "溯源编码 (24位数字或大写字母)"
+// @Success      200 {object} models.Response{data=models.TraceCodeHistory} "查询成功"
+// @Failure      200 {object} models.Response "查询失败"
+// @Router       /trace/{code} [get]
+func (ctrl *TraceController) Lookup(c *gin.Context) {
+	history, err := ctrl.traceService.Lookup(c.Param("code"))
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeNotFound,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: history,
+	})
+}
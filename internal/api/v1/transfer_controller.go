@@ -0,0 +1,299 @@
+package v1
+
+import (
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TransferController struct {
+	transferService *services.TransferService
+}
+
+func NewTransferController(transferService *services.TransferService) *TransferController {
+	return &TransferController{
+		transferService: transferService,
+	}
+}
+
+// GetAll godoc
+// @Summary      获取调拨单列表
+// @Description  分页获取调拨单，支持按状态和仓库 (调出或调入) 筛选
+// @Tags         仓库调拨
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page query int false "页码" default(1)
+// @Param        page_size query int false "每页数量" default(20)
+// @Param        status query string false "状态 (draft/in_transit/received/cancelled)"
+// @Param        warehouse_id query int false "仓库ID (调出或调入)"
+// @Success      200 {object} models.Response{data=models.GetTransferOrdersResponse} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /transfers [get]
+func (ctrl *TransferController) GetAll(c *gin.Context) {
+	var req models.GetTransferOrdersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid query parameters",
+		})
+		return
+	}
+
+	// 设置默认值
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+
+	resp, err := ctrl.transferService.GetAll(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: resp,
+	})
+}
+
+// Create godoc
+// @Summary      创建调拨单
+// @Description  创建仓库间调拨单，ship 为 true 时立即发货扣减调出仓库存
+// @Tags         仓库调拨
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        transfer body models.CreateTransferOrderRequest true "调拨信息"
+// @Success      200 {object} models.Response{data=models.TransferOrder} "创建成功"
+// @Failure      200 {object} models.Response "创建失败"
+// @Router       /transfers [post]
+func (ctrl *TransferController) Create(c *gin.Context) {
+	var req models.CreateTransferOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid request data",
+		})
+		return
+	}
+
+	// Get current user from context
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	transfer, err := ctrl.transferService.Create(&req, userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "Transfer created successfully",
+		Data: transfer,
+	})
+}
+
+// GetInTransit godoc
+// @Summary      获取在途库存
+// @Description  按调出仓、调入仓和分类汇总已发货未收货的调拨重量和金额
+// @Tags         仓库调拨
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        warehouse_id query int false "仓库ID (调出或调入)，为空时为全部仓库"
+// @Success      200 {object} models.Response{data=[]models.InTransitStock} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /transfers/in-transit [get]
+func (ctrl *TransferController) GetInTransit(c *gin.Context) {
+	warehouseID, ok := queryID(c, "warehouse_id", "Invalid warehouse ID")
+	if !ok {
+		return
+	}
+
+	stock, err := ctrl.transferService.GetInTransit(warehouseID)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: stock,
+	})
+}
+
+// GetByID godoc
+// @Summary      根据ID获取调拨单
+// @Description  获取调拨单及各分类的发货重量、实收重量和途损
+// @Tags         仓库调拨
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "调拨单ID"
+// @Success      200 {object} models.Response{data=models.TransferOrder} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /transfers/{id} [get]
+func (ctrl *TransferController) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid transfer ID",
+		})
+		return
+	}
+
+	transfer, err := ctrl.transferService.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeNotFound,
+			Msg:  "Transfer not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: transfer,
+	})
+}
+
+// Ship godoc
+// @Summary      调拨发货
+// @Description  扣减调出仓库存，调拨单转为在途
+// @Tags         仓库调拨
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "调拨单ID"
+// @Success      200 {object} models.Response{data=models.TransferOrder} "发货成功"
+// @Failure      200 {object} models.Response "发货失败"
+// @Router       /transfers/{id}/ship [post]
+func (ctrl *TransferController) Ship(c *gin.Context) {
+	ctrl.changeStatus(c, ctrl.transferService.Ship, "Transfer shipped successfully")
+}
+
+// Receive godoc
+// @Summary      调拨收货
+// @Description  按实收重量记入调入仓库存，发货与实收的差额记为途损
+// @Tags         仓库调拨
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "调拨单ID"
+// @Param        receipt body models.ReceiveTransferOrderRequest true "各分类实收重量"
+// @Success      200 {object} models.Response{data=models.TransferOrder} "收货成功"
+// @Failure      200 {object} models.Response "收货失败"
+// @Router       /transfers/{id}/receive [post]
+func (ctrl *TransferController) Receive(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid transfer ID",
+		})
+		return
+	}
+
+	var req models.ReceiveTransferOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid request data",
+		})
+		return
+	}
+
+	// Get current user from context
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	if err := ctrl.transferService.Receive(uint(id), &req, userModel.ID); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	ctrl.respondTransfer(c, uint(id), "Transfer received successfully")
+}
+
+// Cancel godoc
+// @Summary      取消调拨单
+// @Description  取消草稿或在途调拨单，在途调拨按发货成本退回调出仓
+// @Tags         仓库调拨
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "调拨单ID"
+// @Success      200 {object} models.Response{data=models.TransferOrder} "取消成功"
+// @Failure      200 {object} models.Response "取消失败"
+// @Router       /transfers/{id}/cancel [post]
+func (ctrl *TransferController) Cancel(c *gin.Context) {
+	ctrl.changeStatus(c, ctrl.transferService.Cancel, "Transfer cancelled successfully")
+}
+
+// changeStatus 执行调拨单状态流转并返回流转后的调拨单
+func (ctrl *TransferController) changeStatus(c *gin.Context, transition func(id uint, userID uint) error, msg string) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid transfer ID",
+		})
+		return
+	}
+
+	// Get current user from context
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	if err := transition(uint(id), userModel.ID); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	ctrl.respondTransfer(c, uint(id), msg)
+}
+
+// respondTransfer 返回操作后的调拨单详情
+func (ctrl *TransferController) respondTransfer(c *gin.Context, id uint, msg string) {
+	transfer, err := ctrl.transferService.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeSuccess,
+			Msg:  msg,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  msg,
+		Data: transfer,
+	})
+}
@@ -0,0 +1,454 @@
+package v1
+
+import (
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VehicleController struct {
+	vehicleService *services.VehicleService
+}
+
+func NewVehicleController(vehicleService *services.VehicleService) *VehicleController {
+	return &VehicleController{
+		vehicleService: vehicleService,
+	}
+}
+
+// GetAll godoc
+// @Summary      获取车辆列表
+// @Description  获取所有启用的车辆档案，支持按车牌号或承运单位模糊查询
+// @Tags         车辆管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        keyword query string false "车牌号或承运单位"
+// @Success      200 {object} models.Response{data=[]models.Vehicle} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /vehicles [get]
+func (ctrl *VehicleController) GetAll(c *gin.Context) {
+	var req models.GetVehiclesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid query parameters",
+		})
+		return
+	}
+
+	vehicles, err := ctrl.vehicleService.GetAll(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: vehicles,
+	})
+}
+
+// Create godoc
+// @Summary      登记车辆
+// @Description  登记车辆及其登记皮重，已停用的车牌号重新登记时恢复启用
+// @Tags         车辆管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        vehicle body models.CreateVehicleRequest true "车辆信息"
+// @Success      200 {object} models.Response{data=models.Vehicle} "登记成功"
+// @Failure      200 {object} models.Response "登记失败"
+// @Router       /vehicles [post]
+func (ctrl *VehicleController) Create(c *gin.Context) {
+	var req models.CreateVehicleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid request data",
+		})
+		return
+	}
+
+	vehicle, err := ctrl.vehicleService.Create(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "Vehicle created successfully",
+		Data: vehicle,
+	})
+}
+
+// GetByID godoc
+// @Summary      根据ID获取车辆
+// @Description  根据车辆ID获取车辆档案
+// @Tags         车辆管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "车辆ID"
+// @Success      200 {object} models.Response{data=models.Vehicle} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /vehicles/{id} [get]
+func (ctrl *VehicleController) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid vehicle ID",
+		})
+		return
+	}
+
+	vehicle, err := ctrl.vehicleService.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeNotFound,
+			Msg:  "Vehicle not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: vehicle,
+	})
+}
+
+// Update godoc
+// @Summary      更新车辆
+// @Description  根据ID更新车型、登记皮重、承运单位和常用司机，车牌号不可修改
+// @Tags         车辆管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "车辆ID"
+// @Param        vehicle body models.UpdateVehicleRequest true "车辆信息"
+// @Success      200 {object} models.Response{data=models.Vehicle} "更新成功"
+// @Failure      200 {object} models.Response "更新失败"
+// @Router       /vehicles/{id} [put]
+func (ctrl *VehicleController) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid vehicle ID",
+		})
+		return
+	}
+
+	var req models.UpdateVehicleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid request data",
+		})
+		return
+	}
+
+	vehicle, err := ctrl.vehicleService.Update(uint(id), &req)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "Vehicle updated successfully",
+		Data: vehicle,
+	})
+}
+
+// Delete godoc
+// @Summary      删除车辆
+// @Description  停用车辆档案，历史磅单和订单保留车号
+// @Tags         车辆管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "车辆ID"
+// @Success      200 {object} models.Response "删除成功"
+// @Failure      200 {object} models.Response "删除失败"
+// @Router       /vehicles/{id} [delete]
+func (ctrl *VehicleController) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid vehicle ID",
+		})
+		return
+	}
+
+	if err := ctrl.vehicleService.Delete(uint(id)); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "Vehicle deleted successfully",
+	})
+}
+
+// GetTrips godoc
+// @Summary      获取车辆车次记录
+// @Description  获取车辆的磅单和出库订单车次，汇总净重和皮重偏离次数
+// @Tags         车辆管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "车辆ID"
+// @Param        start_date query string false "开始日期 (YYYY-MM-DD)"
+// @Param        end_date query string false "结束日期 (YYYY-MM-DD)"
+// @Success      200 {object} models.Response{data=models.VehicleTripHistory} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /vehicles/{id}/trips [get]
+func (ctrl *VehicleController) GetTrips(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid vehicle ID",
+		})
+		return
+	}
+
+	var req models.GetVehicleTripsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid query parameters",
+		})
+		return
+	}
+
+	history, err := ctrl.vehicleService.GetTrips(uint(id), &req)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: history,
+	})
+}
+
+// GetAllDrivers godoc
+// @Summary      获取司机列表
+// @Description  获取所有启用的司机档案，支持按姓名或手机号模糊查询
+// @Tags         车辆管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        keyword query string false "姓名或手机号"
+// @Success      200 {object} models.Response{data=[]models.Driver} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /drivers [get]
+func (ctrl *VehicleController) GetAllDrivers(c *gin.Context) {
+	var req models.GetDriversRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid query parameters",
+		})
+		return
+	}
+
+	drivers, err := ctrl.vehicleService.GetAllDrivers(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: drivers,
+	})
+}
+
+// CreateDriver godoc
+// @Summary      登记司机
+// @Description  登记司机档案，同一手机号只能登记一名启用的司机
+// @Tags         车辆管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        driver body models.CreateDriverRequest true "司机信息"
+// @Success      200 {object} models.Response{data=models.Driver} "登记成功"
+// @Failure      200 {object} models.Response "登记失败"
+// @Router       /drivers [post]
+func (ctrl *VehicleController) CreateDriver(c *gin.Context) {
+	var req models.CreateDriverRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid request data",
+		})
+		return
+	}
+
+	driver, err := ctrl.vehicleService.CreateDriver(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "Driver created successfully",
+		Data: driver,
+	})
+}
+
+// GetDriverByID godoc
+// @Summary      根据ID获取司机
+// @Description  根据司机ID获取司机档案
+// @Tags         车辆管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "司机ID"
+// @Success      200 {object} models.Response{data=models.Driver} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /drivers/{id} [get]
+func (ctrl *VehicleController) GetDriverByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid driver ID",
+		})
+		return
+	}
+
+	driver, err := ctrl.vehicleService.GetDriverByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeNotFound,
+			Msg:  "Driver not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: driver,
+	})
+}
+
+// UpdateDriver godoc
+// @Summary      更新司机
+// @Description  根据ID更新司机姓名、手机号和驾驶证号
+// @Tags         车辆管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "司机ID"
+// @Param        driver body models.UpdateDriverRequest true "司机信息"
+// @Success      200 {object} models.Response{data=models.Driver} "更新成功"
+// @Failure      200 {object} models.Response "更新失败"
+// @Router       /drivers/{id} [put]
+func (ctrl *VehicleController) UpdateDriver(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid driver ID",
+		})
+		return
+	}
+
+	var req models.UpdateDriverRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid request data",
+		})
+		return
+	}
+
+	driver, err := ctrl.vehicleService.UpdateDriver(uint(id), &req)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "Driver updated successfully",
+		Data: driver,
+	})
+}
+
+// DeleteDriver godoc
+// @Summary      删除司机
+// @Description  停用司机档案，并取消其作为车辆常用司机的关联
+// @Tags         车辆管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "司机ID"
+// @Success      200 {object} models.Response "删除成功"
+// @Failure      200 {object} models.Response "删除失败"
+// @Router       /drivers/{id} [delete]
+func (ctrl *VehicleController) DeleteDriver(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid driver ID",
+		})
+		return
+	}
+
+	if err := ctrl.vehicleService.DeleteDriver(uint(id)); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "Driver deleted successfully",
+	})
+}
@@ -0,0 +1,203 @@
+package v1
+
+import (
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WarehouseController struct {
+	warehouseService *services.WarehouseService
+}
+
+func NewWarehouseController(warehouseService *services.WarehouseService) *WarehouseController {
+	return &WarehouseController{
+		warehouseService: warehouseService,
+	}
+}
+
+// GetAll godoc
+// @Summary      获取仓库列表
+// @Description  获取所有启用的仓库 (回收场/处理厂)
+// @Tags         仓库管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} models.Response{data=[]models.Warehouse} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /warehouses [get]
+func (ctrl *WarehouseController) GetAll(c *gin.Context) {
+	warehouses, err := ctrl.warehouseService.GetAll()
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: warehouses,
+	})
+}
+
+// Create godoc
+// @Summary      创建仓库
+// @Description  创建新的仓库并为已有分类初始化库存记录 (需要超级管理员权限)
+// @Tags         仓库管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        warehouse body models.CreateWarehouseRequest true "仓库信息"
+// @Success      200 {object} models.Response{data=models.Warehouse} "创建成功"
+// @Failure      200 {object} models.Response "创建失败"
+// @Router       /warehouses [post]
+func (ctrl *WarehouseController) Create(c *gin.Context) {
+	var req models.CreateWarehouseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid request data",
+		})
+		return
+	}
+
+	warehouse, err := ctrl.warehouseService.Create(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "Warehouse created successfully",
+		Data: warehouse,
+	})
+}
+
+// GetByID godoc
+// @Summary      根据ID获取仓库
+// @Description  根据仓库ID获取仓库信息
+// @Tags         仓库管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "仓库ID"
+// @Success      200 {object} models.Response{data=models.Warehouse} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /warehouses/{id} [get]
+func (ctrl *WarehouseController) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid warehouse ID",
+		})
+		return
+	}
+
+	warehouse, err := ctrl.warehouseService.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeNotFound,
+			Msg:  "Warehouse not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: warehouse,
+	})
+}
+
+// Update godoc
+// @Summary      更新仓库
+// @Description  根据ID更新仓库名称、类型和地址 (需要超级管理员权限)
+// @Tags         仓库管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "仓库ID"
+// @Param        warehouse body models.UpdateWarehouseRequest true "仓库信息"
+// @Success      200 {object} models.Response{data=models.Warehouse} "更新成功"
+// @Failure      200 {object} models.Response "更新失败"
+// @Router       /warehouses/{id} [put]
+func (ctrl *WarehouseController) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid warehouse ID",
+		})
+		return
+	}
+
+	var req models.UpdateWarehouseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid request data",
+		})
+		return
+	}
+
+	warehouse, err := ctrl.warehouseService.Update(uint(id), &req)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "Warehouse updated successfully",
+		Data: warehouse,
+	})
+}
+
+// Delete godoc
+// @Summary      删除仓库
+// @Description  停用仓库，默认仓库和仍有库存的仓库不能停用 (需要超级管理员权限)
+// @Tags         仓库管理
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "仓库ID"
+// @Success      200 {object} models.Response "删除成功"
+// @Failure      200 {object} models.Response "删除失败"
+// @Router       /warehouses/{id} [delete]
+func (ctrl *WarehouseController) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid warehouse ID",
+		})
+		return
+	}
+
+	if err := ctrl.warehouseService.Delete(uint(id)); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "Warehouse deleted successfully",
+	})
+}
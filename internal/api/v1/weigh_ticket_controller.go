@@ -0,0 +1,308 @@
+package v1
+
+import (
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/services"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WeighTicketController struct {
+	weighTicketService *services.WeighTicketService
+}
+
+func NewWeighTicketController(weighTicketService *services.WeighTicketService) *WeighTicketController {
+	return &WeighTicketController{
+		weighTicketService: weighTicketService,
+	}
+}
+
+// Create godoc
+// @Summary      首次称重
+// @Description  车辆首次过磅开具磅单，未填写重量时取地磅当前稳定读数
+// @Tags         磅单
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        ticket body models.CreateWeighTicketRequest true "磅单信息"
+// @Success      200 {object} models.Response{data=models.WeighTicket} "开单成功"
+// @Failure      200 {object} models.Response "开单失败"
+// @Router       /weigh-tickets [post]
+func (ctrl *WeighTicketController) Create(c *gin.Context) {
+	var req models.CreateWeighTicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid request data",
+		})
+		return
+	}
+
+	// Get current user from context
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	ticket, err := ctrl.weighTicketService.Create(&req, userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "Weigh ticket created successfully",
+		Data: ticket,
+	})
+}
+
+// GetAll godoc
+// @Summary      获取磅单列表
+// @Description  分页获取磅单，支持按方向、状态、车号和首次称重日期筛选
+// @Tags         磅单
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page query int false "页码" default(1)
+// @Param        page_size query int false "每页数量" default(20)
+// @Param        direction query string false "方向 (inbound/outbound)"
+// @Param        status query string false "状态 (open/closed/converted/cancelled)"
+// @Param        car_number query string false "车号"
+// @Param        tare_flagged query bool false "仅返回皮重偏离的磅单"
+// @Param        start_date query string false "开始日期 (YYYY-MM-DD)"
+// @Param        end_date query string false "结束日期 (YYYY-MM-DD)"
+// @Success      200 {object} models.Response{data=models.GetWeighTicketsResponse} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /weigh-tickets [get]
+func (ctrl *WeighTicketController) GetAll(c *gin.Context) {
+	var req models.GetWeighTicketsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid query parameters",
+		})
+		return
+	}
+
+	// 设置默认值
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+
+	resp, err := ctrl.weighTicketService.GetAll(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: resp,
+	})
+}
+
+// GetByID godoc
+// @Summary      根据ID获取磅单
+// @Description  获取磅单两次称重记录、净重及转入的订单
+// @Tags         磅单
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "磅单ID"
+// @Success      200 {object} models.Response{data=models.WeighTicket} "获取成功"
+// @Failure      200 {object} models.Response "获取失败"
+// @Router       /weigh-tickets/{id} [get]
+func (ctrl *WeighTicketController) GetByID(c *gin.Context) {
+	id, ok := ticketID(c)
+	if !ok {
+		return
+	}
+
+	ticket, err := ctrl.weighTicketService.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeNotFound,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "success",
+		Data: ticket,
+	})
+}
+
+// SecondWeigh godoc
+// @Summary      二次称重
+// @Description  车辆二次过磅并计算毛重、皮重和净重，未填写重量时取地磅当前稳定读数；实测皮重偏离车辆登记皮重时标记 tare_flagged
+// @Tags         磅单
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "磅单ID"
+// @Param        weigh body models.SecondWeighRequest false "二次称重重量"
+// @Success      200 {object} models.Response{data=models.WeighTicket} "称重成功"
+// @Failure      200 {object} models.Response "称重失败"
+// @Router       /weigh-tickets/{id}/second-weigh [post]
+func (ctrl *WeighTicketController) SecondWeigh(c *gin.Context) {
+	id, ok := ticketID(c)
+	if !ok {
+		return
+	}
+
+	// 重量可选，允许空请求体
+	var req models.SecondWeighRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusOK, &models.Response{
+				Code: models.CodeBadRequest,
+				Msg:  "Invalid request data",
+			})
+			return
+		}
+	}
+
+	// Get current user from context
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	ticket, err := ctrl.weighTicketService.SecondWeigh(id, &req, userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	// 实测皮重偏离登记皮重时提示司磅员核对
+	msg := "Second weighing recorded successfully"
+	if ticket.TareFlagged {
+		msg = fmt.Sprintf("Second weighing recorded, measured tare %.3f kg deviates from registered tare %.3f kg", ticket.TareWeight, ticket.RegisteredTare)
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  msg,
+		Data: ticket,
+	})
+}
+
+// Convert godoc
+// @Summary      磅单转订单
+// @Description  把已二次称重的进场磅单转为入库订单、出场磅单转为出库订单，订单关联磅单。出库订单超过客户信用额度时拒绝 (40300)，超级管理员可指定 credit_override 强制下单
+// @Tags         磅单
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "磅单ID"
+// @Param        order body models.ConvertWeighTicketRequest true "订单信息"
+// @Success      200 {object} models.Response{data=models.WeighTicket} "转换成功"
+// @Failure      200 {object} models.Response "转换失败"
+// @Router       /weigh-tickets/{id}/convert [post]
+func (ctrl *WeighTicketController) Convert(c *gin.Context) {
+	id, ok := ticketID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ConvertWeighTicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid request data",
+		})
+		return
+	}
+
+	// Get current user from context
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	// 超过信用额度强制下单需要超级管理员权限
+	if req.CreditOverride && userModel.Role != "super_admin" {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeForbidden,
+			Msg:  "Only super_admin can override credit limit",
+		})
+		return
+	}
+
+	ticket, err := ctrl.weighTicketService.Convert(id, &req, userModel.ID)
+	if err != nil {
+		code := models.CodeInternalError
+		if errors.Is(err, services.ErrCreditLimitExceeded) {
+			code = models.CodeForbidden
+		}
+		c.JSON(http.StatusOK, &models.Response{
+			Code: code,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "Weigh ticket converted successfully",
+		Data: ticket,
+	})
+}
+
+// Cancel godoc
+// @Summary      作废磅单
+// @Description  作废尚未转为订单的磅单
+// @Tags         磅单
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "磅单ID"
+// @Success      200 {object} models.Response "作废成功"
+// @Failure      200 {object} models.Response "作废失败"
+// @Router       /weigh-tickets/{id}/cancel [post]
+func (ctrl *WeighTicketController) Cancel(c *gin.Context) {
+	id, ok := ticketID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.weighTicketService.Cancel(id); err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeInternalError,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.Response{
+		Code: models.CodeSuccess,
+		Msg:  "Weigh ticket cancelled successfully",
+	})
+}
+
+// ticketID 解析路径中的磅单ID，无效时写入错误响应
+func ticketID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusOK, &models.Response{
+			Code: models.CodeBadRequest,
+			Msg:  "Invalid weigh ticket ID",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
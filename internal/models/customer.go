@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+// Customer 客户 (出库订单的买方)
+type Customer struct {
+	ID            uint              `json:"id" gorm:"primaryKey"`
+	Name          string            `json:"name" gorm:"size:100;not null;index"`
+	ContactPerson string            `json:"contact_person" gorm:"size:50"`
+	Phone         string            `json:"phone" gorm:"size:20"`
+	TaxID         string            `json:"tax_id" gorm:"size:50"`                                     // 纳税人识别号
+	CreditLimit   float64           `json:"credit_limit" gorm:"type:decimal(15,2);not null;default:0"` // 信用额度，0 表示不限
+	Notes         string            `json:"notes" gorm:"type:text"`
+	IsActive      bool              `json:"is_active" gorm:"default:true"`
+	Addresses     []CustomerAddress `json:"addresses,omitempty" gorm:"-"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+// TableName sets the insert table name for this struct type
+func (Customer) TableName() string {
+	return "customers"
+}
+
+// CustomerAddress 客户送货地址
+type CustomerAddress struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	CustomerID uint      `json:"customer_id" gorm:"index;not null"`
+	Label      string    `json:"label" gorm:"size:50"` // 地址名称，如 "总部"、"仓库"
+	Address    string    `json:"address" gorm:"size:255;not null"`
+	IsDefault  bool      `json:"is_default" gorm:"default:false"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TableName sets the insert table name for this struct type
+func (CustomerAddress) TableName() string {
+	return "customer_addresses"
+}
+
+// CustomerAddressInput 创建/更新客户时的地址
+type CustomerAddressInput struct {
+	Label     string `json:"label"`
+	Address   string `json:"address" binding:"required"`
+	IsDefault bool   `json:"is_default"`
+}
+
+// CreateCustomerRequest represents request to create customer
+type CreateCustomerRequest struct {
+	Name          string                 `json:"name" binding:"required"`
+	ContactPerson string                 `json:"contact_person"`
+	Phone         string                 `json:"phone"`
+	TaxID         string                 `json:"tax_id"`
+	CreditLimit   float64                `json:"credit_limit" binding:"gte=0"`
+	Notes         string                 `json:"notes"`
+	Addresses     []CustomerAddressInput `json:"addresses" binding:"dive"`
+}
+
+// UpdateCustomerRequest represents request to update customer
+// Addresses 不为 nil 时整体替换客户地址
+type UpdateCustomerRequest struct {
+	Name          string                 `json:"name"`
+	ContactPerson string                 `json:"contact_person"`
+	Phone         string                 `json:"phone"`
+	TaxID         string                 `json:"tax_id"`
+	CreditLimit   *float64               `json:"credit_limit" binding:"omitempty,gte=0"` // 为 0 时取消信用额度
+	Notes         string                 `json:"notes"`
+	Addresses     []CustomerAddressInput `json:"addresses" binding:"omitempty,dive"`
+}
+
+// GetCustomerOrdersRequest 查询客户历史订单请求
+type GetCustomerOrdersRequest struct {
+	Page      int    `json:"page" form:"page" binding:"omitempty,min=1"`
+	PageSize  int    `json:"page_size" form:"page_size" binding:"omitempty,min=1,max=100"`
+	StartDate string `json:"start_date" form:"start_date"`
+	EndDate   string `json:"end_date" form:"end_date"`
+}
+
+// CustomerOrderHistory 客户历史出库订单及汇总
+type CustomerOrderHistory struct {
+	Customer    Customer        `json:"customer"`
+	Orders      []OutboundOrder `json:"orders"`
+	Total       int64           `json:"total"`
+	TotalWeight float64         `json:"total_weight"` // 已记账订单出库重量合计
+	TotalAmount float64         `json:"total_amount"` // 已记账订单金额合计
+}
@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// 库存调整方向
+const (
+	AdjustmentDirectionIncrease = "increase" // 盘盈/增加
+	AdjustmentDirectionDecrease = "decrease" // 盘亏/减少
+)
+
+// 库存调整单状态
+const (
+	AdjustmentStatusPending  = "pending"  // 待审核
+	AdjustmentStatusApproved = "approved" // 已审核并记入库存
+	AdjustmentStatusRejected = "rejected" // 已驳回
+)
+
+// InventoryAdjustment 手工库存调整单
+// 调整重量不超过审核阈值时创建即记入库存，超过时需超级管理员审核后记入
+type InventoryAdjustment struct {
+	ID               uint       `json:"id" gorm:"primaryKey"`
+	AdjustmentNo     string     `json:"adjustment_no" gorm:"uniqueIndex;size:50;not null"`
+	WarehouseID      uint       `json:"warehouse_id" gorm:"index;not null;default:0"`
+	CategoryID       uint       `json:"category_id" gorm:"index;not null"`
+	Direction        string     `json:"direction" gorm:"size:10;not null"`               // increase / decrease
+	Weight           float64    `json:"weight" gorm:"type:decimal(12,3);not null"`       // 调整重量 kg (正数)
+	Reason           string     `json:"reason" gorm:"size:30;not null"`                  // 调整原因
+	Notes            string     `json:"notes" gorm:"type:text"`                          // 备注
+	AttachmentURL    string     `json:"attachment_url" gorm:"size:500"`                  // 附件 (照片/单据) 地址
+	Status           string     `json:"status" gorm:"size:20;not null;index"`            // 状态
+	RequiresApproval bool       `json:"requires_approval" gorm:"not null;default:false"` // 是否超过审核阈值
+	CreatedBy        uint       `json:"created_by" gorm:"not null"`
+	ReviewedBy       *uint      `json:"reviewed_by"`
+	ReviewedAt       *time.Time `json:"reviewed_at"`
+	ReviewNotes      string     `json:"review_notes" gorm:"size:255"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// TableName sets the insert table name for this struct type
+func (InventoryAdjustment) TableName() string {
+	return "inventory_adjustments"
+}
+
+// CreateInventoryAdjustmentRequest 创建库存调整请求
+type CreateInventoryAdjustmentRequest struct {
+	WarehouseID   uint    `json:"warehouse_id" binding:"required"`
+	CategoryID    uint    `json:"category_id" binding:"required"`
+	Direction     string  `json:"direction" binding:"required,oneof=increase decrease"`
+	Weight        float64 `json:"weight" binding:"required,gt=0"`
+	Reason        string  `json:"reason" binding:"required,oneof=damage moisture_loss theft correction"`
+	Notes         string  `json:"notes"`
+	AttachmentURL string  `json:"attachment_url" binding:"omitempty,url"`
+}
+
+// ReviewInventoryAdjustmentRequest 审核/驳回库存调整请求
+type ReviewInventoryAdjustmentRequest struct {
+	ReviewNotes string `json:"review_notes"`
+}
+
+// GetInventoryAdjustmentsRequest 查询库存调整请求
+type GetInventoryAdjustmentsRequest struct {
+	Page        int    `json:"page" form:"page" binding:"omitempty,min=1"`
+	PageSize    int    `json:"page_size" form:"page_size" binding:"omitempty,min=1,max=100"`
+	Status      string `json:"status" form:"status"`
+	CategoryID  uint   `json:"category_id" form:"category_id"`
+	WarehouseID uint   `json:"warehouse_id" form:"warehouse_id"`
+}
+
+type GetInventoryAdjustmentsResponse struct {
+	Adjustments []InventoryAdjustment `json:"adjustments"`
+	Total       int64                 `json:"total"`
+}
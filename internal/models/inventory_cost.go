@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// 库存计价方法
+const (
+	CostingMethodMovingAverage = "moving_average" // 移动加权平均
+	CostingMethodFIFO          = "fifo"           // 先进先出
+)
+
+// CostLayer 先进先出计价的成本层，每次入库形成一层，出库按先后顺序消耗
+type CostLayer struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	WarehouseID     uint      `json:"warehouse_id" gorm:"index;not null;default:0"`               // 仓库ID
+	CategoryID      uint      `json:"category_id" gorm:"index;not null"`                          // 电池类型ID
+	SourceType      string    `json:"source_type" gorm:"size:30;not null;index:idx_layer_source"` // 来源单据类型
+	SourceID        uint      `json:"source_id" gorm:"not null;index:idx_layer_source"`           // 来源单据ID
+	OriginalWeight  float64   `json:"original_weight" gorm:"type:decimal(12,3);not null"`         // 入库重量 kg
+	RemainingWeight float64   `json:"remaining_weight" gorm:"type:decimal(12,3);not null"`        // 剩余重量 kg
+	UnitCost        float64   `json:"unit_cost" gorm:"type:decimal(12,4);not null"`               // 单位成本 元/kg
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// TableName sets the insert table name for this struct type
+func (CostLayer) TableName() string {
+	return "cost_layers"
+}
+
+// InventoryValuation 单个分类在某日的库存估值
+type InventoryValuation struct {
+	CategoryID    uint    `json:"category_id"`
+	CategoryName  string  `json:"category_name"`
+	CostingMethod string  `json:"costing_method"`
+	WeightKg      float64 `json:"weight_kg"`     // 结存重量 kg
+	AvgUnitCost   float64 `json:"avg_unit_cost"` // 平均单位成本 元/kg
+	TotalValue    float64 `json:"total_value"`   // 库存金额
+}
+
+// InventoryValuationReport 库存估值报表
+type InventoryValuationReport struct {
+	WarehouseID uint                 `json:"warehouse_id,omitempty"` // 为空时为全部仓库合计
+	AsOf        string               `json:"as_of"`
+	Categories  []InventoryValuation `json:"categories"`
+	TotalWeight float64              `json:"total_weight"`
+	TotalValue  float64              `json:"total_value"`
+}
@@ -0,0 +1,82 @@
+package models
+
+import "time"
+
+// 库存流水来源单据类型
+const (
+	MovementSourceInbound     = "inbound_order"  // 入库订单
+	MovementSourceOutbound    = "outbound_order" // 出库订单
+	MovementSourceAdjustment  = "adjustment"     // 库存调整
+	MovementSourceStocktake   = "stocktake"      // 盘点
+	MovementSourceTransferOut = "transfer_out"   // 调拨发货 (调出仓)
+	MovementSourceTransferIn  = "transfer_in"    // 调拨收货 (调入仓) 或在途取消退回
+	MovementSourceOpening     = "opening"        // 期初结存 (引入库存流水前已有的库存)
+)
+
+// 库存调整原因
+const (
+	AdjustmentReasonMoistureLoss = "moisture_loss" // 水分损耗
+	AdjustmentReasonScaleError   = "scale_error"   // 磅秤误差
+	AdjustmentReasonDamage       = "damage"        // 损坏
+	AdjustmentReasonTheft        = "theft"         // 失窃
+	AdjustmentReasonCorrection   = "correction"    // 更正
+)
+
+// InventoryMovement 库存流水，每次库存重量变化都会在同一事务中写入一条
+type InventoryMovement struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`                                          // 流水ID
+	WarehouseID  uint      `json:"warehouse_id" gorm:"index;not null;default:0"`                  // 仓库ID
+	CategoryID   uint      `json:"category_id" gorm:"index;not null"`                             // 电池类型ID
+	Delta        float64   `json:"delta" gorm:"type:decimal(12,3);not null"`                      // 变动重量 kg (入库为正, 出库为负)
+	BalanceAfter float64   `json:"balance_after" gorm:"type:decimal(12,3);not null"`              // 变动后结存 kg
+	UnitCost     float64   `json:"unit_cost" gorm:"type:decimal(12,4);not null;default:0"`        // 本次变动的单位成本 元/kg
+	CostAmount   float64   `json:"cost_amount" gorm:"type:decimal(14,2);not null;default:0"`      // 变动金额 (入库为正, 出库为负)
+	ValueAfter   float64   `json:"value_after" gorm:"type:decimal(14,2);not null;default:0"`      // 变动后库存金额
+	SourceType   string    `json:"source_type" gorm:"size:30;not null;index:idx_movement_source"` // 来源单据类型
+	SourceID     uint      `json:"source_id" gorm:"not null;index:idx_movement_source"`           // 来源单据ID
+	Reason       string    `json:"reason,omitempty" gorm:"size:30"`                               // 调整原因 (盘点/库存调整)
+	CreatedBy    uint      `json:"created_by" gorm:"not null"`                                    // 操作人
+	CreatedAt    time.Time `json:"created_at" gorm:"index"`                                       // 发生时间
+}
+
+// TableName sets the insert table name for this struct type
+func (InventoryMovement) TableName() string {
+	return "inventory_movements"
+}
+
+// MovementSource 库存变动的来源单据和操作人
+type MovementSource struct {
+	SourceType string
+	SourceID   uint
+	UserID     uint
+	Reason     string // 调整原因，仅盘点和库存调整填写
+	// UnitCost 指定单位成本: 入库为入账成本 (为 0 时按当前平均成本)；
+	// 出库时仅移动加权平均生效，用于按原价冲回入库
+	UnitCost float64
+}
+
+// GetInventoryMovementsRequest 查询库存流水请求
+type GetInventoryMovementsRequest struct {
+	Page      int    `json:"page" form:"page" binding:"omitempty,min=1"`
+	PageSize  int    `json:"page_size" form:"page_size" binding:"omitempty,min=1,max=100"`
+	StartDate string `json:"start_date" form:"start_date"`
+	EndDate   string `json:"end_date" form:"end_date"`
+	// WarehouseID 仓库ID，为空时查询全部仓库
+	WarehouseID uint `json:"warehouse_id" form:"warehouse_id"`
+}
+
+type GetInventoryMovementsResponse struct {
+	Movements []InventoryMovement `json:"movements"`
+	Total     int64               `json:"total"`
+}
+
+// InventoryLedgerCheck 根据流水重算的库存与当前库存的对账结果
+type InventoryLedgerCheck struct {
+	WarehouseID   uint    `json:"warehouse_id"`
+	CategoryID    uint    `json:"category_id"`
+	CategoryName  string  `json:"category_name"`
+	CurrentWeight float64 `json:"current_weight"` // inventories.current_weight_kg
+	LedgerWeight  float64 `json:"ledger_weight"`  // 流水变动合计
+	Difference    float64 `json:"difference"`     // current_weight - ledger_weight
+	Consistent    bool    `json:"consistent"`
+}
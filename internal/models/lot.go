@@ -0,0 +1,108 @@
+package models
+
+import "time"
+
+// Lot 批次，每个已记账的入库订单项生成一个批次；调拨收货时在调入仓生成子批次
+type Lot struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	LotNo           string    `json:"lot_no" gorm:"uniqueIndex;size:50;not null"`
+	WarehouseID     uint      `json:"warehouse_id" gorm:"index:idx_lot_stock;not null"`       // 所在仓库ID
+	CategoryID      uint      `json:"category_id" gorm:"index:idx_lot_stock;not null"`        // 电池类型ID
+	CategoryName    string    `json:"category_name" gorm:"-"`                                 // 电池类型名称
+	InboundOrderID  uint      `json:"inbound_order_id" gorm:"index;not null"`                 // 来源入库订单ID (子批次继承)
+	InboundItemID   uint      `json:"inbound_item_id" gorm:"index;not null;default:0"`        // 来源入库订单项ID，子批次为 0
+	ParentLotID     *uint     `json:"parent_lot_id" gorm:"index"`                             // 父批次ID (调拨生成的子批次)
+	TransferID      *uint     `json:"transfer_id" gorm:"index"`                               // 生成子批次的调拨单ID
+	SellerID        *uint     `json:"seller_id" gorm:"index"`                                 // 卖家(供应商)ID
+	SupplierName    string    `json:"supplier_name" gorm:"size:100;not null"`                 // 供应商名称
+	Grade           string    `json:"grade" gorm:"size:20"`                                   // 等级
+	ReceivedAt      time.Time `json:"received_at" gorm:"index"`                               // 供应商交货时间，先进先出按此排序
+	UnitCost        float64   `json:"unit_cost" gorm:"type:decimal(12,4);not null;default:0"` // 入库单价 元/kg
+	OriginalWeight  float64   `json:"original_weight" gorm:"type:decimal(12,3);not null"`     // 批次重量 kg
+	RemainingWeight float64   `json:"remaining_weight" gorm:"type:decimal(12,3);not null"`    // 剩余重量 kg
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// TableName sets the insert table name for this struct type
+func (Lot) TableName() string {
+	return "lots"
+}
+
+// LotAllocation 批次分配，记录出库、调拨发货和库存减少消耗了哪些批次
+// 出库订单手工指定的批次在记账前为未记账状态 (Posted=false)，不占用批次剩余重量
+type LotAllocation struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	LotID        uint      `json:"lot_id" gorm:"index;not null"`
+	SourceType   string    `json:"source_type" gorm:"size:30;not null;index:idx_lot_allocation_source"` // 来源单据类型，同库存流水
+	SourceID     uint      `json:"source_id" gorm:"not null;index:idx_lot_allocation_source"`           // 来源单据ID
+	SourceItemID uint      `json:"source_item_id" gorm:"not null;default:0"`                            // 来源单据明细ID
+	Weight       float64   `json:"weight" gorm:"type:decimal(12,3);not null"`                           // 分配重量 kg
+	Posted       bool      `json:"posted" gorm:"not null;default:false"`                                // 是否已扣减批次剩余重量
+	CreatedBy    uint      `json:"created_by" gorm:"not null"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName sets the insert table name for this struct type
+func (LotAllocation) TableName() string {
+	return "lot_allocations"
+}
+
+// LotSelection 出库订单项手工指定的批次和重量，各批次重量合计须等于订单项重量
+type LotSelection struct {
+	LotID  uint    `json:"lot_id" binding:"required"`
+	Weight float64 `json:"weight" binding:"required,gt=0"`
+}
+
+// GetLotsRequest 查询批次请求
+type GetLotsRequest struct {
+	Page           int  `json:"page" form:"page" binding:"omitempty,min=1"`
+	PageSize       int  `json:"page_size" form:"page_size" binding:"omitempty,min=1,max=100"`
+	WarehouseID    uint `json:"warehouse_id" form:"warehouse_id"`
+	CategoryID     uint `json:"category_id" form:"category_id"`
+	InboundOrderID uint `json:"inbound_order_id" form:"inbound_order_id"`
+	SellerID       uint `json:"seller_id" form:"seller_id"`
+	// Available 为 true 时仅返回有剩余重量的批次
+	Available bool `json:"available" form:"available"`
+}
+
+type GetLotsResponse struct {
+	Lots  []Lot `json:"lots"`
+	Total int64 `json:"total"`
+}
+
+// LotConsumption 批次被单据消耗的明细
+type LotConsumption struct {
+	LotID        uint      `json:"lot_id"`
+	LotNo        string    `json:"lot_no"`
+	SourceType   string    `json:"source_type"`
+	SourceID     uint      `json:"source_id"`
+	SourceItemID uint      `json:"source_item_id"`
+	DocumentNo   string    `json:"document_no"`  // 出库订单号/调拨单号
+	Counterparty string    `json:"counterparty"` // 出库客户名称
+	Weight       float64   `json:"weight"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// LotGenealogy 批次谱系: 上溯到供应商交货批次，下溯到调拨子批次和消耗该批次的出库订单
+type LotGenealogy struct {
+	Lot          Lot              `json:"lot"`
+	Ancestors    []Lot            `json:"ancestors"`    // 父批次链，最后一个为供应商交货批次
+	Consumptions []LotConsumption `json:"consumptions"` // 本批次的消耗
+	Children     []LotGenealogy   `json:"children"`     // 调拨生成的子批次
+}
+
+// ShipmentLotTrace 出库订单消耗的批次及其供应商来源
+type ShipmentLotTrace struct {
+	OutboundOrderID uint      `json:"outbound_order_id"`
+	OutboundItemID  uint      `json:"outbound_item_id"`
+	CategoryID      uint      `json:"category_id"`
+	LotID           uint      `json:"lot_id"`
+	LotNo           string    `json:"lot_no"`
+	Weight          float64   `json:"weight"`
+	InboundOrderID  uint      `json:"inbound_order_id"`
+	InboundOrderNo  string    `json:"inbound_order_no"`
+	SupplierName    string    `json:"supplier_name"`
+	Grade           string    `json:"grade"`
+	ReceivedAt      time.Time `json:"received_at"`
+}
@@ -23,13 +23,16 @@ func (User) TableName() string {
 
 // BatteryCategory represents a battery category/type
 type BatteryCategory struct {
-	ID          uint      `json:"id" gorm:"primaryKey"`
-	Name        string    `json:"name" gorm:"size:100;not null"`
-	Description string    `json:"description" gorm:"size:255"`
-	UnitPrice   float64   `json:"unit_price" gorm:"type:decimal(10,2);not null"` // Price per kg
-	IsActive    bool      `json:"is_active" gorm:"default:true"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uint    `json:"id" gorm:"primaryKey"`
+	Name        string  `json:"name" gorm:"size:100;not null"`
+	Description string  `json:"description" gorm:"size:255"`
+	UnitPrice   float64 `json:"unit_price" gorm:"type:decimal(10,2);not null"` // Price per kg，最近一次写入价格时的当前收购价快照，接口返回当前有效的收购价 (价格历史见 CategoryPrice)
+	// CostingMethod 库存计价方法: moving_average 或 fifo
+	CostingMethod string    `json:"costing_method" gorm:"size:20;not null;default:'moving_average'" binding:"omitempty,oneof=moving_average fifo"`
+	WasteCode     string    `json:"waste_code" gorm:"size:30"` // 危险废物代码，如 HW31 900-052-31
+	IsActive      bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 // TableName sets the insert table name for this struct type
@@ -39,16 +42,22 @@ func (BatteryCategory) TableName() string {
 
 // InboundOrder represents a purchase/inbound order
 type InboundOrder struct {
-	ID           uint      `json:"id" gorm:"primaryKey"`                               // 订单ID
-	OrderNo      string    `json:"order_no" gorm:"uniqueIndex;size:50;not null"`       // 订单号
-	SupplierName string    `json:"supplier_name" gorm:"size:100;not null"`             // 供应商名称
-	TotalAmount  float64   `json:"total_amount" gorm:"type:decimal(15,2);not null"`    // 总金额
-	Status       string    `json:"status" gorm:"size:20;not null;default:'completed'"` // 'completed', 'cancelled'
-	Notes        string    `json:"notes" gorm:"type:text"`                             // 备注
-	CreatedBy    uint      `json:"created_by" gorm:"not null"`                         // 创建人
-	IsDeleted    int       `json:"is_deleted" gorm:"default:0"`                        // 是否删除
-	CreatedAt    time.Time `json:"created_at"`                                         // 创建时间
-	UpdatedAt    time.Time `json:"updated_at"`                                         // 更新时间
+	ID            uint      `json:"id" gorm:"primaryKey"`                                          // 订单ID
+	OrderNo       string    `json:"order_no" gorm:"uniqueIndex;size:50;not null"`                  // 订单号
+	WarehouseID   uint      `json:"warehouse_id" gorm:"index;not null;default:0"`                  // 入库仓库ID
+	SellerID      *uint     `json:"seller_id" gorm:"index"`                                        // 卖家(供应商)ID
+	SupplierName  string    `json:"supplier_name" gorm:"size:100;not null"`                        // 供应商名称 (下单时快照)
+	TotalAmount   float64   `json:"total_amount" gorm:"type:decimal(15,2);not null"`               // 总金额
+	PaidAmount    float64   `json:"paid_amount" gorm:"type:decimal(15,2);not null;default:0"`      // 已付金额
+	PaymentStatus string    `json:"payment_status" gorm:"size:20;not null;default:'unpaid';index"` // 付款状态 unpaid/partial/paid
+	Status        string    `json:"status" gorm:"size:20;not null;default:'completed'"`            // 'draft', 'confirmed', 'completed', 'cancelled'
+	Notes         string    `json:"notes" gorm:"type:text"`                                        // 备注
+	PriceFlagged  bool      `json:"price_flagged" gorm:"not null;default:false;index"`             // 有订单项单价偏离价目表
+	WeighTicketID *uint     `json:"weigh_ticket_id" gorm:"index"`                                  // 由磅单转入时的磅单ID
+	CreatedBy     uint      `json:"created_by" gorm:"not null"`                                    // 创建人
+	IsDeleted     int       `json:"is_deleted" gorm:"default:0"`                                   // 是否删除
+	CreatedAt     time.Time `json:"created_at"`                                                    // 创建时间
+	UpdatedAt     time.Time `json:"updated_at"`                                                    // 更新时间
 }
 
 // TableName sets the insert table name for this struct type
@@ -58,16 +67,36 @@ func (InboundOrder) TableName() string {
 
 // InboundOrderItem represents items in an inbound order
 type InboundOrderItem struct {
-	ID          uint      `json:"id" gorm:"primaryKey"`                            // 订单项ID
-	OrderID     uint      `json:"order_id" gorm:"not null"`                        // 订单ID
-	CategoryID  uint      `json:"category_id" gorm:"not null"`                     // 电池类型ID
-	GrossWeight float64   `json:"gross_weight" gorm:"type:decimal(10,3);not null"` // kg
-	TareWeight  float64   `json:"tare_weight" gorm:"type:decimal(10,3);not null"`  // kg
-	NetWeight   float64   `json:"net_weight" gorm:"type:decimal(10,3);not null"`   // kg
-	UnitPrice   float64   `json:"unit_price" gorm:"type:decimal(10,2);not null"`   // 单价
-	SubTotal    float64   `json:"sub_total" gorm:"type:decimal(15,2);not null"`    // 小计
-	CreatedAt   time.Time `json:"created_at"`                                      // 创建时间
-	UpdatedAt   time.Time `json:"updated_at"`                                      // 更新时间
+	ID          uint    `json:"id" gorm:"primaryKey"`                            // 订单项ID
+	OrderID     uint    `json:"order_id" gorm:"not null"`                        // 订单ID
+	CategoryID  uint    `json:"category_id" gorm:"not null"`                     // 电池类型ID
+	GrossWeight float64 `json:"gross_weight" gorm:"type:decimal(10,3);not null"` // kg
+	TareWeight  float64 `json:"tare_weight" gorm:"type:decimal(10,3);not null"`  // kg
+	NetWeight   float64 `json:"net_weight" gorm:"type:decimal(10,3);not null"`   // kg，按净重记入库存
+	InboundDeductions
+	GrossFromScale   bool      `json:"gross_from_scale" gorm:"not null;default:false"`                 // 毛重由地磅采集
+	TareFromScale    bool      `json:"tare_from_scale" gorm:"not null;default:false"`                  // 皮重由地磅采集
+	DeductionWeight  float64   `json:"deduction_weight" gorm:"type:decimal(10,3);not null;default:0"`  // 扣杂重量合计 kg
+	SettlementWeight float64   `json:"settlement_weight" gorm:"type:decimal(10,3);not null;default:0"` // 结算重量 = 净重 - 扣杂重量 kg
+	UnitPrice        float64   `json:"unit_price" gorm:"type:decimal(10,2);not null"`                  // 单价
+	ListPrice        float64   `json:"list_price" gorm:"type:decimal(10,2);not null;default:0"`        // 下单时的价目表收购价，0 表示无价目
+	SubTotal         float64   `json:"sub_total" gorm:"type:decimal(15,2);not null"`                   // 小计 = 结算重量 * 单价
+	Grade            string    `json:"grade" gorm:"size:20"`                                           // 质量等级，记入批次
+	CreatedAt        time.Time `json:"created_at"`                                                     // 创建时间
+	UpdatedAt        time.Time `json:"updated_at"`                                                     // 更新时间
+}
+
+// InboundDeductions 入库扣杂：水分、包装、杂质和混入其他化学体系的电池
+// 每项可填写百分比 (按净重计算扣除重量) 或直接填写扣除重量，二者择一
+type InboundDeductions struct {
+	MoisturePercent  float64 `json:"moisture_percent" gorm:"type:decimal(5,2);not null;default:0"`  // 水分扣除比例 %
+	MoistureWeight   float64 `json:"moisture_weight" gorm:"type:decimal(10,3);not null;default:0"`  // 水分扣除重量 kg
+	PackagingPercent float64 `json:"packaging_percent" gorm:"type:decimal(5,2);not null;default:0"` // 包装扣除比例 %
+	PackagingWeight  float64 `json:"packaging_weight" gorm:"type:decimal(10,3);not null;default:0"` // 包装扣除重量 kg
+	ImpurityPercent  float64 `json:"impurity_percent" gorm:"type:decimal(5,2);not null;default:0"`  // 杂质扣除比例 %
+	ImpurityWeight   float64 `json:"impurity_weight" gorm:"type:decimal(10,3);not null;default:0"`  // 杂质扣除重量 kg
+	MixedPercent     float64 `json:"mixed_percent" gorm:"type:decimal(5,2);not null;default:0"`     // 混杂电池扣除比例 %
+	MixedWeight      float64 `json:"mixed_weight" gorm:"type:decimal(10,3);not null;default:0"`     // 混杂电池扣除重量 kg
 }
 
 // TableName sets the insert table name for this struct type
@@ -77,19 +106,29 @@ func (InboundOrderItem) TableName() string {
 
 // OutboundOrder represents a sales/outbound order
 type OutboundOrder struct {
-	ID              uint      `json:"id" gorm:"primaryKey"`                               // 主键
-	OrderNo         string    `json:"order_no" gorm:"size:50;uniqueIndex;not null"`       // 订单号 YYYYMMDD999999
-	DeliveryAddress string    `json:"delivery_address" gorm:"size:255;not null"`          // 送货地
-	CarNumber       string    `json:"car_number" gorm:"size:50;not null"`                 // 车号
-	DriverName      string    `json:"driver_name" gorm:"size:50;not null"`                // 司机姓名
-	DriverPhone     string    `json:"driver_phone" gorm:"size:20;not null"`               // 司机手机号
-	TotalAmount     float64   `json:"total_amount" gorm:"type:decimal(15,2);not null"`    // 总金额
-	Status          string    `json:"status" gorm:"size:20;not null;default:'completed'"` // 'completed', 'cancelled'
-	Notes           string    `json:"notes" gorm:"type:text"`                             // 备注
-	CreatedBy       uint      `json:"created_by" gorm:"not null"`                         // 创建人
-	IsDeleted       int       `json:"is_deleted" gorm:"default:0"`                        // 是否删除
-	CreatedAt       time.Time `json:"created_at"`                                         // 创建时间
-	UpdatedAt       time.Time `json:"updated_at"`                                         // 更新时间
+	ID              uint      `json:"id" gorm:"primaryKey"`                                          // 主键
+	OrderNo         string    `json:"order_no" gorm:"size:50;uniqueIndex;not null"`                  // 订单号 YYYYMMDD999999
+	WarehouseID     uint      `json:"warehouse_id" gorm:"index;not null;default:0"`                  // 出库仓库ID
+	CustomerID      *uint     `json:"customer_id" gorm:"index"`                                      // 客户ID
+	CustomerName    string    `json:"customer_name" gorm:"size:100;not null;default:''"`             // 客户名称 (下单时快照)
+	DeliveryAddress string    `json:"delivery_address" gorm:"size:255;not null"`                     // 送货地
+	CarNumber       string    `json:"car_number" gorm:"size:50;not null"`                            // 车号
+	DriverName      string    `json:"driver_name" gorm:"size:50;not null"`                           // 司机姓名
+	DriverPhone     string    `json:"driver_phone" gorm:"size:20;not null"`                          // 司机手机号
+	VehicleID       *uint     `json:"vehicle_id" gorm:"index"`                                       // 车辆档案ID
+	DriverID        *uint     `json:"driver_id" gorm:"index"`                                        // 司机档案ID
+	TotalAmount     float64   `json:"total_amount" gorm:"type:decimal(15,2);not null"`               // 总金额
+	ReceivedAmount  float64   `json:"received_amount" gorm:"type:decimal(15,2);not null;default:0"`  // 已收金额
+	PaymentStatus   string    `json:"payment_status" gorm:"size:20;not null;default:'unpaid';index"` // 收款状态 unpaid/partial/paid
+	CreditOverride  bool      `json:"credit_override" gorm:"not null;default:false"`                 // 超过客户信用额度，由超级管理员放行
+	Status          string    `json:"status" gorm:"size:20;not null;default:'completed'"`            // 'draft', 'confirmed', 'completed', 'cancelled'
+	Notes           string    `json:"notes" gorm:"type:text"`                                        // 备注
+	PriceFlagged    bool      `json:"price_flagged" gorm:"not null;default:false;index"`             // 有订单项单价偏离价目表
+	WeighTicketID   *uint     `json:"weigh_ticket_id" gorm:"index"`                                  // 由磅单转入时的磅单ID
+	CreatedBy       uint      `json:"created_by" gorm:"not null"`                                    // 创建人
+	IsDeleted       int       `json:"is_deleted" gorm:"default:0"`                                   // 是否删除
+	CreatedAt       time.Time `json:"created_at"`                                                    // 创建时间
+	UpdatedAt       time.Time `json:"updated_at"`                                                    // 更新时间
 }
 
 // TableName sets the insert table name for this struct type
@@ -102,9 +141,10 @@ type OutboundOrderItem struct {
 	ID         uint      `json:"id" gorm:"primaryKey"`
 	OrderID    uint      `json:"order_id" gorm:"not null"`
 	CategoryID uint      `json:"category_id" gorm:"not null"`
-	Weight     float64   `json:"weight" gorm:"type:decimal(10,3);not null"`     // kg
-	UnitPrice  float64   `json:"unit_price" gorm:"type:decimal(10,2);not null"` // Price per kg
-	SubTotal   float64   `json:"sub_total" gorm:"type:decimal(15,2);not null"`  // Weight * unit price
+	Weight     float64   `json:"weight" gorm:"type:decimal(10,3);not null"`               // kg
+	UnitPrice  float64   `json:"unit_price" gorm:"type:decimal(10,2);not null"`           // Price per kg
+	ListPrice  float64   `json:"list_price" gorm:"type:decimal(10,2);not null;default:0"` // 下单时的价目表销售价，0 表示无价目
+	SubTotal   float64   `json:"sub_total" gorm:"type:decimal(15,2);not null"`            // Weight * unit price
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
 }
@@ -114,11 +154,14 @@ func (OutboundOrderItem) TableName() string {
 	return "outbound_order_items"
 }
 
-// Inventory represents current inventory for each battery category
+// Inventory represents current inventory of one battery category in one warehouse
 type Inventory struct {
 	ID              uint       `json:"id" gorm:"primaryKey"`
-	CategoryID      uint       `json:"category_id" gorm:"uniqueIndex;not null"`
+	WarehouseID     uint       `json:"warehouse_id" gorm:"uniqueIndex:idx_inventory_warehouse_category;not null;default:0"`
+	CategoryID      uint       `json:"category_id" gorm:"uniqueIndex:idx_inventory_warehouse_category;not null"`
 	CurrentWeightKg float64    `json:"current_weight_kg" gorm:"type:decimal(12,3);not null;default:0"`
+	AvgUnitCost     float64    `json:"avg_unit_cost" gorm:"type:decimal(12,4);not null;default:0"` // 平均单位成本 元/kg
+	TotalValue      float64    `json:"total_value" gorm:"type:decimal(14,2);not null;default:0"`   // 库存金额
 	LastInboundAt   *time.Time `json:"last_inbound_at"`
 	LastOutboundAt  *time.Time `json:"last_outbound_at"`
 	CreatedAt       time.Time  `json:"created_at"`
@@ -154,6 +197,8 @@ type ReportSummary struct {
 	InventoryDetails     []InventoryDetail `json:"inventory_details"`
 	InboundStats         *OrderStats       `json:"inbound_stats,omitempty"`
 	OutboundStats        *OrderStats       `json:"outbound_stats,omitempty"`
+	InboundByCategory    []CategoryStats   `json:"inbound_by_category,omitempty"`
+	OutboundByCategory   []CategoryStats   `json:"outbound_by_category,omitempty"`
 	ReportGeneratedAt    time.Time         `json:"report_generated_at"`
 	DateRange            *DateRange        `json:"date_range,omitempty"`
 }
@@ -175,6 +220,16 @@ type OrderStats struct {
 	AvgAmount   float64 `json:"avg_amount"`
 }
 
+// CategoryStats represents order statistics of one battery category
+type CategoryStats struct {
+	CategoryID   uint    `json:"category_id"`
+	CategoryName string  `json:"category_name"`
+	OrderCount   int64   `json:"order_count"`
+	TotalWeight  float64 `json:"total_weight"`
+	TotalAmount  float64 `json:"total_amount"`
+	AvgUnitPrice float64 `json:"avg_unit_price"` // 加权平均单价 = 金额 / 重量
+}
+
 // DateRange represents date range for reports
 type DateRange struct {
 	StartDate string `json:"start_date"`
@@ -183,36 +238,68 @@ type DateRange struct {
 
 // CreateInboundOrderRequest represents request to create inbound order
 type CreateInboundOrderRequest struct {
-	SupplierName string                   `json:"supplier_name" binding:"required"`
+	WarehouseID  uint                     `json:"warehouse_id" binding:"required"`
+	SellerID     *uint                    `json:"seller_id"` // 指定卖家时供应商名称取卖家名称
+	SupplierName string                   `json:"supplier_name" binding:"required_without=SellerID"`
+	Status       string                   `json:"status" binding:"omitempty,oneof=draft confirmed completed"` // 默认 completed
 	Notes        string                   `json:"notes"`
 	Items        []CreateInboundOrderItem `json:"items" binding:"required,dive"`
+	// WeighTicketID 由磅单转入时关联的磅单，仅供内部使用
+	WeighTicketID *uint `json:"-"`
 }
 
 // CreateInboundOrderItem represents item in create inbound order request
 type CreateInboundOrderItem struct {
 	CategoryID  uint    `json:"category_id" binding:"required"`
 	GrossWeight float64 `json:"gross_weight" binding:"required,gt=0"`
-	TareWeight  float64 `json:"tare_weight"`
-	UnitPrice   float64 `json:"unit_price" binding:"required,gt=0"`
+	TareWeight  float64 `json:"tare_weight" binding:"gte=0,ltfield=GrossWeight"`
+	UnitPrice   float64 `json:"unit_price" binding:"omitempty,gt=0"` // 为空时取下单时有效的收购价
+	Grade       string  `json:"grade" binding:"max=20"`              // 质量等级
+	InboundDeductions
+	// TraceCodes 逐件采集的电池溯源编码 (可选)
+	TraceCodes []string `json:"trace_codes"`
+	// GrossFromScale/TareFromScale 磅单转入时沿用磅单的地磅采集标记，仅供内部使用
+	GrossFromScale bool `json:"-"`
+	TareFromScale  bool `json:"-"`
 }
 
 // CreateOutboundOrderRequest represents request to create outbound order
 type CreateOutboundOrderRequest struct {
-	DeliveryAddress string                    `json:"delivery_address" binding:"required"`
-	CarNumber       string                    `json:"car_number" binding:"required"`
-	DriverName      string                    `json:"driver_name" binding:"required"`
-	DriverPhone     string                    `json:"driver_phone" binding:"required"`
+	WarehouseID     uint                      `json:"warehouse_id" binding:"required"`
+	CustomerID      *uint                     `json:"customer_id"`
+	DeliveryAddress string                    `json:"delivery_address" binding:"required_without=CustomerID"` // 为空时取客户默认地址
+	VehicleID       *uint                     `json:"vehicle_id"`                                             // 指定车辆档案时车号取自档案
+	CarNumber       string                    `json:"car_number" binding:"required_without=VehicleID"`
+	DriverID        *uint                     `json:"driver_id"` // 指定司机档案时姓名和手机号取自档案
+	DriverName      string                    `json:"driver_name"`
+	DriverPhone     string                    `json:"driver_phone"`
+	Status          string                    `json:"status" binding:"omitempty,oneof=draft confirmed completed"` // 默认 completed
 	Notes           string                    `json:"notes"`
+	CreditOverride  bool                      `json:"credit_override"` // 超过客户信用额度时放行，仅超级管理员可用
 	Items           []CreateOutboundOrderItem `json:"items" binding:"required,dive"`
+	// WeighTicketID 由磅单转入时关联的磅单，仅供内部使用
+	WeighTicketID *uint `json:"-"`
 }
 
 // CreateOutboundOrderItem represents item in create outbound order request
 type CreateOutboundOrderItem struct {
 	CategoryID uint    `json:"category_id" binding:"required"`
 	Weight     float64 `json:"weight" binding:"required,gt=0"`
-	UnitPrice  float64 `json:"unit_price" binding:"required,gt=0"`
+	UnitPrice  float64 `json:"unit_price" binding:"omitempty,gt=0"` // 为空时取下单时有效的销售价
+	// Lots 手工指定出库批次，为空时按先进先出自动分配
+	Lots []LotSelection `json:"lots" binding:"omitempty,dive"`
+	// TraceCodes 逐件采集的电池溯源编码 (可选)，须为已入库的编码
+	TraceCodes []string `json:"trace_codes"`
 }
 
+// 订单状态: draft → confirmed → completed，任一未取消状态均可 → cancelled
+const (
+	OrderStatusDraft     = "draft"     // 草稿，不影响库存
+	OrderStatusConfirmed = "confirmed" // 已确认，已记入库存
+	OrderStatusCompleted = "completed" // 已完成
+	OrderStatusCancelled = "cancelled" // 已取消，库存已冲回
+)
+
 // Business error codes
 const (
 	// Success codes
@@ -237,13 +324,23 @@ type UpdateCategoryRequest struct {
 	Name        string  `json:"name"`
 	Description string  `json:"description"`
 	UnitPrice   float64 `json:"unit_price"`
+	// CostingMethod 切换计价方法，切换为 fifo 时以当前结存和平均成本建立期初成本层
+	CostingMethod string `json:"costing_method" binding:"omitempty,oneof=moving_average fifo"`
+	WasteCode     string `json:"waste_code"`
 }
 type GetInboundOrderRequest struct {
-	Page      int    `json:"page" form:"page" binding:"omitempty,min=1"`
-	PageSize  int    `json:"page_size" form:"page_size" binding:"omitempty,min=1,max=100"`
-	StartDate string `json:"start_date" form:"start_date"`
-	EndDate   string `json:"end_date" form:"end_date"`
-	Supplier  string `json:"supplier" form:"supplier"`
+	Page        int    `json:"page" form:"page" binding:"omitempty,min=1"`
+	PageSize    int    `json:"page_size" form:"page_size" binding:"omitempty,min=1,max=100"`
+	StartDate   string `json:"start_date" form:"start_date"`
+	EndDate     string `json:"end_date" form:"end_date"`
+	Supplier    string `json:"supplier" form:"supplier"`
+	SellerID    uint   `json:"seller_id" form:"seller_id"`
+	Status      string `json:"status" form:"status"`
+	WarehouseID uint   `json:"warehouse_id" form:"warehouse_id"`
+	// PriceFlagged 仅查询单价偏离价目表的订单
+	PriceFlagged bool `json:"price_flagged" form:"price_flagged"`
+	// PaymentStatus 按付款状态筛选 (unpaid/partial/paid)
+	PaymentStatus string `json:"payment_status" form:"payment_status"`
 }
 
 type GetInboundOrderResponse struct {
@@ -251,14 +348,45 @@ type GetInboundOrderResponse struct {
 	Total  int64          `json:"total"`
 }
 
+// UpdateInboundOrderRequest represents request to update inbound order
+type UpdateInboundOrderRequest struct {
+	SellerID     *uint                    `json:"seller_id"`
+	SupplierName string                   `json:"supplier_name"`
+	Status       string                   `json:"status"`
+	Notes        string                   `json:"notes"`
+	Items        []UpdateInboundOrderItem `json:"items,omitempty" binding:"omitempty,dive"`
+}
+
+// UpdateInboundOrderItem represents item in update inbound order request
+type UpdateInboundOrderItem struct {
+	ID          uint    `json:"id,omitempty"` // 如果有ID则是更新，没有则是新增
+	CategoryID  uint    `json:"category_id"`
+	GrossWeight float64 `json:"gross_weight"`
+	TareWeight  float64 `json:"tare_weight"`
+	UnitPrice   float64 `json:"unit_price"` // 为空时取下单时有效的收购价
+	Grade       string  `json:"grade"`
+	InboundDeductions
+	Action string `json:"action,omitempty" binding:"omitempty,oneof=add update delete"` // "add", "update", "delete"
+	// TraceCodes 替换订单项的溯源编码，为 null 时保持不变
+	TraceCodes []string `json:"trace_codes"`
+}
+
 type InboundOrderDetailDTO struct {
+	ID           uint    `json:"id"`
 	CategoryID   uint    `json:"category_id"`
 	CategoryName string  `json:"category_name"`
 	GrossWeight  float64 `json:"gross_weight"`
 	TareWeight   float64 `json:"tare_weight"`
 	NetWeight    float64 `json:"net_weight"`
-	UnitPrice    float64 `json:"unit_price"`
-	SubTotal     float64 `json:"sub_total"`
+	InboundDeductions
+	GrossFromScale   bool    `json:"gross_from_scale"`
+	TareFromScale    bool    `json:"tare_from_scale"`
+	DeductionWeight  float64 `json:"deduction_weight"`
+	SettlementWeight float64 `json:"settlement_weight"`
+	UnitPrice        float64 `json:"unit_price"`
+	ListPrice        float64 `json:"list_price"`
+	SubTotal         float64 `json:"sub_total"`
+	Grade            string  `json:"grade"`
 }
 
 type GetInboudOrderDetailResp struct {
@@ -272,20 +400,29 @@ type OutboundOrderDetailDTO struct {
 	CategoryName string  `json:"category_name"`
 	Weight       float64 `json:"weight"`
 	UnitPrice    float64 `json:"unit_price"`
+	ListPrice    float64 `json:"list_price"`
 	SubTotal     float64 `json:"sub_total"`
 }
 
 type GetOutboundOrderDetailResp struct {
-	Order  OutboundOrder            `json:"order"`
-	Detail []OutboundOrderDetailDTO `json:"detail"`
+	Order    OutboundOrder            `json:"order"`
+	Customer *Customer                `json:"customer,omitempty"`
+	Detail   []OutboundOrderDetailDTO `json:"detail"`
 }
 
 type GetOutboundOrderRequest struct {
-	Page      int    `json:"page" form:"page" binding:"omitempty,min=1"`
-	PageSize  int    `json:"page_size" form:"page_size" binding:"omitempty,min=1,max=100"`
-	StartDate string `json:"start_date" form:"start_date"`
-	EndDate   string `json:"end_date" form:"end_date"`
-	Customer  string `json:"customer" form:"customer"`
+	Page        int    `json:"page" form:"page" binding:"omitempty,min=1"`
+	PageSize    int    `json:"page_size" form:"page_size" binding:"omitempty,min=1,max=100"`
+	StartDate   string `json:"start_date" form:"start_date"`
+	EndDate     string `json:"end_date" form:"end_date"`
+	Customer    string `json:"customer" form:"customer"`
+	CustomerID  uint   `json:"customer_id" form:"customer_id"`
+	Status      string `json:"status" form:"status"`
+	WarehouseID uint   `json:"warehouse_id" form:"warehouse_id"`
+	// PriceFlagged 仅查询单价偏离价目表的订单
+	PriceFlagged bool `json:"price_flagged" form:"price_flagged"`
+	// PaymentStatus 按收款状态筛选 (unpaid/partial/paid)
+	PaymentStatus string `json:"payment_status" form:"payment_status"`
 }
 
 type GetOutboundOrderResponse struct {
@@ -295,20 +432,80 @@ type GetOutboundOrderResponse struct {
 
 // UpdateOutboundOrderRequest represents request to update outbound order
 type UpdateOutboundOrderRequest struct {
+	CustomerID      *uint                     `json:"customer_id"`
 	DeliveryAddress string                    `json:"delivery_address"`
+	VehicleID       *uint                     `json:"vehicle_id"`
 	CarNumber       string                    `json:"car_number"`
+	DriverID        *uint                     `json:"driver_id"`
 	DriverName      string                    `json:"driver_name"`
 	DriverPhone     string                    `json:"driver_phone"`
 	Status          string                    `json:"status"`
 	Notes           string                    `json:"notes"`
-	Items           []UpdateOutboundOrderItem `json:"items,omitempty"`
+	Items           []UpdateOutboundOrderItem `json:"items,omitempty" binding:"omitempty,dive"` // 提供时整体替换订单项
+	CreditOverride  bool                      `json:"credit_override"`                          // 修改或确认后超过客户信用额度时放行，仅超级管理员可用
 }
 
 // UpdateOutboundOrderItem represents item in update outbound order request
 type UpdateOutboundOrderItem struct {
 	ID         uint    `json:"id,omitempty"` // 如果有ID则是更新，没有则是新增
-	CategoryID uint    `json:"category_id" binding:"required"`
-	Weight     float64 `json:"weight" binding:"required,gt=0"`
-	UnitPrice  float64 `json:"unit_price" binding:"required,gt=0"`
-	Action     string  `json:"action,omitempty"` // "add", "update", "delete"
+	CategoryID uint    `json:"category_id" binding:"required_unless=Action delete"`
+	Weight     float64 `json:"weight" binding:"required_unless=Action delete,omitempty,gt=0"`
+	UnitPrice  float64 `json:"unit_price" binding:"omitempty,gt=0"`                          // 为空时取下单时有效的销售价
+	Action     string  `json:"action,omitempty" binding:"omitempty,oneof=add update delete"` // "add", "update", "delete"；delete 的订单项不再保留
+	// Lots 手工指定出库批次，为空时按先进先出自动分配
+	Lots []LotSelection `json:"lots" binding:"omitempty,dive"`
+	// TraceCodes 逐件采集的电池溯源编码 (可选)，须为已入库的编码
+	TraceCodes []string `json:"trace_codes"`
+}
+
+// TrendReport represents bucketed purchase/sales series per battery category
+type TrendReport struct {
+	Granularity string        `json:"granularity"` // day, week, month
+	DateRange   DateRange     `json:"date_range"`
+	Periods     []string      `json:"periods"` // 时间桶: 2006-01-02 / 2006-W01 / 2006-01
+	Series      []TrendSeries `json:"series"`
+}
+
+// TrendSeries represents one category's series, points aligned with TrendReport.Periods
+type TrendSeries struct {
+	CategoryID   uint         `json:"category_id"`
+	CategoryName string       `json:"category_name"`
+	Points       []TrendPoint `json:"points"`
+}
+
+// TrendPoint represents purchase and sales totals of one period
+type TrendPoint struct {
+	Period         string  `json:"period"`
+	InboundWeight  float64 `json:"inbound_weight"`  // 入库净重 kg
+	OutboundWeight float64 `json:"outbound_weight"` // 出库重量 kg
+	PurchaseAmount float64 `json:"purchase_amount"` // 采购金额
+	SalesAmount    float64 `json:"sales_amount"`    // 销售金额
+}
+
+// TrendRow represents one aggregated (period, category) row of trend query
+type TrendRow struct {
+	Period       string
+	CategoryID   uint
+	CategoryName string
+	Weight       float64
+	Amount       float64
+}
+
+// MarginReport represents gross margin per battery category for a period
+type MarginReport struct {
+	DateRange  DateRange        `json:"date_range"`
+	Categories []CategoryMargin `json:"categories"`
+	Total      CategoryMargin   `json:"total"` // 合计 (不含分类信息和平均成本)
+}
+
+// CategoryMargin represents revenue, cost of goods sold and gross profit of one category
+type CategoryMargin struct {
+	CategoryID   uint    `json:"category_id,omitempty"`
+	CategoryName string  `json:"category_name,omitempty"`
+	SalesWeight  float64 `json:"sales_weight"`  // 出库重量 kg
+	Revenue      float64 `json:"revenue"`       // 销售收入
+	AvgUnitCost  float64 `json:"avg_unit_cost"` // 平均单位成本 = 销售成本 / 出库重量 (元/kg)
+	COGS         float64 `json:"cogs"`          // 销售成本，取出库流水记录的实际成本，无成本记录的部分按全公司加权平均采购成本
+	GrossProfit  float64 `json:"gross_profit"`  // 毛利 = 收入 - 销售成本
+	MarginPct    float64 `json:"margin_pct"`    // 毛利率 % = 毛利 / 收入 × 100
 }
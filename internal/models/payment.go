@@ -0,0 +1,145 @@
+package models
+
+import "time"
+
+// 付款方式
+const (
+	PaymentMethodCash         = "cash"          // 现金
+	PaymentMethodBankTransfer = "bank_transfer" // 银行转账
+	PaymentMethodMobilePay    = "mobile_pay"    // 微信/支付宝等移动支付
+)
+
+// 付款单状态
+const (
+	PaymentStatusPosted = "posted" // 已入账
+	PaymentStatusVoided = "voided" // 已作废，分摊金额已冲回
+)
+
+// 订单付款状态 (入库订单为付款，出库订单为收款)
+const (
+	OrderPaymentUnpaid  = "unpaid"  // 未付款
+	OrderPaymentPartial = "partial" // 部分付款
+	OrderPaymentPaid    = "paid"    // 已付清
+)
+
+// SupplierPayment 供应商付款单，一笔付款可分摊到同一供应商的多张入库订单
+type SupplierPayment struct {
+	ID           uint                        `json:"id" gorm:"primaryKey"`
+	PaymentNo    string                      `json:"payment_no" gorm:"uniqueIndex;size:50;not null"` // 付款单号
+	SellerID     *uint                       `json:"seller_id" gorm:"index"`                         // 卖家(供应商)ID
+	SupplierName string                      `json:"supplier_name" gorm:"size:100;not null;index"`   // 供应商名称 (取自订单)
+	Amount       float64                     `json:"amount" gorm:"type:decimal(15,2);not null"`      // 付款金额 = 各订单分摊金额合计
+	Method       string                      `json:"method" gorm:"size:20;not null"`                 // 付款方式
+	PaidAt       time.Time                   `json:"paid_at" gorm:"not null;index"`                  // 付款日期
+	Reference    string                      `json:"reference" gorm:"size:100"`                      // 银行流水号/交易单号
+	Status       string                      `json:"status" gorm:"size:20;not null;index"`           // 状态
+	Notes        string                      `json:"notes" gorm:"type:text"`                         // 备注
+	Allocations  []SupplierPaymentAllocation `json:"allocations,omitempty" gorm:"-"`
+	CreatedBy    uint                        `json:"created_by" gorm:"not null"` // 创建人
+	VoidedBy     *uint                       `json:"voided_by"`                  // 作废人
+	VoidedAt     *time.Time                  `json:"voided_at"`                  // 作废时间
+	CreatedAt    time.Time                   `json:"created_at"`
+	UpdatedAt    time.Time                   `json:"updated_at"`
+}
+
+// TableName sets the insert table name for this struct type
+func (SupplierPayment) TableName() string {
+	return "supplier_payments"
+}
+
+// SupplierPaymentAllocation 付款单分摊到入库订单的金额
+type SupplierPaymentAllocation struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	PaymentID      uint      `json:"payment_id" gorm:"index;not null"`
+	InboundOrderID uint      `json:"inbound_order_id" gorm:"index;not null"`
+	OrderNo        string    `json:"order_no" gorm:"size:50;not null"`          // 订单号快照
+	Amount         float64   `json:"amount" gorm:"type:decimal(15,2);not null"` // 分摊金额
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TableName sets the insert table name for this struct type
+func (SupplierPaymentAllocation) TableName() string {
+	return "supplier_payment_allocations"
+}
+
+// PaymentAllocationInput 付款分摊到单张订单的金额
+type PaymentAllocationInput struct {
+	OrderID uint    `json:"order_id" binding:"required"`
+	Amount  float64 `json:"amount" binding:"required,gt=0"`
+}
+
+// CreateSupplierPaymentRequest 登记供应商付款，分摊的订单须属于同一供应商
+type CreateSupplierPaymentRequest struct {
+	Method      string                   `json:"method" binding:"required,oneof=cash bank_transfer mobile_pay"`
+	PaidAt      string                   `json:"paid_at"` // 付款日期 YYYY-MM-DD，默认当天
+	Reference   string                   `json:"reference"`
+	Notes       string                   `json:"notes"`
+	Allocations []PaymentAllocationInput `json:"allocations" binding:"required,min=1,dive"`
+}
+
+// GetSupplierPaymentsRequest 查询供应商付款单请求
+type GetSupplierPaymentsRequest struct {
+	Page      int    `json:"page" form:"page" binding:"omitempty,min=1"`
+	PageSize  int    `json:"page_size" form:"page_size" binding:"omitempty,min=1,max=100"`
+	SellerID  uint   `json:"seller_id" form:"seller_id"`
+	Supplier  string `json:"supplier" form:"supplier"`
+	Method    string `json:"method" form:"method"`
+	Status    string `json:"status" form:"status"`
+	StartDate string `json:"start_date" form:"start_date"` // 按付款日期筛选
+	EndDate   string `json:"end_date" form:"end_date"`
+}
+
+type GetSupplierPaymentsResponse struct {
+	Payments []SupplierPayment `json:"payments"`
+	Total    int64             `json:"total"`
+}
+
+// SupplierBalance 卖家的应付账款余额及未付清订单
+type SupplierBalance struct {
+	Seller        Seller         `json:"seller"`
+	TotalPayable  float64        `json:"total_payable"` // 已记账订单金额合计
+	TotalPaid     float64        `json:"total_paid"`    // 已付金额合计
+	Outstanding   float64        `json:"outstanding"`   // 未付金额
+	UnpaidOrders  []InboundOrder `json:"unpaid_orders"` // 未付清的已记账订单
+	LastPaymentAt *time.Time     `json:"last_payment_at"`
+}
+
+// GetAgingRequest 账龄报表请求
+type GetAgingRequest struct {
+	AsOf string `json:"as_of" form:"as_of"` // 截止日期 YYYY-MM-DD，默认当天
+}
+
+// OpenOrderBalance 截止日期的单张订单未结金额，账龄按订单创建日期计算
+type OpenOrderBalance struct {
+	OrderID     uint      `json:"order_id"`
+	OrderNo     string    `json:"order_no"`
+	PartyID     *uint     `json:"party_id"`
+	PartyName   string    `json:"party_name"`
+	CreatedAt   time.Time `json:"created_at"`
+	TotalAmount float64   `json:"total_amount"`
+	Outstanding float64   `json:"outstanding"`
+}
+
+// AgingBuckets 按账龄分段的未结金额
+type AgingBuckets struct {
+	Days0To30   float64 `json:"days_0_30"`
+	Days31To60  float64 `json:"days_31_60"`
+	Days61To90  float64 `json:"days_61_90"`
+	DaysOver90  float64 `json:"days_over_90"`
+	Outstanding float64 `json:"outstanding"` // 合计
+}
+
+// SupplierAging 单个供应商的应付账龄
+type SupplierAging struct {
+	SellerID     *uint  `json:"seller_id"`
+	SupplierName string `json:"supplier_name"`
+	OrderCount   int    `json:"order_count"`
+	AgingBuckets
+}
+
+// PayablesAgingReport 应付账款账龄报表
+type PayablesAgingReport struct {
+	AsOf      string          `json:"as_of"`
+	Suppliers []SupplierAging `json:"suppliers"`
+	Total     AgingBuckets    `json:"total"`
+}
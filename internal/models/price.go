@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// 价格类型
+const (
+	PriceTypeBuy  = "buy"  // 收购价，入库订单默认单价
+	PriceTypeSell = "sell" // 销售价，出库订单默认单价
+)
+
+// CategoryPrice 分类价格历史，每条记录自生效时间起有效，直到同类型更晚的价格生效
+type CategoryPrice struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	CategoryID    uint      `json:"category_id" gorm:"not null;index:idx_category_prices_lookup,priority:1"`
+	PriceType     string    `json:"price_type" gorm:"size:10;not null;index:idx_category_prices_lookup,priority:2"` // buy, sell
+	UnitPrice     float64   `json:"unit_price" gorm:"type:decimal(10,2);not null"`                                  // 每公斤单价
+	EffectiveFrom time.Time `json:"effective_from" gorm:"not null;index:idx_category_prices_lookup,priority:3"`     // 生效时间
+	Notes         string    `json:"notes" gorm:"size:255"`
+	CreatedBy     uint      `json:"created_by" gorm:"not null"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TableName sets the insert table name for this struct type
+func (CategoryPrice) TableName() string {
+	return "category_prices"
+}
+
+// CreateCategoryPriceRequest 新增分类价格请求
+type CreateCategoryPriceRequest struct {
+	PriceType string  `json:"price_type" binding:"required,oneof=buy sell"`
+	UnitPrice float64 `json:"unit_price" binding:"required,gt=0"`
+	// EffectiveFrom 生效时间 (YYYY-MM-DD 或 RFC3339)，为空时立即生效
+	EffectiveFrom string `json:"effective_from"`
+	Notes         string `json:"notes"`
+}
+
+// CategoryPriceHistory 分类当前价格及价格历史
+type CategoryPriceHistory struct {
+	CategoryID   uint            `json:"category_id"`
+	CategoryName string          `json:"category_name"`
+	BuyPrice     *float64        `json:"buy_price"`  // 当前收购价，未设置时为 null
+	SellPrice    *float64        `json:"sell_price"` // 当前销售价，未设置时为 null
+	Prices       []CategoryPrice `json:"prices"`     // 按生效时间倒序
+}
+
+// PriceListEntry 价目表中单个分类在指定时间的有效价格
+type PriceListEntry struct {
+	CategoryID        uint       `json:"category_id"`
+	CategoryName      string     `json:"category_name"`
+	BuyPrice          *float64   `json:"buy_price"`
+	BuyEffectiveFrom  *time.Time `json:"buy_effective_from"`
+	SellPrice         *float64   `json:"sell_price"`
+	SellEffectiveFrom *time.Time `json:"sell_effective_from"`
+}
+
+// PriceList 指定时间的价目表
+type PriceList struct {
+	AsOf    time.Time        `json:"as_of"`
+	Entries []PriceListEntry `json:"entries"`
+}
@@ -0,0 +1,97 @@
+package models
+
+import "time"
+
+// CustomerReceipt 客户收款单，一笔收款可核销同一客户的多张出库订单
+// 收款方式和状态与供应商付款单相同
+type CustomerReceipt struct {
+	ID           uint                        `json:"id" gorm:"primaryKey"`
+	ReceiptNo    string                      `json:"receipt_no" gorm:"uniqueIndex;size:50;not null"` // 收款单号
+	CustomerID   *uint                       `json:"customer_id" gorm:"index"`                       // 客户ID
+	CustomerName string                      `json:"customer_name" gorm:"size:100;not null;index"`   // 客户名称 (取自订单)
+	Amount       float64                     `json:"amount" gorm:"type:decimal(15,2);not null"`      // 收款金额 = 各订单核销金额合计
+	Method       string                      `json:"method" gorm:"size:20;not null"`                 // 收款方式
+	ReceivedAt   time.Time                   `json:"received_at" gorm:"not null;index"`              // 收款日期
+	Reference    string                      `json:"reference" gorm:"size:100"`                      // 银行流水号/交易单号
+	Status       string                      `json:"status" gorm:"size:20;not null;index"`           // 状态
+	Notes        string                      `json:"notes" gorm:"type:text"`                         // 备注
+	Allocations  []CustomerReceiptAllocation `json:"allocations,omitempty" gorm:"-"`
+	CreatedBy    uint                        `json:"created_by" gorm:"not null"` // 创建人
+	VoidedBy     *uint                       `json:"voided_by"`                  // 作废人
+	VoidedAt     *time.Time                  `json:"voided_at"`                  // 作废时间
+	CreatedAt    time.Time                   `json:"created_at"`
+	UpdatedAt    time.Time                   `json:"updated_at"`
+}
+
+// TableName sets the insert table name for this struct type
+func (CustomerReceipt) TableName() string {
+	return "customer_receipts"
+}
+
+// CustomerReceiptAllocation 收款单核销到出库订单的金额
+type CustomerReceiptAllocation struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	ReceiptID       uint      `json:"receipt_id" gorm:"index;not null"`
+	OutboundOrderID uint      `json:"outbound_order_id" gorm:"index;not null"`
+	OrderNo         string    `json:"order_no" gorm:"size:50;not null"`          // 订单号快照
+	Amount          float64   `json:"amount" gorm:"type:decimal(15,2);not null"` // 核销金额
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// TableName sets the insert table name for this struct type
+func (CustomerReceiptAllocation) TableName() string {
+	return "customer_receipt_allocations"
+}
+
+// CreateCustomerReceiptRequest 登记客户收款，核销的订单须属于同一客户
+type CreateCustomerReceiptRequest struct {
+	Method      string                   `json:"method" binding:"required,oneof=cash bank_transfer mobile_pay"`
+	ReceivedAt  string                   `json:"received_at"` // 收款日期 YYYY-MM-DD，默认当天
+	Reference   string                   `json:"reference"`
+	Notes       string                   `json:"notes"`
+	Allocations []PaymentAllocationInput `json:"allocations" binding:"required,min=1,dive"`
+}
+
+// GetCustomerReceiptsRequest 查询客户收款单请求
+type GetCustomerReceiptsRequest struct {
+	Page       int    `json:"page" form:"page" binding:"omitempty,min=1"`
+	PageSize   int    `json:"page_size" form:"page_size" binding:"omitempty,min=1,max=100"`
+	CustomerID uint   `json:"customer_id" form:"customer_id"`
+	Customer   string `json:"customer" form:"customer"`
+	Method     string `json:"method" form:"method"`
+	Status     string `json:"status" form:"status"`
+	StartDate  string `json:"start_date" form:"start_date"` // 按收款日期筛选
+	EndDate    string `json:"end_date" form:"end_date"`
+}
+
+type GetCustomerReceiptsResponse struct {
+	Receipts []CustomerReceipt `json:"receipts"`
+	Total    int64             `json:"total"`
+}
+
+// CustomerBalance 客户的应收账款余额、信用额度及未收清订单
+type CustomerBalance struct {
+	Customer        Customer        `json:"customer"`
+	TotalReceivable float64         `json:"total_receivable"` // 已记账订单金额合计
+	TotalReceived   float64         `json:"total_received"`   // 已收金额合计
+	Outstanding     float64         `json:"outstanding"`      // 未收金额
+	CreditLimit     float64         `json:"credit_limit"`     // 信用额度，0 表示不限
+	AvailableCredit *float64        `json:"available_credit"` // 剩余额度，不限额度时为 null
+	UnpaidOrders    []OutboundOrder `json:"unpaid_orders"`    // 未收清的已记账订单
+	LastReceiptAt   *time.Time      `json:"last_receipt_at"`
+}
+
+// CustomerAging 单个客户的应收账龄
+type CustomerAging struct {
+	CustomerID   *uint  `json:"customer_id"`
+	CustomerName string `json:"customer_name"`
+	OrderCount   int    `json:"order_count"`
+	AgingBuckets
+}
+
+// ReceivablesAgingReport 应收账款账龄报表
+type ReceivablesAgingReport struct {
+	AsOf      string          `json:"as_of"`
+	Customers []CustomerAging `json:"customers"`
+	Total     AgingBuckets    `json:"total"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// 地磅采集的重量字段
+const (
+	ScaleFieldGross = "gross"
+	ScaleFieldTare  = "tare"
+)
+
+// ScaleReading 地磅当前读数
+type ScaleReading struct {
+	Weight     float64   `json:"weight"`      // 重量 kg
+	Stable     bool      `json:"stable"`      // 读数已稳定，可以采集
+	Motion     bool      `json:"motion"`      // 仪表报告秤台晃动
+	ReceivedAt time.Time `json:"received_at"` // 收到该读数的时间
+}
+
+// ScaleCapture 地磅采集记录，每次把读数写入入库订单项时记录一条
+type ScaleCapture struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	OrderID    uint      `json:"order_id" gorm:"index;not null"`            // 入库订单ID
+	ItemID     uint      `json:"item_id" gorm:"index;not null"`             // 入库订单项ID
+	Field      string    `json:"field" gorm:"size:10;not null"`             // gross 或 tare
+	Weight     float64   `json:"weight" gorm:"type:decimal(10,3);not null"` // 采集的重量 kg
+	CapturedBy uint      `json:"captured_by" gorm:"not null"`               // 操作人
+	CapturedAt time.Time `json:"captured_at" gorm:"not null"`               // 仪表读数时间
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName sets the insert table name for this struct type
+func (ScaleCapture) TableName() string {
+	return "scale_captures"
+}
+
+// ScaleCaptureRequest 把地磅当前稳定读数写入入库订单项的毛重或皮重
+type ScaleCaptureRequest struct {
+	ItemID uint   `json:"item_id" binding:"required"`
+	Field  string `json:"field" binding:"required,oneof=gross tare"`
+}
+
+// ScaleCaptureResponse 采集结果
+type ScaleCaptureResponse struct {
+	Reading ScaleReading     `json:"reading"`
+	Item    InboundOrderItem `json:"item"`
+}
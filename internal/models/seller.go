@@ -14,3 +14,37 @@ type Seller struct {
 func (Seller) TableName() string {
 	return "sellers"
 }
+
+// CreateSellerRequest represents request to create seller
+type CreateSellerRequest struct {
+	Name    string `json:"name" binding:"required"`
+	Phone   string `json:"phone"`
+	Address string `json:"address"`
+}
+
+// UpdateSellerRequest represents request to update seller
+type UpdateSellerRequest struct {
+	Name    string `json:"name"`
+	Phone   string `json:"phone"`
+	Address string `json:"address"`
+}
+
+// MatchSuppliersRequest 将历史入库订单的供应商名称匹配到卖家
+type MatchSuppliersRequest struct {
+	CreateMissing bool `json:"create_missing"` // 找不到同名卖家时自动创建
+}
+
+// SupplierMatch 单个供应商名称的匹配结果
+type SupplierMatch struct {
+	SupplierName string `json:"supplier_name"`
+	SellerID     uint   `json:"seller_id"`
+	OrderCount   int64  `json:"order_count"`
+	Created      bool   `json:"created"`
+}
+
+// MatchSuppliersResponse 供应商名称匹配结果
+type MatchSuppliersResponse struct {
+	Matched   []SupplierMatch `json:"matched"`
+	Unmatched []string        `json:"unmatched"` // 没有同名卖家
+	Ambiguous []string        `json:"ambiguous"` // 存在多个同名卖家，需人工处理
+}
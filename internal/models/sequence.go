@@ -0,0 +1,12 @@
+package models
+
+// DocumentSequence 单据流水号，每个名称一行，取号时在事务中加一
+type DocumentSequence struct {
+	Name  string `json:"name" gorm:"primaryKey;size:50"`
+	Value int64  `json:"value" gorm:"not null;default:0"`
+}
+
+// TableName sets the insert table name for this struct type
+func (DocumentSequence) TableName() string {
+	return "document_sequences"
+}
@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+// 盘点单状态
+const (
+	StocktakeStatusOpen      = "open"      // 盘点中，盘点分类禁止出库
+	StocktakeStatusApproved  = "approved"  // 已审核，盘盈盘亏已记入库存
+	StocktakeStatusCancelled = "cancelled" // 已取消
+)
+
+// Stocktake 盘点单
+type Stocktake struct {
+	ID          uint            `json:"id" gorm:"primaryKey"`
+	StocktakeNo string          `json:"stocktake_no" gorm:"uniqueIndex;size:50;not null"`
+	WarehouseID uint            `json:"warehouse_id" gorm:"index;not null;default:0"` // 盘点仓库ID
+	Status      string          `json:"status" gorm:"size:20;not null;default:'open';index"`
+	Notes       string          `json:"notes" gorm:"type:text"`
+	CreatedBy   uint            `json:"created_by" gorm:"not null"`
+	ApprovedBy  *uint           `json:"approved_by"`
+	ApprovedAt  *time.Time      `json:"approved_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	Items       []StocktakeItem `json:"items,omitempty" gorm:"-"`
+}
+
+// TableName sets the insert table name for this struct type
+func (Stocktake) TableName() string {
+	return "stocktakes"
+}
+
+// StocktakeItem 盘点明细，每个盘点分类一条
+type StocktakeItem struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	StocktakeID   uint       `json:"stocktake_id" gorm:"index;not null"`
+	CategoryID    uint       `json:"category_id" gorm:"index;not null"`
+	CategoryName  string     `json:"category_name" gorm:"-"`
+	SystemWeight  float64    `json:"system_weight" gorm:"type:decimal(12,3);not null"`      // 账面重量 kg (开单时快照，录入实盘时刷新)
+	CountedWeight *float64   `json:"counted_weight" gorm:"type:decimal(12,3)"`              // 实盘重量 kg，未录入为 null
+	Variance      float64    `json:"variance" gorm:"type:decimal(12,3);not null;default:0"` // 差异 = 实盘 - 账面
+	Reason        string     `json:"reason" gorm:"size:30"`                                 // 差异原因
+	Notes         string     `json:"notes" gorm:"size:255"`
+	CountedBy     *uint      `json:"counted_by"`
+	CountedAt     *time.Time `json:"counted_at"`
+}
+
+// TableName sets the insert table name for this struct type
+func (StocktakeItem) TableName() string {
+	return "stocktake_items"
+}
+
+// CreateStocktakeRequest 创建盘点单请求，未指定分类时盘点该仓库全部库存分类
+type CreateStocktakeRequest struct {
+	WarehouseID uint   `json:"warehouse_id" binding:"required"`
+	CategoryIDs []uint `json:"category_ids"`
+	Notes       string `json:"notes"`
+}
+
+// RecordStocktakeCountsRequest 录入实盘重量请求
+type RecordStocktakeCountsRequest struct {
+	Items []StocktakeCountInput `json:"items" binding:"required,min=1,dive"`
+}
+
+// StocktakeCountInput 单个分类的实盘重量
+type StocktakeCountInput struct {
+	CategoryID    uint     `json:"category_id" binding:"required"`
+	CountedWeight *float64 `json:"counted_weight" binding:"required,min=0"`
+	Reason        string   `json:"reason" binding:"omitempty,oneof=moisture_loss scale_error damage theft correction"` // 默认 correction
+	Notes         string   `json:"notes"`
+}
+
+// GetStocktakesRequest 查询盘点单请求
+type GetStocktakesRequest struct {
+	Page        int    `json:"page" form:"page" binding:"omitempty,min=1"`
+	PageSize    int    `json:"page_size" form:"page_size" binding:"omitempty,min=1,max=100"`
+	Status      string `json:"status" form:"status"`
+	WarehouseID uint   `json:"warehouse_id" form:"warehouse_id"`
+}
+
+type GetStocktakesResponse struct {
+	Stocktakes []Stocktake `json:"stocktakes"`
+	Total      int64       `json:"total"`
+}
@@ -0,0 +1,89 @@
+package models
+
+import "time"
+
+// 溯源编码状态
+const (
+	TraceCodeStatusInStock   = "in_stock"   // 已入库
+	TraceCodeStatusInTransit = "in_transit" // 调拨在途
+	TraceCodeStatusShipped   = "shipped"    // 已出库
+)
+
+// 溯源编码事件类型
+const (
+	TraceEventReceived         = "received"          // 入库
+	TraceEventShipped          = "shipped"           // 出库
+	TraceEventReceiptReversed  = "receipt_reversed"  // 入库冲回
+	TraceEventShipmentReversed = "shipment_reversed" // 出库退回
+	TraceEventTransferShipped  = "transfer_shipped"  // 调拨发货
+	TraceEventTransferReceived = "transfer_received" // 调拨收货，转入调入仓
+	TraceEventTransferReversed = "transfer_reversed" // 在途调拨取消，退回调出仓
+)
+
+// TraceCodeLength 动力电池溯源编码长度，由数字和大写字母组成
+const TraceCodeLength = 24
+
+// TraceCode 电池溯源编码登记，每个编码同一时间只能在库一次
+type TraceCode struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	Code            string     `json:"code" gorm:"uniqueIndex;size:24;not null"` // 溯源编码
+	CategoryID      uint       `json:"category_id" gorm:"index;not null"`        // 电池类型ID
+	CategoryName    string     `json:"category_name" gorm:"-"`                   // 电池类型名称
+	WarehouseID     uint       `json:"warehouse_id" gorm:"index;not null"`       // 所在仓库ID (入库仓，调拨收货后为调入仓)
+	Status          string     `json:"status" gorm:"size:20;not null;index"`     // in_stock / in_transit / shipped
+	InboundOrderID  uint       `json:"inbound_order_id" gorm:"index;not null"`   // 入库订单ID
+	InboundItemID   uint       `json:"inbound_item_id" gorm:"not null"`          // 入库订单项ID
+	OutboundOrderID *uint      `json:"outbound_order_id" gorm:"index"`           // 出库订单ID
+	OutboundItemID  *uint      `json:"outbound_item_id"`                         // 出库订单项ID
+	ReceivedAt      time.Time  `json:"received_at"`                              // 入库时间
+	ShippedAt       *time.Time `json:"shipped_at"`                               // 出库时间
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// TableName sets the insert table name for this struct type
+func (TraceCode) TableName() string {
+	return "trace_codes"
+}
+
+// TraceCodeEvent 溯源编码事件，记录编码在系统中的全部入库、出库、调拨和冲回
+type TraceCodeEvent struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Code         string    `json:"code" gorm:"size:24;not null;index"`
+	EventType    string    `json:"event_type" gorm:"size:30;not null"`
+	CategoryID   uint      `json:"category_id" gorm:"not null"`
+	WarehouseID  uint      `json:"warehouse_id" gorm:"not null"`
+	SourceType   string    `json:"source_type" gorm:"size:30;not null"` // 来源单据类型，同库存流水
+	SourceID     uint      `json:"source_id" gorm:"not null"`           // 来源单据ID
+	SourceItemID uint      `json:"source_item_id" gorm:"not null"`      // 来源单据明细ID
+	DocumentNo   string    `json:"document_no" gorm:"size:50"`          // 单据号
+	Counterparty string    `json:"counterparty" gorm:"size:100"`        // 供应商/客户名称
+	CreatedBy    uint      `json:"created_by" gorm:"not null"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName sets the insert table name for this struct type
+func (TraceCodeEvent) TableName() string {
+	return "trace_code_events"
+}
+
+// OrderItemTraceCode 入库/出库订单项和调拨明细采集的溯源编码，订单记账或调拨发货时登记
+type OrderItemTraceCode struct {
+	ID           uint   `json:"id" gorm:"primaryKey"`
+	SourceType   string `json:"source_type" gorm:"size:30;not null;index:idx_item_trace_code_source"` // inbound_order / outbound_order / transfer_out
+	SourceID     uint   `json:"source_id" gorm:"not null;index:idx_item_trace_code_source"`           // 订单/调拨单ID
+	SourceItemID uint   `json:"source_item_id" gorm:"not null;index"`                                 // 订单项/调拨明细ID
+	Code         string `json:"code" gorm:"size:24;not null;index"`
+}
+
+// TableName sets the insert table name for this struct type
+func (OrderItemTraceCode) TableName() string {
+	return "order_item_trace_codes"
+}
+
+// TraceCodeHistory 溯源编码查询结果
+type TraceCodeHistory struct {
+	Code    string           `json:"code"`
+	Current *TraceCode       `json:"current"` // 当前登记状态，未入库或入库已冲回时为 null
+	Events  []TraceCodeEvent `json:"events"`
+}
@@ -0,0 +1,113 @@
+package models
+
+import "time"
+
+// 调拨单状态
+const (
+	TransferStatusDraft     = "draft"      // 草稿，未出库
+	TransferStatusInTransit = "in_transit" // 已从调出仓发货，在途
+	TransferStatusReceived  = "received"   // 调入仓已收货
+	TransferStatusCancelled = "cancelled"  // 已取消 (在途取消时退回调出仓)
+)
+
+// TransferOrder 仓库间调拨单
+// 发货时扣减调出仓库存转为在途，收货时按实收重量记入调入仓，差额记为途损
+type TransferOrder struct {
+	ID              uint                `json:"id" gorm:"primaryKey"`
+	TransferNo      string              `json:"transfer_no" gorm:"uniqueIndex;size:50;not null"`
+	FromWarehouseID uint                `json:"from_warehouse_id" gorm:"index;not null"` // 调出仓库ID
+	ToWarehouseID   uint                `json:"to_warehouse_id" gorm:"index;not null"`   // 调入仓库ID
+	CarNumber       string              `json:"car_number" gorm:"size:50;not null"`      // 车号
+	DriverName      string              `json:"driver_name" gorm:"size:50;not null"`     // 司机姓名
+	DriverPhone     string              `json:"driver_phone" gorm:"size:20;not null"`    // 司机手机号
+	Status          string              `json:"status" gorm:"size:20;not null;index"`    // 状态
+	Notes           string              `json:"notes" gorm:"type:text"`                  // 备注
+	CreatedBy       uint                `json:"created_by" gorm:"not null"`              // 创建人
+	ShippedBy       *uint               `json:"shipped_by"`                              // 发货人
+	ShippedAt       *time.Time          `json:"shipped_at"`                              // 发货时间
+	ReceivedBy      *uint               `json:"received_by"`                             // 收货人
+	ReceivedAt      *time.Time          `json:"received_at"`                             // 收货时间
+	CreatedAt       time.Time           `json:"created_at"`                              // 创建时间
+	UpdatedAt       time.Time           `json:"updated_at"`                              // 更新时间
+	Items           []TransferOrderItem `json:"items,omitempty" gorm:"-"`
+}
+
+// TableName sets the insert table name for this struct type
+func (TransferOrder) TableName() string {
+	return "transfer_orders"
+}
+
+// TransferOrderItem 调拨明细，每个分类一条
+type TransferOrderItem struct {
+	ID                uint     `json:"id" gorm:"primaryKey"`
+	TransferID        uint     `json:"transfer_id" gorm:"index;not null"`
+	CategoryID        uint     `json:"category_id" gorm:"index;not null"`
+	CategoryName      string   `json:"category_name" gorm:"-"`
+	Weight            float64  `json:"weight" gorm:"type:decimal(12,3);not null"`                        // 发货重量 kg
+	UnitCost          float64  `json:"unit_cost" gorm:"type:decimal(12,4);not null;default:0"`           // 发货时的单位成本 元/kg
+	ReceivedWeight    *float64 `json:"received_weight" gorm:"type:decimal(12,3)"`                        // 实收重量 kg，未收货为 null
+	TransitLoss       float64  `json:"transit_loss" gorm:"type:decimal(12,3);not null;default:0"`        // 途损 kg = 发货 - 实收
+	TransitLossAmount float64  `json:"transit_loss_amount" gorm:"type:decimal(14,2);not null;default:0"` // 途损金额
+}
+
+// TableName sets the insert table name for this struct type
+func (TransferOrderItem) TableName() string {
+	return "transfer_order_items"
+}
+
+// CreateTransferOrderRequest 创建调拨单请求
+type CreateTransferOrderRequest struct {
+	FromWarehouseID uint                        `json:"from_warehouse_id" binding:"required"`
+	ToWarehouseID   uint                        `json:"to_warehouse_id" binding:"required,nefield=FromWarehouseID"`
+	CarNumber       string                      `json:"car_number" binding:"required"`
+	DriverName      string                      `json:"driver_name" binding:"required"`
+	DriverPhone     string                      `json:"driver_phone" binding:"required"`
+	Notes           string                      `json:"notes"`
+	Items           []CreateTransferItemRequest `json:"items" binding:"required,min=1,dive"`
+	// Ship 为 true 时创建后立即发货
+	Ship bool `json:"ship"`
+}
+
+// CreateTransferItemRequest 调拨明细
+type CreateTransferItemRequest struct {
+	CategoryID uint    `json:"category_id" binding:"required"`
+	Weight     float64 `json:"weight" binding:"required,gt=0"`
+	// TraceCodes 随车调拨的电池溯源编码 (可选)，须在调出仓在库
+	TraceCodes []string `json:"trace_codes"`
+}
+
+// ReceiveTransferOrderRequest 调拨收货请求，每个调拨分类填写实收重量
+type ReceiveTransferOrderRequest struct {
+	Items []ReceiveTransferItemInput `json:"items" binding:"required,min=1,dive"`
+}
+
+// ReceiveTransferItemInput 调拨分类实收重量，不能超过发货重量，全部途损时填 0
+type ReceiveTransferItemInput struct {
+	CategoryID     uint     `json:"category_id" binding:"required"`
+	ReceivedWeight *float64 `json:"received_weight" binding:"required,gte=0"`
+}
+
+// GetTransferOrdersRequest 查询调拨单请求
+type GetTransferOrdersRequest struct {
+	Page     int    `json:"page" form:"page" binding:"omitempty,min=1"`
+	PageSize int    `json:"page_size" form:"page_size" binding:"omitempty,min=1,max=100"`
+	Status   string `json:"status" form:"status"`
+	// WarehouseID 调出或调入仓库ID
+	WarehouseID uint `json:"warehouse_id" form:"warehouse_id"`
+}
+
+type GetTransferOrdersResponse struct {
+	Transfers []TransferOrder `json:"transfers"`
+	Total     int64           `json:"total"`
+}
+
+// InTransitStock 在途库存汇总行 (按调出仓、调入仓和分类)
+type InTransitStock struct {
+	FromWarehouseID uint    `json:"from_warehouse_id"`
+	ToWarehouseID   uint    `json:"to_warehouse_id"`
+	CategoryID      uint    `json:"category_id"`
+	CategoryName    string  `json:"category_name"`
+	TransferCount   int64   `json:"transfer_count"`
+	Weight          float64 `json:"weight"` // 在途重量 kg
+	Value           float64 `json:"value"`  // 在途金额
+}
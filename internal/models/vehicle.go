@@ -0,0 +1,121 @@
+package models
+
+import "time"
+
+// Vehicle 车辆档案，登记皮重用于核对过磅实测皮重
+type Vehicle struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	PlateNumber     string    `json:"plate_number" gorm:"uniqueIndex;size:50;not null"`         // 车牌号
+	VehicleType     string    `json:"vehicle_type" gorm:"size:50"`                              // 车型
+	TareWeight      float64   `json:"tare_weight" gorm:"type:decimal(10,3);not null;default:0"` // 登记皮重 kg，0 表示未登记
+	Carrier         string    `json:"carrier" gorm:"size:100"`                                  // 承运单位
+	DefaultDriverID *uint     `json:"default_driver_id"`                                        // 常用司机，开单未指定司机时自动带出
+	Notes           string    `json:"notes" gorm:"type:text"`
+	IsActive        bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// TableName sets the insert table name for this struct type
+func (Vehicle) TableName() string {
+	return "vehicles"
+}
+
+// Driver 司机档案
+type Driver struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"size:50;not null"`
+	Phone     string    `json:"phone" gorm:"size:20;not null;index"`
+	LicenseNo string    `json:"license_no" gorm:"size:50"` // 驾驶证号
+	Notes     string    `json:"notes" gorm:"type:text"`
+	IsActive  bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName sets the insert table name for this struct type
+func (Driver) TableName() string {
+	return "drivers"
+}
+
+// CreateVehicleRequest represents request to create vehicle
+type CreateVehicleRequest struct {
+	PlateNumber     string  `json:"plate_number" binding:"required,max=50"`
+	VehicleType     string  `json:"vehicle_type" binding:"max=50"`
+	TareWeight      float64 `json:"tare_weight" binding:"gte=0"`
+	Carrier         string  `json:"carrier" binding:"max=100"`
+	DefaultDriverID *uint   `json:"default_driver_id"`
+	Notes           string  `json:"notes"`
+}
+
+// UpdateVehicleRequest represents request to update vehicle，未填写的字段保持不变
+type UpdateVehicleRequest struct {
+	VehicleType     string   `json:"vehicle_type" binding:"max=50"`
+	TareWeight      *float64 `json:"tare_weight" binding:"omitempty,gte=0"`
+	Carrier         string   `json:"carrier" binding:"max=100"`
+	DefaultDriverID *uint    `json:"default_driver_id"`
+	Notes           string   `json:"notes"`
+}
+
+// CreateDriverRequest represents request to create driver
+type CreateDriverRequest struct {
+	Name      string `json:"name" binding:"required,max=50"`
+	Phone     string `json:"phone" binding:"required,max=20"`
+	LicenseNo string `json:"license_no" binding:"max=50"`
+	Notes     string `json:"notes"`
+}
+
+// UpdateDriverRequest represents request to update driver，未填写的字段保持不变
+type UpdateDriverRequest struct {
+	Name      string `json:"name" binding:"max=50"`
+	Phone     string `json:"phone" binding:"max=20"`
+	LicenseNo string `json:"license_no" binding:"max=50"`
+	Notes     string `json:"notes"`
+}
+
+// GetVehiclesRequest 查询车辆请求
+type GetVehiclesRequest struct {
+	Keyword string `json:"keyword" form:"keyword"` // 按车牌号或承运单位模糊查询
+}
+
+// GetDriversRequest 查询司机请求
+type GetDriversRequest struct {
+	Keyword string `json:"keyword" form:"keyword"` // 按姓名或手机号模糊查询
+}
+
+// 车次来源
+const (
+	TripSourceWeighTicket   = "weigh_ticket"
+	TripSourceOutboundOrder = "outbound_order"
+)
+
+// VehicleTrip 车辆的一个车次：一张磅单，或未经磅单直接开具的出库订单
+type VehicleTrip struct {
+	Source        string    `json:"source"`         // weigh_ticket 或 outbound_order
+	SourceID      uint      `json:"source_id"`      // 磅单或出库订单ID
+	DocumentNo    string    `json:"document_no"`    // 磅单号或出库订单号
+	Direction     string    `json:"direction"`      // inbound 或 outbound
+	Status        string    `json:"status"`         // 单据状态
+	DriverName    string    `json:"driver_name"`    // 司机姓名
+	TripAt        time.Time `json:"trip_at"`        // 首次称重或开单时间
+	NetWeight     float64   `json:"net_weight"`     // 净重 kg (磅单未二次称重时为 0)
+	TareWeight    float64   `json:"tare_weight"`    // 实测皮重 kg (仅磅单)
+	TareDeviation float64   `json:"tare_deviation"` // 实测皮重 - 登记皮重 kg (仅磅单)
+	TareFlagged   bool      `json:"tare_flagged"`   // 实测皮重偏离登记皮重
+	OrderNo       string    `json:"order_no"`       // 磅单转入的订单号
+}
+
+// GetVehicleTripsRequest 查询车辆车次请求
+type GetVehicleTripsRequest struct {
+	StartDate string `json:"start_date" form:"start_date"`
+	EndDate   string `json:"end_date" form:"end_date"`
+}
+
+// VehicleTripHistory 车辆车次记录，按时间倒序
+type VehicleTripHistory struct {
+	Vehicle        Vehicle       `json:"vehicle"`
+	Trips          []VehicleTrip `json:"trips"`
+	TripCount      int           `json:"trip_count"`
+	TotalNetWeight float64       `json:"total_net_weight"` // 各车次净重合计 kg
+	FlaggedCount   int           `json:"flagged_count"`    // 皮重偏离的车次数
+}
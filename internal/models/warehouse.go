@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// 仓库类型
+const (
+	WarehouseTypeYard       = "yard"       // 回收场
+	WarehouseTypeProcessing = "processing" // 处理厂
+)
+
+// DefaultWarehouseCode 默认仓库编码，升级到多仓库前的库存和订单归入默认仓库
+const DefaultWarehouseCode = "DEFAULT"
+
+// Warehouse 仓库 (回收场/处理厂)，库存按 仓库 + 电池类型 记账
+type Warehouse struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Code      string    `json:"code" gorm:"uniqueIndex;size:20;not null"`
+	Name      string    `json:"name" gorm:"size:100;not null"`
+	Type      string    `json:"type" gorm:"size:20;not null;default:'yard'"`
+	Address   string    `json:"address" gorm:"size:255"`
+	IsDefault bool      `json:"is_default" gorm:"not null;default:false"`
+	IsActive  bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName sets the insert table name for this struct type
+func (Warehouse) TableName() string {
+	return "warehouses"
+}
+
+// CreateWarehouseRequest represents request to create warehouse
+type CreateWarehouseRequest struct {
+	Code    string `json:"code" binding:"required,max=20"`
+	Name    string `json:"name" binding:"required"`
+	Type    string `json:"type" binding:"omitempty,oneof=yard processing"` // 默认 yard
+	Address string `json:"address"`
+}
+
+// UpdateWarehouseRequest represents request to update warehouse
+type UpdateWarehouseRequest struct {
+	Name    string `json:"name"`
+	Type    string `json:"type" binding:"omitempty,oneof=yard processing"`
+	Address string `json:"address"`
+}
+
+// ConsolidatedInventory 单个分类跨仓库汇总的库存
+type ConsolidatedInventory struct {
+	CategoryID    uint                     `json:"category_id"`
+	CategoryName  string                   `json:"category_name"`
+	TotalWeightKg float64                  `json:"total_weight_kg"`
+	TotalValue    float64                  `json:"total_value"`
+	AvgUnitCost   float64                  `json:"avg_unit_cost"`
+	Warehouses    []WarehouseInventoryLine `json:"warehouses"`
+}
+
+// WarehouseInventoryLine 分类在单个仓库的库存
+type WarehouseInventoryLine struct {
+	WarehouseID     uint    `json:"warehouse_id"`
+	WarehouseName   string  `json:"warehouse_name"`
+	CurrentWeightKg float64 `json:"current_weight_kg"`
+	TotalValue      float64 `json:"total_value"`
+}
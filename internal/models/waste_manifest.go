@@ -0,0 +1,131 @@
+package models
+
+import "time"
+
+// 危险废物转移联单状态
+const (
+	ManifestStatusIssued   = "issued"   // 已开具，随车转移
+	ManifestStatusReceived = "received" // 接收单位已签收
+	ManifestStatusClosed   = "closed"   // 已办结
+)
+
+// WasteManifest 危险废物转移联单，每个已记账出库订单最多一份
+// 移出、运输和接收单位信息在开具时快照，之后修改订单或客户不影响联单
+type WasteManifest struct {
+	ID                uint                `json:"id" gorm:"primaryKey"`
+	ManifestNo        string              `json:"manifest_no" gorm:"uniqueIndex;size:50;not null"` // 联单编号
+	OutboundOrderID   uint                `json:"outbound_order_id" gorm:"uniqueIndex;not null"`   // 出库订单ID
+	OutboundOrderNo   string              `json:"outbound_order_no" gorm:"size:50;not null"`       // 出库订单号
+	Status            string              `json:"status" gorm:"size:20;not null;index"`            // 状态
+	ShipperName       string              `json:"shipper_name" gorm:"size:100;not null"`           // 移出单位
+	ShipperAddress    string              `json:"shipper_address" gorm:"size:255"`                 // 移出单位地址
+	ShipperLicenseNo  string              `json:"shipper_license_no" gorm:"size:50"`               // 移出单位许可证编号
+	ShipperContact    string              `json:"shipper_contact" gorm:"size:50"`                  // 移出单位联系人
+	ShipperPhone      string              `json:"shipper_phone" gorm:"size:20"`                    // 移出单位电话
+	CarNumber         string              `json:"car_number" gorm:"size:50;not null"`              // 运输车号
+	DriverName        string              `json:"driver_name" gorm:"size:50;not null"`             // 司机姓名
+	DriverPhone       string              `json:"driver_phone" gorm:"size:20;not null"`            // 司机手机号
+	ReceiverName      string              `json:"receiver_name" gorm:"size:100;not null"`          // 接收单位
+	ReceiverAddress   string              `json:"receiver_address" gorm:"size:255"`                // 接收单位地址 (送货地)
+	ReceiverLicenseNo string              `json:"receiver_license_no" gorm:"size:50"`              // 接收单位许可证编号
+	ReceiverContact   string              `json:"receiver_contact" gorm:"size:50"`                 // 接收单位联系人
+	ReceiverPhone     string              `json:"receiver_phone" gorm:"size:20"`                   // 接收单位电话
+	TotalWeight       float64             `json:"total_weight" gorm:"type:decimal(12,3);not null"` // 转移总重量 kg
+	ReceivedWeight    *float64            `json:"received_weight" gorm:"type:decimal(12,3)"`       // 接收单位签收重量 kg
+	Notes             string              `json:"notes" gorm:"type:text"`                          // 备注
+	IssuedBy          uint                `json:"issued_by" gorm:"not null"`                       // 开具人
+	IssuedAt          time.Time           `json:"issued_at"`                                       // 开具时间
+	ReceivedBy        *uint               `json:"received_by"`                                     // 签收登记人
+	ReceivedAt        *time.Time          `json:"received_at"`                                     // 签收时间
+	ClosedBy          *uint               `json:"closed_by"`                                       // 办结人
+	ClosedAt          *time.Time          `json:"closed_at"`                                       // 办结时间
+	CreatedAt         time.Time           `json:"created_at"`
+	UpdatedAt         time.Time           `json:"updated_at"`
+	Items             []WasteManifestItem `json:"items,omitempty" gorm:"-"`
+}
+
+// TableName sets the insert table name for this struct type
+func (WasteManifest) TableName() string {
+	return "waste_manifests"
+}
+
+// WasteManifestItem 联单废物明细，按分类汇总出库重量
+type WasteManifestItem struct {
+	ID           uint    `json:"id" gorm:"primaryKey"`
+	ManifestID   uint    `json:"manifest_id" gorm:"index;not null"`
+	CategoryID   uint    `json:"category_id" gorm:"not null"`
+	CategoryName string  `json:"category_name" gorm:"size:100;not null"` // 废物名称 (分类名称快照)
+	WasteCode    string  `json:"waste_code" gorm:"size:30;not null"`     // 危险废物代码
+	Weight       float64 `json:"weight" gorm:"type:decimal(12,3);not null"`
+}
+
+// TableName sets the insert table name for this struct type
+func (WasteManifestItem) TableName() string {
+	return "waste_manifest_items"
+}
+
+// CreateWasteManifestRequest 开具联单请求，出库订单未关联客户时须填写接收单位
+type CreateWasteManifestRequest struct {
+	ReceiverName      string `json:"receiver_name"`
+	ReceiverLicenseNo string `json:"receiver_license_no"`
+	Notes             string `json:"notes"`
+}
+
+// ReceiveWasteManifestRequest 联单签收请求
+type ReceiveWasteManifestRequest struct {
+	ReceivedWeight *float64 `json:"received_weight" binding:"omitempty,gte=0"`
+	Notes          string   `json:"notes"`
+}
+
+// GetWasteManifestsRequest 查询联单请求
+type GetWasteManifestsRequest struct {
+	Page      int    `json:"page" form:"page" binding:"omitempty,min=1"`
+	PageSize  int    `json:"page_size" form:"page_size" binding:"omitempty,min=1,max=100"`
+	Status    string `json:"status" form:"status"`
+	StartDate string `json:"start_date" form:"start_date"`
+	EndDate   string `json:"end_date" form:"end_date"`
+}
+
+type GetWasteManifestsResponse struct {
+	Manifests []WasteManifest `json:"manifests"`
+	Total     int64           `json:"total"`
+}
+
+// ManifestParty 联单中的单位信息
+type ManifestParty struct {
+	Name          string `json:"name"`
+	Address       string `json:"address,omitempty"`
+	LicenseNo     string `json:"license_no,omitempty"`
+	ContactPerson string `json:"contact_person,omitempty"`
+	Phone         string `json:"phone,omitempty"`
+}
+
+// ManifestCarrier 联单中的运输信息
+type ManifestCarrier struct {
+	VehicleNo   string `json:"vehicle_no"`
+	DriverName  string `json:"driver_name"`
+	DriverPhone string `json:"driver_phone"`
+}
+
+// ManifestWaste 联单中的废物明细
+type ManifestWaste struct {
+	WasteCode string  `json:"waste_code"`
+	WasteName string  `json:"waste_name"`
+	WeightKg  float64 `json:"weight_kg"`
+}
+
+// WasteManifestExport 联单上报用的结构化数据
+type WasteManifestExport struct {
+	ManifestNo       string          `json:"manifest_no"`
+	Status           string          `json:"status"`
+	IssuedAt         time.Time       `json:"issued_at"`
+	ReceivedAt       *time.Time      `json:"received_at,omitempty"`
+	ClosedAt         *time.Time      `json:"closed_at,omitempty"`
+	OutboundOrderNo  string          `json:"outbound_order_no"`
+	Shipper          ManifestParty   `json:"shipper"`
+	Carrier          ManifestCarrier `json:"carrier"`
+	Receiver         ManifestParty   `json:"receiver"`
+	Wastes           []ManifestWaste `json:"wastes"`
+	TotalWeightKg    float64         `json:"total_weight_kg"`
+	ReceivedWeightKg *float64        `json:"received_weight_kg,omitempty"`
+}
@@ -0,0 +1,117 @@
+package models
+
+import "time"
+
+// 磅单方向，决定两次称重中哪一次是毛重
+const (
+	WeighTicketDirectionInbound  = "inbound"  // 进场卸货：首次称重车 (毛重)，二次称空车 (皮重)
+	WeighTicketDirectionOutbound = "outbound" // 出场装货：首次称空车 (皮重)，二次称重车 (毛重)
+)
+
+// 磅单状态
+const (
+	WeighTicketStatusOpen      = "open"      // 已首次称重，车辆在场内
+	WeighTicketStatusClosed    = "closed"    // 已二次称重，待转订单
+	WeighTicketStatusConverted = "converted" // 已转为入库/出库订单
+	WeighTicketStatusCancelled = "cancelled" // 已作废
+)
+
+// WeighTicket 磅单，记录车辆进场和出场两次称重
+// 二次称重后按方向计算毛重、皮重和净重，再转为入库或出库订单
+type WeighTicket struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	TicketNo        string     `json:"ticket_no" gorm:"uniqueIndex;size:50;not null"`                // 磅单号
+	Direction       string     `json:"direction" gorm:"size:20;not null;index"`                      // inbound 或 outbound
+	Status          string     `json:"status" gorm:"size:20;not null;index"`                         // 状态
+	CarNumber       string     `json:"car_number" gorm:"size:50;not null;index"`                     // 车号
+	DriverName      string     `json:"driver_name" gorm:"size:50"`                                   // 司机姓名
+	DriverPhone     string     `json:"driver_phone" gorm:"size:20"`                                  // 司机手机号
+	VehicleID       *uint      `json:"vehicle_id" gorm:"index"`                                      // 车辆档案ID
+	DriverID        *uint      `json:"driver_id" gorm:"index"`                                       // 司机档案ID
+	FirstWeight     float64    `json:"first_weight" gorm:"type:decimal(10,3);not null"`              // 首次称重 kg
+	FirstFromScale  bool       `json:"first_from_scale" gorm:"not null;default:false"`               // 首次称重由地磅采集
+	FirstWeighedBy  uint       `json:"first_weighed_by" gorm:"not null"`                             // 首次称重司磅员
+	FirstWeighedAt  time.Time  `json:"first_weighed_at" gorm:"not null"`                             // 首次称重时间
+	SecondWeight    *float64   `json:"second_weight" gorm:"type:decimal(10,3)"`                      // 二次称重 kg
+	SecondFromScale bool       `json:"second_from_scale" gorm:"not null;default:false"`              // 二次称重由地磅采集
+	SecondWeighedBy *uint      `json:"second_weighed_by"`                                            // 二次称重司磅员
+	SecondWeighedAt *time.Time `json:"second_weighed_at"`                                            // 二次称重时间
+	GrossWeight     float64    `json:"gross_weight" gorm:"type:decimal(10,3);not null;default:0"`    // 毛重 kg，二次称重后计算
+	TareWeight      float64    `json:"tare_weight" gorm:"type:decimal(10,3);not null;default:0"`     // 皮重 kg，二次称重后计算
+	NetWeight       float64    `json:"net_weight" gorm:"type:decimal(10,3);not null;default:0"`      // 净重 kg，二次称重后计算
+	RegisteredTare  float64    `json:"registered_tare" gorm:"type:decimal(10,3);not null;default:0"` // 二次称重时车辆档案的登记皮重 kg，0 表示未登记
+	TareDeviation   float64    `json:"tare_deviation" gorm:"type:decimal(10,3);not null;default:0"`  // 实测皮重 - 登记皮重 kg
+	TareFlagged     bool       `json:"tare_flagged" gorm:"not null;default:false;index"`             // 实测皮重偏离登记皮重超过阈值
+	OrderID         *uint      `json:"order_id" gorm:"index"`                                        // 转入的入库/出库订单ID (按方向)
+	OrderNo         string     `json:"order_no" gorm:"size:50"`                                      // 转入的订单号
+	Notes           string     `json:"notes" gorm:"type:text"`                                       // 备注
+	CreatedBy       uint       `json:"created_by" gorm:"not null"`                                   // 创建人
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// TableName sets the insert table name for this struct type
+func (WeighTicket) TableName() string {
+	return "weigh_tickets"
+}
+
+// CreateWeighTicketRequest 首次称重开磅单，未填写重量时取地磅当前稳定读数
+// 指定车辆或司机档案时自动带出车号和司机信息
+type CreateWeighTicketRequest struct {
+	Direction   string   `json:"direction" binding:"required,oneof=inbound outbound"`
+	VehicleID   *uint    `json:"vehicle_id"`
+	CarNumber   string   `json:"car_number" binding:"required_without=VehicleID"`
+	DriverID    *uint    `json:"driver_id"`
+	DriverName  string   `json:"driver_name"`
+	DriverPhone string   `json:"driver_phone"`
+	Weight      *float64 `json:"weight" binding:"omitempty,gt=0"`
+	Notes       string   `json:"notes"`
+}
+
+// SecondWeighRequest 二次称重请求，未填写重量时取地磅当前稳定读数
+type SecondWeighRequest struct {
+	Weight *float64 `json:"weight" binding:"omitempty,gt=0"`
+}
+
+// ConvertWeighTicketRequest 磅单转订单请求
+// 入库磅单填写卖家或供应商，出库磅单填写客户或送货地址；各项重量之和须等于磅单净重，只有一项时可不填重量
+type ConvertWeighTicketRequest struct {
+	WarehouseID     uint                     `json:"warehouse_id" binding:"required"`
+	SellerID        *uint                    `json:"seller_id"`
+	SupplierName    string                   `json:"supplier_name"`
+	CustomerID      *uint                    `json:"customer_id"`
+	DeliveryAddress string                   `json:"delivery_address"`
+	Status          string                   `json:"status" binding:"omitempty,oneof=draft confirmed completed"` // 默认 completed
+	Notes           string                   `json:"notes"`
+	CreditOverride  bool                     `json:"credit_override"` // 出库客户超过信用额度时放行，仅超级管理员可用
+	Items           []ConvertWeighTicketItem `json:"items" binding:"required,min=1,dive"`
+}
+
+// ConvertWeighTicketItem 磅单转订单的订单项，扣杂仅用于入库
+type ConvertWeighTicketItem struct {
+	CategoryID uint    `json:"category_id" binding:"required"`
+	Weight     float64 `json:"weight" binding:"omitempty,gt=0"`
+	UnitPrice  float64 `json:"unit_price" binding:"omitempty,gt=0"`
+	Grade      string  `json:"grade" binding:"max=20"`
+	InboundDeductions
+	Lots       []LotSelection `json:"lots" binding:"omitempty,dive"`
+	TraceCodes []string       `json:"trace_codes"`
+}
+
+// GetWeighTicketsRequest 查询磅单请求
+type GetWeighTicketsRequest struct {
+	Page      int    `json:"page" form:"page" binding:"omitempty,min=1"`
+	PageSize  int    `json:"page_size" form:"page_size" binding:"omitempty,min=1,max=100"`
+	Direction string `json:"direction" form:"direction"`
+	Status    string `json:"status" form:"status"`
+	CarNumber string `json:"car_number" form:"car_number"`
+	// TareFlagged 仅返回皮重偏离的磅单
+	TareFlagged bool   `json:"tare_flagged" form:"tare_flagged"`
+	StartDate   string `json:"start_date" form:"start_date"`
+	EndDate     string `json:"end_date" form:"end_date"`
+}
+
+type GetWeighTicketsResponse struct {
+	Tickets []WeighTicket `json:"tickets"`
+	Total   int64         `json:"total"`
+}
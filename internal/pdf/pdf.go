@@ -0,0 +1,148 @@
+// Package pdf 生成仅含文字和线条的简单 PDF 文档 (A4 纵向)
+//
+// 中文使用 PDF 阅读器内置的 Adobe 标准字体 STSong-Light (UniGB-UCS2-H 编码)，
+// 字体不嵌入文件，因此生成的文件很小且不依赖服务器上的字体文件。
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf16"
+)
+
+// A4 页面尺寸，单位为点 (1/72 英寸)
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// Document PDF 文档，坐标原点为页面左上角，y 轴向下
+type Document struct {
+	pages []*bytes.Buffer
+}
+
+// New 创建空文档
+func New() *Document {
+	return &Document{}
+}
+
+// AddPage 新增一页，之后的绘制都在该页上
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+// PageCount 当前页数
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+// Text 在当前页 (x, y) 处绘制文字，y 为文字顶部位置
+func (d *Document) Text(x, y, size float64, text string) {
+	page := d.currentPage()
+	baseline := PageHeight - y - size*0.88
+	fmt.Fprintf(page, "BT /F1 %.2f Tf %.2f %.2f Td <%s> Tj ET\n", size, x, baseline, encodeText(text))
+}
+
+// TextRight 绘制右对齐文字，right 为文字右边缘位置
+func (d *Document) TextRight(right, y, size float64, text string) {
+	d.Text(right-TextWidth(text, size), y, size, text)
+}
+
+// TextCenter 绘制居中文字，center 为文字中心位置
+func (d *Document) TextCenter(center, y, size float64, text string) {
+	d.Text(center-TextWidth(text, size)/2, y, size, text)
+}
+
+// Line 在当前页绘制线段
+func (d *Document) Line(x1, y1, x2, y2 float64) {
+	page := d.currentPage()
+	fmt.Fprintf(page, "0.6 w %.2f %.2f m %.2f %.2f l S\n", x1, PageHeight-y1, x2, PageHeight-y2)
+}
+
+// Rect 在当前页绘制矩形边框，(x, y) 为左上角
+func (d *Document) Rect(x, y, width, height float64) {
+	page := d.currentPage()
+	fmt.Fprintf(page, "0.6 w %.2f %.2f %.2f %.2f re S\n", x, PageHeight-y-height, width, height)
+}
+
+// Bytes 输出完整的 PDF 文件内容
+func (d *Document) Bytes() []byte {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	// 对象编号: 1 目录, 2 页面树, 3 字体, 4 CID 字体, 5 字体描述, 之后每页依次为页面对象和内容流
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"", // 页面树在确定页面对象编号后填入
+		"<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UCS2-H /DescendantFonts [4 0 R] >>",
+		"<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light " +
+			"/CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 2 >> " +
+			"/FontDescriptor 5 0 R /DW 1000 /W [1 95 500] >>",
+		"<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 /FontBBox [-25 -254 1000 880] " +
+			"/ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>",
+	}
+
+	var kids bytes.Buffer
+	for _, page := range d.pages {
+		pageObj := len(objects) + 1
+		contentObj := pageObj + 1
+		fmt.Fprintf(&kids, "%d 0 R ", pageObj)
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+				PageWidth, PageHeight, contentObj),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", bytes.TrimSpace(kids.Bytes()), len(d.pages))
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return out.Bytes()
+}
+
+// TextWidth 估算文字宽度: ASCII 字符为半角，其余为全角
+func TextWidth(text string, size float64) float64 {
+	var width float64
+	for _, r := range text {
+		if r < 0x80 {
+			width += size / 2
+		} else {
+			width += size
+		}
+	}
+	return width
+}
+
+func (d *Document) currentPage() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// encodeText 将文字编码为 UCS-2 大端十六进制串，超出基本平面的字符以问号代替
+func encodeText(text string) string {
+	var buf bytes.Buffer
+	for _, r := range text {
+		if r > 0xFFFF {
+			r = '?'
+		}
+		for _, unit := range utf16.Encode([]rune{r}) {
+			fmt.Fprintf(&buf, "%04X", unit)
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"battery-erp-backend/internal/models"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AdjustmentRepository 库存调整单数据仓库
+type AdjustmentRepository struct {
+	db *gorm.DB
+}
+
+// NewAdjustmentRepository 创建库存调整单仓库实例
+func NewAdjustmentRepository(db *gorm.DB) *AdjustmentRepository {
+	return &AdjustmentRepository{db: db}
+}
+
+// Create 创建库存调整单
+func (r *AdjustmentRepository) Create(adjustment *models.InventoryAdjustment) error {
+	return r.db.Create(adjustment).Error
+}
+
+// GetByID 根据ID获取库存调整单
+func (r *AdjustmentRepository) GetByID(id uint) (*models.InventoryAdjustment, error) {
+	var adjustment models.InventoryAdjustment
+	err := r.db.First(&adjustment, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &adjustment, nil
+}
+
+// GetByIDForUpdate 根据ID获取库存调整单并加行锁，需在事务中调用
+func (r *AdjustmentRepository) GetByIDForUpdate(id uint) (*models.InventoryAdjustment, error) {
+	var adjustment models.InventoryAdjustment
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&adjustment, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &adjustment, nil
+}
+
+// GetAll 分页获取库存调整单
+func (r *AdjustmentRepository) GetAll(req *models.GetInventoryAdjustmentsRequest) ([]models.InventoryAdjustment, int64, error) {
+	query := r.db.Model(&models.InventoryAdjustment{})
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.CategoryID != 0 {
+		query = query.Where("category_id = ?", req.CategoryID)
+	}
+	if req.WarehouseID != 0 {
+		query = query.Where("warehouse_id = ?", req.WarehouseID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var adjustments []models.InventoryAdjustment
+	err := query.Order("id DESC").
+		Offset((req.Page - 1) * req.PageSize).
+		Limit(req.PageSize).
+		Find(&adjustments).Error
+
+	return adjustments, total, err
+}
+
+// UpdateFields 显式更新库存调整单字段
+func (r *AdjustmentRepository) UpdateFields(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&models.InventoryAdjustment{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// GenerateAdjustmentNo 生成库存调整单号
+func (r *AdjustmentRepository) GenerateAdjustmentNo() (string, error) {
+	now := time.Now()
+
+	// 格式：ADJ-20240101-123456789-1234
+	adjustmentNo := fmt.Sprintf("ADJ-%s-%05d-%04d", now.Format("20060102"), now.Nanosecond(), rand.Intn(10000))
+	return adjustmentNo, nil
+}
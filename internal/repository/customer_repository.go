@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"battery-erp-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CustomerRepository 客户数据仓库
+type CustomerRepository struct {
+	db *gorm.DB
+}
+
+// NewCustomerRepository 创建客户仓库实例
+func NewCustomerRepository(db *gorm.DB) *CustomerRepository {
+	return &CustomerRepository{db: db}
+}
+
+// Create 创建客户
+func (r *CustomerRepository) Create(customer *models.Customer) error {
+	return r.db.Create(customer).Error
+}
+
+// GetByID 根据ID获取客户
+func (r *CustomerRepository) GetByID(id uint) (*models.Customer, error) {
+	var customer models.Customer
+	err := r.db.Where("id = ? AND is_active = ?", id, true).First(&customer).Error
+	if err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// GetByIDForUpdate 根据ID获取客户并加行锁，需在事务中调用
+func (r *CustomerRepository) GetByIDForUpdate(id uint) (*models.Customer, error) {
+	var customer models.Customer
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND is_active = ?", id, true).First(&customer).Error
+	if err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// Search 根据关键字搜索活跃客户 (名称、联系人或电话模糊匹配)
+func (r *CustomerRepository) Search(keyword string) ([]models.Customer, error) {
+	var customers []models.Customer
+	query := r.db.Where("is_active = ?", true)
+	if keyword != "" {
+		like := "%" + keyword + "%"
+		query = query.Where("name LIKE ? OR contact_person LIKE ? OR phone LIKE ?", like, like, like)
+	}
+	err := query.Order("id DESC").Find(&customers).Error
+	return customers, err
+}
+
+// UpdateFields 显式更新指定字段
+func (r *CustomerRepository) UpdateFields(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&models.Customer{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// Delete 软删除客户 (设置为非活跃状态)
+func (r *CustomerRepository) Delete(id uint) error {
+	return r.db.Model(&models.Customer{}).Where("id = ?", id).Update("is_active", false).Error
+}
+
+// GetAddresses 获取客户的所有地址，默认地址排在最前
+func (r *CustomerRepository) GetAddresses(customerID uint) ([]models.CustomerAddress, error) {
+	var addresses []models.CustomerAddress
+	err := r.db.Where("customer_id = ?", customerID).Order("is_default DESC, id ASC").Find(&addresses).Error
+	return addresses, err
+}
+
+// ReplaceAddresses 用新的地址列表替换客户的所有地址
+func (r *CustomerRepository) ReplaceAddresses(customerID uint, addresses []models.CustomerAddress) error {
+	if err := r.db.Where("customer_id = ?", customerID).Delete(&models.CustomerAddress{}).Error; err != nil {
+		return err
+	}
+	for i := range addresses {
+		addresses[i].ID = 0
+		addresses[i].CustomerID = customerID
+		if err := r.db.Create(&addresses[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
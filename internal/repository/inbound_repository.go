@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // 初始化随机数种子
@@ -44,6 +45,16 @@ func (r *InboundRepository) GetByID(id uint) (*models.InboundOrder, error) {
 	return &order, nil
 }
 
+// GetByIDForUpdate 根据ID获取入库订单并加行锁，需在事务中调用
+func (r *InboundRepository) GetByIDForUpdate(id uint) (*models.InboundOrder, error) {
+	var order models.InboundOrder
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&order).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
 // GetAll 获取所有入库订单 (分页)
 func (r *InboundRepository) GetAll(limit, offset int) ([]models.InboundOrder, int64, error) {
 	var orders []models.InboundOrder
@@ -68,6 +79,21 @@ func (r *InboundRepository) GetAllWithConditions(req *models.GetInboundOrderRequ
 	if req.Supplier != "" {
 		query = query.Where("supplier_name LIKE ?", "%"+req.Supplier+"%")
 	}
+	if req.SellerID != 0 {
+		query = query.Where("seller_id = ?", req.SellerID)
+	}
+	if req.WarehouseID != 0 {
+		query = query.Where("warehouse_id = ?", req.WarehouseID)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.PriceFlagged {
+		query = query.Where("price_flagged = ?", true)
+	}
+	if req.PaymentStatus != "" {
+		query = query.Where("payment_status = ?", req.PaymentStatus)
+	}
 	if req.StartDate != "" && req.EndDate != "" {
 		query = query.Where("created_at >= ? AND created_at <= ?", req.StartDate, req.EndDate)
 	}
@@ -137,13 +163,28 @@ func (r *InboundRepository) GetItemsByOrderID(orderID uint) ([]models.InboundOrd
 
 	err := r.db.Table("inbound_order_items as i").
 		Select(`
+			i.id,
 			i.category_id,
 			COALESCE(c.name, '未知分类') as category_name,
 			i.gross_weight,
 			i.tare_weight,
 			i.net_weight,
+			i.moisture_percent,
+			i.moisture_weight,
+			i.packaging_percent,
+			i.packaging_weight,
+			i.impurity_percent,
+			i.impurity_weight,
+			i.mixed_percent,
+			i.mixed_weight,
+			i.gross_from_scale,
+			i.tare_from_scale,
+			i.deduction_weight,
+			i.settlement_weight,
 			i.unit_price,
-			i.sub_total
+			i.list_price,
+			i.sub_total,
+			i.grade
 		`).
 		Joins("LEFT JOIN battery_categories c ON i.category_id = c.id").
 		Where("i.order_id = ?", orderID).
@@ -152,6 +193,80 @@ func (r *InboundRepository) GetItemsByOrderID(orderID uint) ([]models.InboundOrd
 	return result, err
 }
 
+// UpdateItem 更新入库订单项
+func (r *InboundRepository) UpdateItem(item *models.InboundOrderItem) error {
+	return r.db.Save(item).Error
+}
+
+// DeleteItem 删除入库订单项
+func (r *InboundRepository) DeleteItem(itemID uint) error {
+	return r.db.Delete(&models.InboundOrderItem{}, itemID).Error
+}
+
+// GetItemByID 根据ID获取入库订单项
+func (r *InboundRepository) GetItemByID(itemID uint) (*models.InboundOrderItem, error) {
+	var item models.InboundOrderItem
+	if err := r.db.First(&item, itemID).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// GetRawItemsByOrderID 获取原始订单项（不包含分类名称）
+func (r *InboundRepository) GetRawItemsByOrderID(orderID uint) ([]models.InboundOrderItem, error) {
+	var items []models.InboundOrderItem
+	err := r.db.Where("order_id = ?", orderID).Find(&items).Error
+	return items, err
+}
+
+// CountBySellerID 统计卖家关联的入库订单数量
+func (r *InboundRepository) CountBySellerID(sellerID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.InboundOrder{}).Where("seller_id = ? AND is_deleted = 0", sellerID).Count(&count).Error
+	return count, err
+}
+
+// GetSellerPayableTotals 汇总卖家已记账入库订单的应付金额和已付金额
+func (r *InboundRepository) GetSellerPayableTotals(sellerID uint) (float64, float64, error) {
+	var result struct {
+		TotalAmount float64
+		PaidAmount  float64
+	}
+	err := r.db.Model(&models.InboundOrder{}).
+		Select("COALESCE(SUM(total_amount), 0) as total_amount, COALESCE(SUM(paid_amount), 0) as paid_amount").
+		Where("seller_id = ? AND is_deleted = 0 AND status IN ?", sellerID, []string{models.OrderStatusConfirmed, models.OrderStatusCompleted}).
+		Scan(&result).Error
+	return result.TotalAmount, result.PaidAmount, err
+}
+
+// GetUnpaidBySeller 获取卖家未付清的已记账入库订单，按创建时间升序
+func (r *InboundRepository) GetUnpaidBySeller(sellerID uint) ([]models.InboundOrder, error) {
+	var orders []models.InboundOrder
+	err := r.db.Where("seller_id = ? AND is_deleted = 0 AND status IN ?", sellerID, []string{models.OrderStatusConfirmed, models.OrderStatusCompleted}).
+		Where("payment_status <> ?", models.OrderPaymentPaid).
+		Order("created_at ASC").
+		Find(&orders).Error
+	return orders, err
+}
+
+// GetUnlinkedSupplierNames 获取尚未关联卖家的供应商名称
+func (r *InboundRepository) GetUnlinkedSupplierNames() ([]string, error) {
+	var names []string
+	err := r.db.Model(&models.InboundOrder{}).
+		Where("seller_id IS NULL").
+		Distinct().
+		Pluck("supplier_name", &names).Error
+	return names, err
+}
+
+// LinkSupplierToSeller 将指定供应商名称的未关联订单关联到卖家，返回关联的订单数
+func (r *InboundRepository) LinkSupplierToSeller(supplierName string, sellerID uint) (int64, error) {
+	result := r.db.Model(&models.InboundOrder{}).
+		Where("seller_id IS NULL AND supplier_name = ?", supplierName).
+		Update("seller_id", sellerID)
+	return result.RowsAffected, result.Error
+}
+
 // GenerateOrderNo 生成订单号 (并发安全：纳秒时间戳+随机数)
 func (r *InboundRepository) GenerateOrderNo() (string, error) {
 	now := time.Now()
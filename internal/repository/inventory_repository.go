@@ -4,9 +4,11 @@ import (
 	"battery-erp-backend/internal/models"
 	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // InventoryRepository 库存数据仓库 (不再是接口实现)
@@ -19,20 +21,43 @@ func NewInventoryRepository(db *gorm.DB) *InventoryRepository {
 	return &InventoryRepository{db: db}
 }
 
-// GetByCategoryID 根据分类ID获取库存
-func (r *InventoryRepository) GetByCategoryID(categoryID uint) (*models.Inventory, error) {
+// GetByCategoryID 获取分类在指定仓库的库存
+func (r *InventoryRepository) GetByCategoryID(warehouseID, categoryID uint) (*models.Inventory, error) {
 	var inventory models.Inventory
-	err := r.db.Where("category_id = ?", categoryID).First(&inventory).Error
+	err := r.db.Where("warehouse_id = ? AND category_id = ?", warehouseID, categoryID).First(&inventory).Error
 	if err != nil {
 		return nil, err
 	}
 	return &inventory, nil
 }
 
-// GetAll 获取所有库存
-func (r *InventoryRepository) GetAll() ([]models.Inventory, error) {
+// GetByCategoryIDForUpdate 获取分类在指定仓库的库存并加行锁 (SELECT ... FOR UPDATE)，需在事务中调用
+func (r *InventoryRepository) GetByCategoryIDForUpdate(warehouseID, categoryID uint) (*models.Inventory, error) {
+	var inventory models.Inventory
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("warehouse_id = ? AND category_id = ?", warehouseID, categoryID).
+		First(&inventory).Error
+	if err != nil {
+		return nil, err
+	}
+	return &inventory, nil
+}
+
+// GetAllByCategoryID 获取分类在所有仓库的库存
+func (r *InventoryRepository) GetAllByCategoryID(categoryID uint) ([]models.Inventory, error) {
+	var inventories []models.Inventory
+	err := r.db.Where("category_id = ?", categoryID).Order("warehouse_id").Find(&inventories).Error
+	return inventories, err
+}
+
+// GetAll 获取所有库存，warehouseID 为 0 时返回全部仓库
+func (r *InventoryRepository) GetAll(warehouseID uint) ([]models.Inventory, error) {
 	var inventories []models.Inventory
-	err := r.db.Find(&inventories).Error
+	query := r.db.Model(&models.Inventory{})
+	if warehouseID != 0 {
+		query = query.Where("warehouse_id = ?", warehouseID)
+	}
+	err := query.Order("warehouse_id, category_id").Find(&inventories).Error
 	return inventories, err
 }
 
@@ -42,37 +67,50 @@ func (r *InventoryRepository) Create(inventory *models.Inventory) error {
 }
 
 // UpdateCurrentWeight 显式更新当前重量
-func (r *InventoryRepository) UpdateCurrentWeight(categoryID uint, weight float64) error {
-	return r.db.Model(&models.Inventory{}).Where("category_id = ?", categoryID).Update("current_weight_kg", weight).Error
+func (r *InventoryRepository) UpdateCurrentWeight(warehouseID, categoryID uint, weight float64) error {
+	return r.inventoryRow(warehouseID, categoryID).Update("current_weight_kg", weight).Error
 }
 
 // UpdateLastInboundAt 显式更新最后入库时间
-func (r *InventoryRepository) UpdateLastInboundAt(categoryID uint, lastInboundAt time.Time) error {
-	return r.db.Model(&models.Inventory{}).Where("category_id = ?", categoryID).Update("last_inbound_at", lastInboundAt).Error
+func (r *InventoryRepository) UpdateLastInboundAt(warehouseID, categoryID uint, lastInboundAt time.Time) error {
+	return r.inventoryRow(warehouseID, categoryID).Update("last_inbound_at", lastInboundAt).Error
 }
 
 // UpdateLastOutboundAt 显式更新最后出库时间
-func (r *InventoryRepository) UpdateLastOutboundAt(categoryID uint, lastOutboundAt time.Time) error {
-	return r.db.Model(&models.Inventory{}).Where("category_id = ?", categoryID).Update("last_outbound_at", lastOutboundAt).Error
+func (r *InventoryRepository) UpdateLastOutboundAt(warehouseID, categoryID uint, lastOutboundAt time.Time) error {
+	return r.inventoryRow(warehouseID, categoryID).Update("last_outbound_at", lastOutboundAt).Error
 }
 
 // UpdateFields 显式更新指定字段
-func (r *InventoryRepository) UpdateFields(categoryID uint, updates map[string]interface{}) error {
-	return r.db.Model(&models.Inventory{}).Where("category_id = ?", categoryID).Updates(updates).Error
+func (r *InventoryRepository) UpdateFields(warehouseID, categoryID uint, updates map[string]interface{}) error {
+	return r.inventoryRow(warehouseID, categoryID).Updates(updates).Error
 }
 
+// inventoryRow 定位 仓库 + 分类 的库存行
+func (r *InventoryRepository) inventoryRow(warehouseID, categoryID uint) *gorm.DB {
+	return r.db.Model(&models.Inventory{}).Where("warehouse_id = ? AND category_id = ?", warehouseID, categoryID)
+}
+
+// weightTolerance 重量比较容差 (库存重量精确到 0.001 kg)
+const weightTolerance = 0.0005
+
 // UpdateWeight 显式更新库存重量 (事务)
-func (r *InventoryRepository) UpdateWeight(categoryID uint, weightChange float64, isInbound bool) error {
+// 库存行通过 SELECT ... FOR UPDATE 加锁，并发出库不会同时通过库存充足校验；
+// 按分类的计价方法同步更新库存成本，每次变动在同一事务中写入一条带成本的库存流水
+func (r *InventoryRepository) UpdateWeight(warehouseID, categoryID uint, weightChange float64, isInbound bool, source models.MovementSource) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
 		var inventory models.Inventory
 
-		// Find or create inventory record
-		err := tx.Where("category_id = ?", categoryID).First(&inventory).Error
+		// Find (with row lock) or create inventory record
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("warehouse_id = ? AND category_id = ?", warehouseID, categoryID).
+			First(&inventory).Error
 		if err != nil {
 			if err == gorm.ErrRecordNotFound {
 				// Create new inventory record
 				now := time.Now()
 				inventory = models.Inventory{
+					WarehouseID:     warehouseID,
 					CategoryID:      categoryID,
 					CurrentWeightKg: 0,
 					CreatedAt:       now,
@@ -86,15 +124,40 @@ func (r *InventoryRepository) UpdateWeight(categoryID uint, weightChange float64
 			}
 		}
 
+		var category models.BatteryCategory
+		if err := tx.Select("id", "costing_method").First(&category, categoryID).Error; err != nil {
+			return err
+		}
+
 		// 显式更新相关字段
 		now := time.Now()
 		updates := map[string]interface{}{
 			"updated_at": now,
 		}
 
+		var delta, costAmount float64
 		if isInbound {
-			newWeight := inventory.CurrentWeightKg + weightChange
-			updates["current_weight_kg"] = newWeight
+			delta = weightChange
+			unitCost := source.UnitCost
+			if unitCost <= 0 {
+				unitCost = inventory.AvgUnitCost
+			}
+			costAmount = weightChange * unitCost
+
+			if category.CostingMethod == models.CostingMethodFIFO {
+				layer := models.CostLayer{
+					WarehouseID:     warehouseID,
+					CategoryID:      categoryID,
+					SourceType:      source.SourceType,
+					SourceID:        source.SourceID,
+					OriginalWeight:  weightChange,
+					RemainingWeight: weightChange,
+					UnitCost:        unitCost,
+				}
+				if err := tx.Create(&layer).Error; err != nil {
+					return err
+				}
+			}
 			updates["last_inbound_at"] = now
 		} else {
 			// Check for overselling
@@ -102,11 +165,216 @@ func (r *InventoryRepository) UpdateWeight(categoryID uint, weightChange float64
 				return errors.New(fmt.Sprintf("insufficient inventory: current weight is %.3f kg, requested %.3f kg",
 					inventory.CurrentWeightKg, weightChange))
 			}
-			newWeight := inventory.CurrentWeightKg - weightChange
-			updates["current_weight_kg"] = newWeight
+			delta = -weightChange
+
+			cost, err := issueCost(tx, &inventory, category.CostingMethod, weightChange, source)
+			if err != nil {
+				return err
+			}
+			costAmount = -cost
 			updates["last_outbound_at"] = now
 		}
 
-		return tx.Model(&models.Inventory{}).Where("category_id = ?", categoryID).Updates(updates).Error
+		newWeight := inventory.CurrentWeightKg + delta
+		newValue := inventory.TotalValue + costAmount
+		avgUnitCost := inventory.AvgUnitCost
+		if newWeight < weightTolerance || newValue < 0 {
+			// 库存清空 (或按指定成本冲回后为负) 时金额归零，保留最后的平均成本供退回入库使用
+			newValue = 0
+		}
+		if newWeight >= weightTolerance {
+			avgUnitCost = newValue / newWeight
+		}
+		updates["current_weight_kg"] = newWeight
+		updates["avg_unit_cost"] = avgUnitCost
+		updates["total_value"] = newValue
+
+		if err := tx.Model(&models.Inventory{}).Where("id = ?", inventory.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		movement := models.InventoryMovement{
+			WarehouseID:  warehouseID,
+			CategoryID:   categoryID,
+			Delta:        delta,
+			BalanceAfter: newWeight,
+			UnitCost:     math.Abs(costAmount) / weightChange,
+			CostAmount:   costAmount,
+			ValueAfter:   newValue,
+			SourceType:   source.SourceType,
+			SourceID:     source.SourceID,
+			Reason:       source.Reason,
+			CreatedBy:    source.UserID,
+			CreatedAt:    now,
+		}
+		return tx.Create(&movement).Error
+	})
+}
+
+// issueCost 计算从库存行出库 weight kg 的成本金额
+// 移动加权平均按平均成本 (或来源指定的成本) 计算；先进先出按成本层先后消耗，
+// 来源单据自身形成的成本层优先消耗 (冲回入库、重扣出库退回的库存)，成本层不足部分按平均成本计算
+func issueCost(tx *gorm.DB, inventory *models.Inventory, method string, weight float64, source models.MovementSource) (float64, error) {
+	if method != models.CostingMethodFIFO {
+		unitCost := inventory.AvgUnitCost
+		if source.UnitCost > 0 {
+			unitCost = source.UnitCost
+		}
+		return weight * unitCost, nil
+	}
+
+	var layers []models.CostLayer
+	err := tx.Clauses(
+		clause.Locking{Strength: "UPDATE"},
+		clause.OrderBy{Expression: clause.Expr{
+			SQL:  "(source_type = ? AND source_id = ?) DESC, id ASC",
+			Vars: []interface{}{source.SourceType, source.SourceID},
+		}},
+	).Where("warehouse_id = ? AND category_id = ? AND remaining_weight > 0", inventory.WarehouseID, inventory.CategoryID).
+		Find(&layers).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var cost float64
+	remaining := weight
+	for _, layer := range layers {
+		if remaining < weightTolerance {
+			break
+		}
+		take := math.Min(layer.RemainingWeight, remaining)
+		cost += take * layer.UnitCost
+		remaining -= take
+
+		if err := tx.Model(&models.CostLayer{}).Where("id = ?", layer.ID).Updates(map[string]interface{}{
+			"remaining_weight": layer.RemainingWeight - take,
+			"updated_at":       time.Now(),
+		}).Error; err != nil {
+			return 0, err
+		}
+	}
+	if remaining >= weightTolerance {
+		cost += remaining * inventory.AvgUnitCost
+	}
+	return cost, nil
+}
+
+// ChangeCostingMethod 切换分类的计价方法 (事务)
+// 切换为先进先出时以各仓库当前结存和平均成本建立期初成本层，切换为移动加权平均时清除成本层
+func (r *InventoryRepository) ChangeCostingMethod(categoryID uint, method string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var inventories []models.Inventory
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("category_id = ?", categoryID).
+			Order("warehouse_id").
+			Find(&inventories).Error
+		if err != nil {
+			return err
+		}
+
+		var category models.BatteryCategory
+		if err := tx.Select("id", "costing_method").First(&category, categoryID).Error; err != nil {
+			return err
+		}
+		if category.CostingMethod == method {
+			return nil
+		}
+
+		if err := tx.Where("category_id = ?", categoryID).Delete(&models.CostLayer{}).Error; err != nil {
+			return err
+		}
+		if method == models.CostingMethodFIFO {
+			for _, inventory := range inventories {
+				if inventory.CurrentWeightKg < weightTolerance {
+					continue
+				}
+				layer := models.CostLayer{
+					WarehouseID:     inventory.WarehouseID,
+					CategoryID:      categoryID,
+					SourceType:      models.MovementSourceAdjustment,
+					SourceID:        0,
+					OriginalWeight:  inventory.CurrentWeightKg,
+					RemainingWeight: inventory.CurrentWeightKg,
+					UnitCost:        inventory.AvgUnitCost,
+				}
+				if err := tx.Create(&layer).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		return tx.Model(&models.BatteryCategory{}).Where("id = ?", categoryID).Update("costing_method", method).Error
 	})
 }
+
+// GetSourceUnitCost 获取来源单据在该分类上最近一次出库流水的单位成本，没有时返回 0
+func (r *InventoryRepository) GetSourceUnitCost(categoryID uint, sourceType string, sourceID uint) (float64, error) {
+	var movement models.InventoryMovement
+	err := r.db.Where("category_id = ? AND source_type = ? AND source_id = ? AND delta < 0", categoryID, sourceType, sourceID).
+		Order("id DESC").
+		First(&movement).Error
+	if err == gorm.ErrRecordNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return movement.UnitCost, nil
+}
+
+// GetMovements 根据分类ID获取库存流水 (支持仓库、日期筛选和分页)
+func (r *InventoryRepository) GetMovements(categoryID uint, req *models.GetInventoryMovementsRequest) ([]models.InventoryMovement, int64, error) {
+	query := r.db.Model(&models.InventoryMovement{}).Where("category_id = ?", categoryID)
+
+	if req.WarehouseID != 0 {
+		query = query.Where("warehouse_id = ?", req.WarehouseID)
+	}
+	if req.StartDate != "" {
+		query = query.Where("created_at >= ?", req.StartDate)
+	}
+	if req.EndDate != "" {
+		query = query.Where("created_at < DATE_ADD(?, INTERVAL 1 DAY)", req.EndDate)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 期初流水补录在后，按发生时间排序
+	var movements []models.InventoryMovement
+	err := query.Order("created_at DESC, id DESC").
+		Offset((req.Page - 1) * req.PageSize).
+		Limit(req.PageSize).
+		Find(&movements).Error
+
+	return movements, total, err
+}
+
+// StockKey 库存记账维度: 仓库 + 分类
+type StockKey struct {
+	WarehouseID uint
+	CategoryID  uint
+}
+
+// SumMovementsByCategory 按仓库和分类汇总库存流水变动重量
+func (r *InventoryRepository) SumMovementsByCategory() (map[StockKey]float64, error) {
+	var rows []struct {
+		WarehouseID uint
+		CategoryID  uint
+		Total       float64
+	}
+	err := r.db.Model(&models.InventoryMovement{}).
+		Select("warehouse_id, category_id, SUM(delta) as total").
+		Group("warehouse_id, category_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	sums := make(map[StockKey]float64, len(rows))
+	for _, row := range rows {
+		sums[StockKey{WarehouseID: row.WarehouseID, CategoryID: row.CategoryID}] = row.Total
+	}
+	return sums, nil
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"battery-erp-backend/internal/models"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LotRepository 批次及批次分配数据仓库
+type LotRepository struct {
+	db *gorm.DB
+}
+
+// NewLotRepository 创建批次仓库实例
+func NewLotRepository(db *gorm.DB) *LotRepository {
+	return &LotRepository{db: db}
+}
+
+// Create 创建批次
+func (r *LotRepository) Create(lot *models.Lot) error {
+	return r.db.Create(lot).Error
+}
+
+// GetByID 根据ID获取批次
+func (r *LotRepository) GetByID(id uint) (*models.Lot, error) {
+	var lot models.Lot
+	err := r.db.First(&lot, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &lot, nil
+}
+
+// GetByIDForUpdate 根据ID获取批次并加行锁，需在事务中调用
+func (r *LotRepository) GetByIDForUpdate(id uint) (*models.Lot, error) {
+	var lot models.Lot
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&lot, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &lot, nil
+}
+
+// GetByInboundItemForUpdate 获取入库订单项生成的批次并加行锁，需在事务中调用
+func (r *LotRepository) GetByInboundItemForUpdate(itemID uint) (*models.Lot, error) {
+	var lot models.Lot
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("inbound_item_id = ? AND parent_lot_id IS NULL", itemID).
+		First(&lot).Error
+	if err != nil {
+		return nil, err
+	}
+	return &lot, nil
+}
+
+// GetByInboundOrderForUpdate 获取入库订单生成的批次 (不含调拨子批次) 并加行锁，需在事务中调用
+func (r *LotRepository) GetByInboundOrderForUpdate(orderID uint) ([]models.Lot, error) {
+	var lots []models.Lot
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("inbound_order_id = ? AND parent_lot_id IS NULL", orderID).
+		Order("id").
+		Find(&lots).Error
+	return lots, err
+}
+
+// GetAvailableForUpdate 按先进先出顺序获取仓库中分类有剩余重量的批次并加行锁，需在事务中调用
+func (r *LotRepository) GetAvailableForUpdate(warehouseID, categoryID uint) ([]models.Lot, error) {
+	var lots []models.Lot
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("warehouse_id = ? AND category_id = ? AND remaining_weight > 0", warehouseID, categoryID).
+		Order("received_at, id").
+		Find(&lots).Error
+	return lots, err
+}
+
+// GetChildren 获取调拨生成的子批次
+func (r *LotRepository) GetChildren(parentID uint) ([]models.Lot, error) {
+	var lots []models.Lot
+	err := r.db.Where("parent_lot_id = ?", parentID).Order("id").Find(&lots).Error
+	return lots, err
+}
+
+// GetAll 分页获取批次
+func (r *LotRepository) GetAll(req *models.GetLotsRequest) ([]models.Lot, int64, error) {
+	query := r.db.Model(&models.Lot{})
+	if req.WarehouseID != 0 {
+		query = query.Where("warehouse_id = ?", req.WarehouseID)
+	}
+	if req.CategoryID != 0 {
+		query = query.Where("category_id = ?", req.CategoryID)
+	}
+	if req.InboundOrderID != 0 {
+		query = query.Where("inbound_order_id = ?", req.InboundOrderID)
+	}
+	if req.SellerID != 0 {
+		query = query.Where("seller_id = ?", req.SellerID)
+	}
+	if req.Available {
+		query = query.Where("remaining_weight > 0")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var lots []models.Lot
+	err := query.Order("received_at DESC, id DESC").
+		Offset((req.Page - 1) * req.PageSize).
+		Limit(req.PageSize).
+		Find(&lots).Error
+
+	return lots, total, err
+}
+
+// UpdateFields 显式更新批次字段
+func (r *LotRepository) UpdateFields(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&models.Lot{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// Delete 删除批次
+func (r *LotRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Lot{}, id).Error
+}
+
+// CreateAllocation 创建批次分配
+func (r *LotRepository) CreateAllocation(allocation *models.LotAllocation) error {
+	return r.db.Create(allocation).Error
+}
+
+// GetAllocations 获取来源单据的批次分配
+func (r *LotRepository) GetAllocations(sourceType string, sourceID uint) ([]models.LotAllocation, error) {
+	var allocations []models.LotAllocation
+	err := r.db.Where("source_type = ? AND source_id = ?", sourceType, sourceID).
+		Order("id").
+		Find(&allocations).Error
+	return allocations, err
+}
+
+// UpdateAllocationFields 显式更新批次分配字段
+func (r *LotRepository) UpdateAllocationFields(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&models.LotAllocation{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// DeleteAllocations 删除来源单据的全部批次分配
+func (r *LotRepository) DeleteAllocations(sourceType string, sourceID uint) error {
+	return r.db.Where("source_type = ? AND source_id = ?", sourceType, sourceID).
+		Delete(&models.LotAllocation{}).Error
+}
+
+// GetConsumptions 获取批次已记账的消耗明细及对应单据号
+func (r *LotRepository) GetConsumptions(lotID uint) ([]models.LotConsumption, error) {
+	var consumptions []models.LotConsumption
+	err := r.db.Table("lot_allocations as a").
+		Select(`
+			a.lot_id,
+			l.lot_no,
+			a.source_type,
+			a.source_id,
+			a.source_item_id,
+			COALESCE(o.order_no, t.transfer_no, adj.adjustment_no, st.stocktake_no, '') as document_no,
+			COALESCE(o.customer_name, '') as counterparty,
+			a.weight,
+			a.created_at
+		`).
+		Joins("JOIN lots l ON l.id = a.lot_id").
+		Joins("LEFT JOIN outbound_orders o ON a.source_type = ? AND o.id = a.source_id", models.MovementSourceOutbound).
+		Joins("LEFT JOIN transfer_orders t ON a.source_type = ? AND t.id = a.source_id", models.MovementSourceTransferOut).
+		Joins("LEFT JOIN inventory_adjustments adj ON a.source_type = ? AND adj.id = a.source_id", models.MovementSourceAdjustment).
+		Joins("LEFT JOIN stocktakes st ON a.source_type = ? AND st.id = a.source_id", models.MovementSourceStocktake).
+		Where("a.lot_id = ? AND a.posted = ?", lotID, true).
+		Order("a.id").
+		Scan(&consumptions).Error
+	return consumptions, err
+}
+
+// GetShipmentTrace 获取出库订单已记账消耗的批次及其来源入库订单
+func (r *LotRepository) GetShipmentTrace(outboundOrderID uint) ([]models.ShipmentLotTrace, error) {
+	var traces []models.ShipmentLotTrace
+	err := r.db.Table("lot_allocations as a").
+		Select(`
+			a.source_id as outbound_order_id,
+			a.source_item_id as outbound_item_id,
+			l.category_id,
+			l.id as lot_id,
+			l.lot_no,
+			a.weight,
+			l.inbound_order_id,
+			COALESCE(io.order_no, '') as inbound_order_no,
+			l.supplier_name,
+			l.grade,
+			l.received_at
+		`).
+		Joins("JOIN lots l ON l.id = a.lot_id").
+		Joins("LEFT JOIN inbound_orders io ON io.id = l.inbound_order_id").
+		Where("a.source_type = ? AND a.source_id = ? AND a.posted = ?", models.MovementSourceOutbound, outboundOrderID, true).
+		Order("a.source_item_id, a.id").
+		Scan(&traces).Error
+	return traces, err
+}
+
+// GenerateLotNo 生成批次号
+func (r *LotRepository) GenerateLotNo() (string, error) {
+	now := time.Now()
+
+	// 格式：LOT-20240101-123456789-1234
+	lotNo := fmt.Sprintf("LOT-%s-%05d-%04d", now.Format("20060102"), now.Nanosecond(), rand.Intn(10000))
+	return lotNo, nil
+}
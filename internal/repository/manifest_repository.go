@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"battery-erp-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ManifestRepository 危险废物转移联单数据仓库
+type ManifestRepository struct {
+	db *gorm.DB
+}
+
+// NewManifestRepository 创建联单仓库实例
+func NewManifestRepository(db *gorm.DB) *ManifestRepository {
+	return &ManifestRepository{db: db}
+}
+
+// Create 创建联单
+func (r *ManifestRepository) Create(manifest *models.WasteManifest) error {
+	return r.db.Create(manifest).Error
+}
+
+// CreateItems 批量创建联单废物明细
+func (r *ManifestRepository) CreateItems(items []models.WasteManifestItem) error {
+	return r.db.Create(&items).Error
+}
+
+// GetByID 根据ID获取联单
+func (r *ManifestRepository) GetByID(id uint) (*models.WasteManifest, error) {
+	var manifest models.WasteManifest
+	err := r.db.First(&manifest, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// GetByIDForUpdate 根据ID获取联单并加行锁，需在事务中调用
+func (r *ManifestRepository) GetByIDForUpdate(id uint) (*models.WasteManifest, error) {
+	var manifest models.WasteManifest
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&manifest, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// GetByOutboundOrderID 获取出库订单的联单
+func (r *ManifestRepository) GetByOutboundOrderID(orderID uint) (*models.WasteManifest, error) {
+	var manifest models.WasteManifest
+	err := r.db.Where("outbound_order_id = ?", orderID).First(&manifest).Error
+	if err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// GetAll 分页获取联单
+func (r *ManifestRepository) GetAll(req *models.GetWasteManifestsRequest) ([]models.WasteManifest, int64, error) {
+	query := r.db.Model(&models.WasteManifest{})
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.StartDate != "" {
+		query = query.Where("issued_at >= ?", req.StartDate)
+	}
+	if req.EndDate != "" {
+		query = query.Where("issued_at < DATE_ADD(?, INTERVAL 1 DAY)", req.EndDate)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var manifests []models.WasteManifest
+	err := query.Order("id DESC").
+		Offset((req.Page - 1) * req.PageSize).
+		Limit(req.PageSize).
+		Find(&manifests).Error
+
+	return manifests, total, err
+}
+
+// GetItems 获取联单废物明细
+func (r *ManifestRepository) GetItems(manifestID uint) ([]models.WasteManifestItem, error) {
+	var items []models.WasteManifestItem
+	err := r.db.Where("manifest_id = ?", manifestID).Order("id").Find(&items).Error
+	return items, err
+}
+
+// UpdateFields 显式更新联单字段
+func (r *ManifestRepository) UpdateFields(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&models.WasteManifest{}).Where("id = ?", id).Updates(updates).Error
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // 初始化随机数种子
@@ -49,6 +50,16 @@ func (r *OutboundRepository) GetByID(id uint) (*models.OutboundOrder, error) {
 	return &order, nil
 }
 
+// GetByIDForUpdate 根据ID获取出库订单并加行锁，需在事务中调用
+func (r *OutboundRepository) GetByIDForUpdate(id uint) (*models.OutboundOrder, error) {
+	var order models.OutboundOrder
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&order).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
 // GetAll 获取所有出库订单 (分页)
 func (r *OutboundRepository) GetAll(limit, offset int) ([]models.OutboundOrder, int64, error) {
 	var orders []models.OutboundOrder
@@ -90,9 +101,9 @@ func (r *OutboundRepository) UpdateFields(id uint, updates map[string]interface{
 	return r.db.Model(&models.OutboundOrder{}).Where("id = ?", id).Updates(updates).Error
 }
 
-// Delete 删除出库订单
+// Delete 删除出库订单 (软删除，保留订单项用于追溯)
 func (r *OutboundRepository) Delete(id uint) error {
-	return r.db.Delete(&models.OutboundOrder{}, id).Error
+	return r.db.Model(&models.OutboundOrder{}).Where("id = ?", id).Update("is_deleted", 1).Error
 }
 
 // GetItemsByOrderID 根据订单ID获取出库订单详细条目 (包含分类名称)
@@ -105,6 +116,7 @@ func (r *OutboundRepository) GetItemsByOrderID(orderID uint) ([]models.OutboundO
 			COALESCE(c.name, '未知分类') as category_name,
 			o.weight,
 			o.unit_price,
+			o.list_price,
 			o.sub_total
 		`).
 		Joins("LEFT JOIN battery_categories c ON o.category_id = c.id").
@@ -122,6 +134,21 @@ func (r *OutboundRepository) GetAllWithConditions(req *models.GetOutboundOrderRe
 	if req.Customer != "" {
 		query = query.Where("customer_name LIKE ?", "%"+req.Customer+"%")
 	}
+	if req.CustomerID != 0 {
+		query = query.Where("customer_id = ?", req.CustomerID)
+	}
+	if req.WarehouseID != 0 {
+		query = query.Where("warehouse_id = ?", req.WarehouseID)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.PriceFlagged {
+		query = query.Where("price_flagged = ?", true)
+	}
+	if req.PaymentStatus != "" {
+		query = query.Where("payment_status = ?", req.PaymentStatus)
+	}
 	if req.StartDate != "" && req.EndDate != "" {
 		query = query.Where("created_at >= ? AND created_at <= ?", req.StartDate, req.EndDate)
 	}
@@ -129,6 +156,8 @@ func (r *OutboundRepository) GetAllWithConditions(req *models.GetOutboundOrderRe
 		query = query.Where("created_at >= ?", req.StartDate)
 	}
 
+	query = query.Where("is_deleted = 0")
+
 	// 获取总数
 	var total int64
 	if err := query.Count(&total).Error; err != nil {
@@ -153,6 +182,62 @@ func (r *OutboundRepository) GetAllWithConditions(req *models.GetOutboundOrderRe
 	return orders, total, err
 }
 
+// GetCustomerTotals 汇总客户已记账订单 (已确认/已完成) 的出库重量和金额
+func (r *OutboundRepository) GetCustomerTotals(customerID uint, startDate, endDate string) (float64, float64, error) {
+	postedOrders := func(db *gorm.DB) *gorm.DB {
+		db = db.Where("o.customer_id = ? AND o.is_deleted = 0 AND o.status IN ?", customerID,
+			[]string{models.OrderStatusConfirmed, models.OrderStatusCompleted})
+		if startDate != "" {
+			db = db.Where("o.created_at >= ?", startDate)
+		}
+		if endDate != "" {
+			db = db.Where("o.created_at < DATE_ADD(?, INTERVAL 1 DAY)", endDate)
+		}
+		return db
+	}
+
+	var totalAmount float64
+	err := r.db.Table("outbound_orders as o").
+		Scopes(postedOrders).
+		Select("COALESCE(SUM(o.total_amount), 0)").
+		Scan(&totalAmount).Error
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var totalWeight float64
+	err = r.db.Table("outbound_order_items as i").
+		Joins("JOIN outbound_orders o ON o.id = i.order_id").
+		Scopes(postedOrders).
+		Select("COALESCE(SUM(i.weight), 0)").
+		Scan(&totalWeight).Error
+
+	return totalWeight, totalAmount, err
+}
+
+// GetCustomerReceivableTotals 汇总客户已记账出库订单的应收金额和已收金额
+func (r *OutboundRepository) GetCustomerReceivableTotals(customerID uint) (float64, float64, error) {
+	var result struct {
+		TotalAmount    float64
+		ReceivedAmount float64
+	}
+	err := r.db.Model(&models.OutboundOrder{}).
+		Select("COALESCE(SUM(total_amount), 0) as total_amount, COALESCE(SUM(received_amount), 0) as received_amount").
+		Where("customer_id = ? AND is_deleted = 0 AND status IN ?", customerID, []string{models.OrderStatusConfirmed, models.OrderStatusCompleted}).
+		Scan(&result).Error
+	return result.TotalAmount, result.ReceivedAmount, err
+}
+
+// GetUnpaidByCustomer 获取客户未收清的已记账出库订单，按创建时间升序
+func (r *OutboundRepository) GetUnpaidByCustomer(customerID uint) ([]models.OutboundOrder, error) {
+	var orders []models.OutboundOrder
+	err := r.db.Where("customer_id = ? AND is_deleted = 0 AND status IN ?", customerID, []string{models.OrderStatusConfirmed, models.OrderStatusCompleted}).
+		Where("payment_status <> ?", models.OrderPaymentPaid).
+		Order("created_at ASC").
+		Find(&orders).Error
+	return orders, err
+}
+
 // GenerateOrderNo 生成订单号 (并发安全：纳秒时间戳+随机数)
 func (r *OutboundRepository) GenerateOrderNo() (string, error) {
 	now := time.Now()
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"battery-erp-backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SupplierPaymentRepository 供应商付款数据仓库
+type SupplierPaymentRepository struct {
+	db *gorm.DB
+}
+
+// NewSupplierPaymentRepository 创建供应商付款仓库实例
+func NewSupplierPaymentRepository(db *gorm.DB) *SupplierPaymentRepository {
+	return &SupplierPaymentRepository{db: db}
+}
+
+// Create 创建付款单
+func (r *SupplierPaymentRepository) Create(payment *models.SupplierPayment) error {
+	return r.db.Create(payment).Error
+}
+
+// CreateAllocation 创建付款分摊
+func (r *SupplierPaymentRepository) CreateAllocation(allocation *models.SupplierPaymentAllocation) error {
+	return r.db.Create(allocation).Error
+}
+
+// GetByID 根据ID获取付款单
+func (r *SupplierPaymentRepository) GetByID(id uint) (*models.SupplierPayment, error) {
+	var payment models.SupplierPayment
+	if err := r.db.First(&payment, id).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// GetByIDForUpdate 根据ID获取付款单并加行锁，需在事务中调用
+func (r *SupplierPaymentRepository) GetByIDForUpdate(id uint) (*models.SupplierPayment, error) {
+	var payment models.SupplierPayment
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&payment, id).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// GetAllocations 获取付款单的分摊明细
+func (r *SupplierPaymentRepository) GetAllocations(paymentID uint) ([]models.SupplierPaymentAllocation, error) {
+	var allocations []models.SupplierPaymentAllocation
+	err := r.db.Where("payment_id = ?", paymentID).Order("id ASC").Find(&allocations).Error
+	return allocations, err
+}
+
+// GetAll 分页获取付款单
+func (r *SupplierPaymentRepository) GetAll(req *models.GetSupplierPaymentsRequest) ([]models.SupplierPayment, int64, error) {
+	query := r.db.Model(&models.SupplierPayment{})
+	if req.SellerID != 0 {
+		query = query.Where("seller_id = ?", req.SellerID)
+	}
+	if req.Supplier != "" {
+		query = query.Where("supplier_name LIKE ?", "%"+req.Supplier+"%")
+	}
+	if req.Method != "" {
+		query = query.Where("method = ?", req.Method)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.StartDate != "" {
+		query = query.Where("paid_at >= ?", req.StartDate)
+	}
+	if req.EndDate != "" {
+		query = query.Where("paid_at < DATE_ADD(?, INTERVAL 1 DAY)", req.EndDate)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var payments []models.SupplierPayment
+	err := query.Order("paid_at DESC, id DESC").
+		Offset((req.Page - 1) * req.PageSize).
+		Limit(req.PageSize).
+		Find(&payments).Error
+
+	return payments, total, err
+}
+
+// UpdateFields 显式更新付款单字段
+func (r *SupplierPaymentRepository) UpdateFields(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&models.SupplierPayment{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// LinkSupplierToSeller 将指定供应商名称的未关联付款单关联到卖家
+func (r *SupplierPaymentRepository) LinkSupplierToSeller(supplierName string, sellerID uint) error {
+	return r.db.Model(&models.SupplierPayment{}).
+		Where("seller_id IS NULL AND supplier_name = ?", supplierName).
+		Update("seller_id", sellerID).Error
+}
+
+// GetLastPaymentAt 获取卖家最近一次有效付款的付款日期
+func (r *SupplierPaymentRepository) GetLastPaymentAt(sellerID uint) (*time.Time, error) {
+	var payments []models.SupplierPayment
+	err := r.db.Where("seller_id = ? AND status = ?", sellerID, models.PaymentStatusPosted).
+		Order("paid_at DESC").
+		Limit(1).
+		Find(&payments).Error
+	if err != nil || len(payments) == 0 {
+		return nil, err
+	}
+	return &payments[0].PaidAt, nil
+}
+
+// GetOpenPayables 获取截止日期仍未付清的已记账入库订单
+// 订单取截止日期当天及以前创建的，已付金额只计截止日期及以前的有效付款
+func (r *SupplierPaymentRepository) GetOpenPayables(asOf string) ([]models.OpenOrderBalance, error) {
+	var balances []models.OpenOrderBalance
+	err := r.db.Table("inbound_orders as o").
+		Select(`
+			o.id as order_id,
+			o.order_no,
+			o.seller_id as party_id,
+			o.supplier_name as party_name,
+			o.created_at,
+			o.total_amount,
+			o.total_amount - COALESCE((
+				SELECT SUM(a.amount) FROM supplier_payment_allocations a
+				JOIN supplier_payments p ON p.id = a.payment_id
+				WHERE a.inbound_order_id = o.id AND p.status = ? AND p.paid_at < DATE_ADD(?, INTERVAL 1 DAY)
+			), 0) as outstanding
+		`, models.PaymentStatusPosted, asOf).
+		Where("o.is_deleted = 0 AND o.status IN ?", []string{models.OrderStatusConfirmed, models.OrderStatusCompleted}).
+		Where("o.created_at < DATE_ADD(?, INTERVAL 1 DAY)", asOf).
+		Having("outstanding > 0.005").
+		Order("o.created_at ASC").
+		Scan(&balances).Error
+	return balances, err
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"battery-erp-backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PriceRepository 分类价格历史数据仓库
+type PriceRepository struct {
+	db *gorm.DB
+}
+
+// NewPriceRepository 创建价格仓库实例
+func NewPriceRepository(db *gorm.DB) *PriceRepository {
+	return &PriceRepository{db: db}
+}
+
+// Create 新增价格记录
+func (r *PriceRepository) Create(price *models.CategoryPrice) error {
+	return r.db.Create(price).Error
+}
+
+// GetByCategory 获取分类的价格历史，priceType 为空时返回全部类型，按生效时间倒序
+func (r *PriceRepository) GetByCategory(categoryID uint, priceType string) ([]models.CategoryPrice, error) {
+	query := r.db.Where("category_id = ?", categoryID)
+	if priceType != "" {
+		query = query.Where("price_type = ?", priceType)
+	}
+
+	var prices []models.CategoryPrice
+	err := query.Order("effective_from DESC, id DESC").Find(&prices).Error
+	return prices, err
+}
+
+// GetEffective 获取分类在指定时间有效的价格，同一生效时间以后录入的为准
+func (r *PriceRepository) GetEffective(categoryID uint, priceType string, at time.Time) (*models.CategoryPrice, error) {
+	var price models.CategoryPrice
+	err := r.db.Where("category_id = ? AND price_type = ? AND effective_from <= ?", categoryID, priceType, at).
+		Order("effective_from DESC, id DESC").
+		First(&price).Error
+	if err != nil {
+		return nil, err
+	}
+	return &price, nil
+}
+
+// GetAllEffective 获取所有分类在指定时间有效的收购价和销售价
+func (r *PriceRepository) GetAllEffective(at time.Time) ([]models.CategoryPrice, error) {
+	var prices []models.CategoryPrice
+	err := r.db.Table("category_prices AS p").
+		Where(`p.id = (
+			SELECT p2.id FROM category_prices p2
+			WHERE p2.category_id = p.category_id AND p2.price_type = p.price_type AND p2.effective_from <= ?
+			ORDER BY p2.effective_from DESC, p2.id DESC
+			LIMIT 1
+		)`, at).
+		Find(&prices).Error
+	return prices, err
+}
+
+// HasPrices 分类是否已有指定类型的价格记录
+func (r *PriceRepository) HasPrices(categoryID uint, priceType string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.CategoryPrice{}).
+		Where("category_id = ? AND price_type = ?", categoryID, priceType).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"battery-erp-backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CustomerReceiptRepository 客户收款数据仓库
+type CustomerReceiptRepository struct {
+	db *gorm.DB
+}
+
+// NewCustomerReceiptRepository 创建客户收款仓库实例
+func NewCustomerReceiptRepository(db *gorm.DB) *CustomerReceiptRepository {
+	return &CustomerReceiptRepository{db: db}
+}
+
+// Create 创建收款单
+func (r *CustomerReceiptRepository) Create(receipt *models.CustomerReceipt) error {
+	return r.db.Create(receipt).Error
+}
+
+// CreateAllocation 创建收款核销
+func (r *CustomerReceiptRepository) CreateAllocation(allocation *models.CustomerReceiptAllocation) error {
+	return r.db.Create(allocation).Error
+}
+
+// GetByID 根据ID获取收款单
+func (r *CustomerReceiptRepository) GetByID(id uint) (*models.CustomerReceipt, error) {
+	var receipt models.CustomerReceipt
+	if err := r.db.First(&receipt, id).Error; err != nil {
+		return nil, err
+	}
+	return &receipt, nil
+}
+
+// GetByIDForUpdate 根据ID获取收款单并加行锁，需在事务中调用
+func (r *CustomerReceiptRepository) GetByIDForUpdate(id uint) (*models.CustomerReceipt, error) {
+	var receipt models.CustomerReceipt
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&receipt, id).Error; err != nil {
+		return nil, err
+	}
+	return &receipt, nil
+}
+
+// GetAllocations 获取收款单的核销明细
+func (r *CustomerReceiptRepository) GetAllocations(receiptID uint) ([]models.CustomerReceiptAllocation, error) {
+	var allocations []models.CustomerReceiptAllocation
+	err := r.db.Where("receipt_id = ?", receiptID).Order("id ASC").Find(&allocations).Error
+	return allocations, err
+}
+
+// GetAll 分页获取收款单
+func (r *CustomerReceiptRepository) GetAll(req *models.GetCustomerReceiptsRequest) ([]models.CustomerReceipt, int64, error) {
+	query := r.db.Model(&models.CustomerReceipt{})
+	if req.CustomerID != 0 {
+		query = query.Where("customer_id = ?", req.CustomerID)
+	}
+	if req.Customer != "" {
+		query = query.Where("customer_name LIKE ?", "%"+req.Customer+"%")
+	}
+	if req.Method != "" {
+		query = query.Where("method = ?", req.Method)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.StartDate != "" {
+		query = query.Where("received_at >= ?", req.StartDate)
+	}
+	if req.EndDate != "" {
+		query = query.Where("received_at < DATE_ADD(?, INTERVAL 1 DAY)", req.EndDate)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var receipts []models.CustomerReceipt
+	err := query.Order("received_at DESC, id DESC").
+		Offset((req.Page - 1) * req.PageSize).
+		Limit(req.PageSize).
+		Find(&receipts).Error
+
+	return receipts, total, err
+}
+
+// UpdateFields 显式更新收款单字段
+func (r *CustomerReceiptRepository) UpdateFields(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&models.CustomerReceipt{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// GetLastReceiptAt 获取客户最近一次有效收款的收款日期
+func (r *CustomerReceiptRepository) GetLastReceiptAt(customerID uint) (*time.Time, error) {
+	var receipts []models.CustomerReceipt
+	err := r.db.Where("customer_id = ? AND status = ?", customerID, models.PaymentStatusPosted).
+		Order("received_at DESC").
+		Limit(1).
+		Find(&receipts).Error
+	if err != nil || len(receipts) == 0 {
+		return nil, err
+	}
+	return &receipts[0].ReceivedAt, nil
+}
+
+// GetOpenReceivables 获取截止日期仍未收清的已记账出库订单
+// 订单取截止日期当天及以前创建的，已收金额只计截止日期及以前的有效收款
+func (r *CustomerReceiptRepository) GetOpenReceivables(asOf string) ([]models.OpenOrderBalance, error) {
+	var balances []models.OpenOrderBalance
+	err := r.db.Table("outbound_orders as o").
+		Select(`
+			o.id as order_id,
+			o.order_no,
+			o.customer_id as party_id,
+			o.customer_name as party_name,
+			o.created_at,
+			o.total_amount,
+			o.total_amount - COALESCE((
+				SELECT SUM(a.amount) FROM customer_receipt_allocations a
+				JOIN customer_receipts p ON p.id = a.receipt_id
+				WHERE a.outbound_order_id = o.id AND p.status = ? AND p.received_at < DATE_ADD(?, INTERVAL 1 DAY)
+			), 0) as outstanding
+		`, models.PaymentStatusPosted, asOf).
+		Where("o.is_deleted = 0 AND o.status IN ?", []string{models.OrderStatusConfirmed, models.OrderStatusCompleted}).
+		Where("o.created_at < DATE_ADD(?, INTERVAL 1 DAY)", asOf).
+		Having("outstanding > 0.005").
+		Order("o.created_at ASC").
+		Scan(&balances).Error
+	return balances, err
+}
@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"battery-erp-backend/internal/models"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// orderTables 入库/出库订单统计所需的表和字段
+type orderTables struct {
+	orders string // 订单表
+	items  string // 订单项表
+	weight string // 订单项重量字段
+}
+
+var (
+	inboundTables  = orderTables{orders: "inbound_orders", items: "inbound_order_items", weight: "net_weight"}
+	outboundTables = orderTables{orders: "outbound_orders", items: "outbound_order_items", weight: "weight"}
+)
+
+// trendPeriodFormats 趋势报表时间桶对应的 MySQL DATE_FORMAT 格式 (周为 ISO 周)
+var trendPeriodFormats = map[string]string{
+	"day":   "%Y-%m-%d",
+	"week":  "%x-W%v",
+	"month": "%Y-%m",
+}
+
+// ReportRepository 报表统计数据仓库，统计均通过 SQL 分组汇总完成
+type ReportRepository struct {
+	db          *gorm.DB
+	warehouseID uint // 非 0 时仅统计该仓库
+}
+
+// NewReportRepository 创建报表仓库实例
+func NewReportRepository(db *gorm.DB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+// WithWarehouse 返回仅统计指定仓库的报表仓库，warehouseID 为 0 时统计全部仓库
+func (r *ReportRepository) WithWarehouse(warehouseID uint) *ReportRepository {
+	return &ReportRepository{db: r.db, warehouseID: warehouseID}
+}
+
+// GetInboundStats 统计时间范围内已记账入库订单
+func (r *ReportRepository) GetInboundStats(start, end time.Time) (*models.OrderStats, error) {
+	return r.getOrderStats(inboundTables, start, end)
+}
+
+// GetOutboundStats 统计时间范围内已记账出库订单
+func (r *ReportRepository) GetOutboundStats(start, end time.Time) (*models.OrderStats, error) {
+	return r.getOrderStats(outboundTables, start, end)
+}
+
+// GetInboundCategoryStats 按分类统计时间范围内已记账入库订单，start 为零值时统计截至 end 的全部入库
+func (r *ReportRepository) GetInboundCategoryStats(start, end time.Time) ([]models.CategoryStats, error) {
+	return r.getCategoryStats(inboundTables, start, end)
+}
+
+// GetOutboundCategoryStats 按分类统计时间范围内已记账出库订单
+func (r *ReportRepository) GetOutboundCategoryStats(start, end time.Time) ([]models.CategoryStats, error) {
+	return r.getCategoryStats(outboundTables, start, end)
+}
+
+// GetInboundTrend 按时间桶和分类汇总已记账入库订单净重和金额
+func (r *ReportRepository) GetInboundTrend(granularity string, start, end time.Time, categoryID uint) ([]models.TrendRow, error) {
+	return r.getTrend(inboundTables, granularity, start, end, categoryID)
+}
+
+// GetOutboundTrend 按时间桶和分类汇总已记账出库订单重量和金额
+func (r *ReportRepository) GetOutboundTrend(granularity string, start, end time.Time, categoryID uint) ([]models.TrendRow, error) {
+	return r.getTrend(outboundTables, granularity, start, end, categoryID)
+}
+
+// postedItems 已记账 (已确认/已完成且未删除) 订单在 [start, end) 内的订单项，start 为零值时不限开始时间
+func (r *ReportRepository) postedItems(t orderTables, start, end time.Time) *gorm.DB {
+	query := r.db.Table(t.items+" as i").
+		Joins("JOIN "+t.orders+" o ON o.id = i.order_id").
+		Where("o.is_deleted = 0 AND o.status IN ?", []string{models.OrderStatusConfirmed, models.OrderStatusCompleted}).
+		Where("o.created_at < ?", end)
+	if !start.IsZero() {
+		query = query.Where("o.created_at >= ?", start)
+	}
+	if r.warehouseID != 0 {
+		query = query.Where("o.warehouse_id = ?", r.warehouseID)
+	}
+	return query
+}
+
+func (r *ReportRepository) getOrderStats(t orderTables, start, end time.Time) (*models.OrderStats, error) {
+	var stats models.OrderStats
+	err := r.postedItems(t, start, end).
+		Select(`
+			COUNT(DISTINCT o.id) as total_orders,
+			COALESCE(SUM(i.sub_total), 0) as total_amount,
+			COALESCE(SUM(i.` + t.weight + `), 0) as total_weight
+		`).
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if stats.TotalOrders > 0 {
+		stats.AvgAmount = stats.TotalAmount / float64(stats.TotalOrders)
+	}
+	return &stats, nil
+}
+
+func (r *ReportRepository) getCategoryStats(t orderTables, start, end time.Time) ([]models.CategoryStats, error) {
+	var result []models.CategoryStats
+	err := r.postedItems(t, start, end).
+		Select(`
+			i.category_id,
+			COALESCE(c.name, '未知分类') as category_name,
+			COUNT(DISTINCT o.id) as order_count,
+			COALESCE(SUM(i.` + t.weight + `), 0) as total_weight,
+			COALESCE(SUM(i.sub_total), 0) as total_amount
+		`).
+		Joins("LEFT JOIN battery_categories c ON i.category_id = c.id").
+		Group("i.category_id, c.name").
+		Order("i.category_id").
+		Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range result {
+		if result[i].TotalWeight > 0 {
+			result[i].AvgUnitPrice = result[i].TotalAmount / result[i].TotalWeight
+		}
+	}
+	return result, nil
+}
+
+func (r *ReportRepository) getTrend(t orderTables, granularity string, start, end time.Time, categoryID uint) ([]models.TrendRow, error) {
+	format, ok := trendPeriodFormats[granularity]
+	if !ok {
+		return nil, fmt.Errorf("unsupported granularity: %s", granularity)
+	}
+	period := "DATE_FORMAT(o.created_at, '" + format + "')"
+
+	query := r.postedItems(t, start, end).
+		Select(period + ` as period,
+			i.category_id,
+			COALESCE(c.name, '未知分类') as category_name,
+			COALESCE(SUM(i.` + t.weight + `), 0) as weight,
+			COALESCE(SUM(i.sub_total), 0) as amount
+		`).
+		Joins("LEFT JOIN battery_categories c ON i.category_id = c.id")
+	if categoryID != 0 {
+		query = query.Where("i.category_id = ?", categoryID)
+	}
+
+	var rows []models.TrendRow
+	err := query.Group(period + ", i.category_id, c.name").
+		Order("period, i.category_id").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// CategoryIssueCost 分类出库流水的出库重量和实际成本
+type CategoryIssueCost struct {
+	CategoryID uint
+	Weight     float64 // 出库重量 kg (扣除退回)
+	CostAmount float64 // 出库成本 (扣除退回)
+}
+
+// GetOutboundIssueCosts 按分类汇总 [start, end) 内已记账出库订单的出库流水成本
+// 只统计记录了成本的流水，引入库存成本前的出库不计入
+func (r *ReportRepository) GetOutboundIssueCosts(start, end time.Time) ([]CategoryIssueCost, error) {
+	query := r.db.Table("inventory_movements as m").
+		Select("m.category_id, -SUM(m.delta) as weight, -SUM(m.cost_amount) as cost_amount").
+		Joins("JOIN outbound_orders o ON o.id = m.source_id").
+		Where("m.source_type = ? AND m.cost_amount <> 0", models.MovementSourceOutbound).
+		Where("o.is_deleted = 0 AND o.status IN ?", []string{models.OrderStatusConfirmed, models.OrderStatusCompleted}).
+		Where("o.created_at < ?", end)
+	if !start.IsZero() {
+		query = query.Where("o.created_at >= ?", start)
+	}
+	if r.warehouseID != 0 {
+		query = query.Where("o.warehouse_id = ?", r.warehouseID)
+	}
+
+	var costs []CategoryIssueCost
+	err := query.Group("m.category_id").Scan(&costs).Error
+	return costs, err
+}
+
+// GetInventoryValuation 按仓库和分类取 end 之前最后一条库存流水的结存重量和金额，再按分类合计
+// 最后一条按发生时间判断 (期初流水补录在后，ID 较大但发生时间最早)，同一时间取 ID 较大的
+func (r *ReportRepository) GetInventoryValuation(end time.Time) ([]models.InventoryValuation, error) {
+	query := r.db.Table("inventory_movements as m").
+		Select(`
+			m.category_id,
+			c.name as category_name,
+			c.costing_method,
+			SUM(m.balance_after) as weight_kg,
+			SUM(m.value_after) as total_value
+		`).
+		Joins("JOIN battery_categories c ON c.id = m.category_id").
+		Where("m.created_at < ?", end).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM inventory_movements n
+			WHERE n.warehouse_id = m.warehouse_id AND n.category_id = m.category_id AND n.created_at < ?
+			AND (n.created_at > m.created_at OR (n.created_at = m.created_at AND n.id > m.id))
+		)`, end)
+	if r.warehouseID != 0 {
+		query = query.Where("m.warehouse_id = ?", r.warehouseID)
+	}
+
+	var valuations []models.InventoryValuation
+	err := query.Group("m.category_id, c.name, c.costing_method").
+		Order("m.category_id").
+		Scan(&valuations).Error
+	return valuations, err
+}
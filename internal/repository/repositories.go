@@ -2,42 +2,298 @@ package repository
 
 import (
 	"battery-erp-backend/internal/models"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Repositories holds all repository instances (no interfaces)
 type Repositories struct {
-	UserRepo      *UserRepository
-	CategoryRepo  *CategoryRepository
-	InboundRepo   *InboundRepository
-	OutboundRepo  *OutboundRepository
-	InventoryRepo *InventoryRepository
-	SellerRepo    *SellerRepository
-	DB            *gorm.DB
+	UserRepo        *UserRepository
+	CategoryRepo    *CategoryRepository
+	InboundRepo     *InboundRepository
+	OutboundRepo    *OutboundRepository
+	InventoryRepo   *InventoryRepository
+	SellerRepo      *SellerRepository
+	CustomerRepo    *CustomerRepository
+	ReportRepo      *ReportRepository
+	StocktakeRepo   *StocktakeRepository
+	AdjustmentRepo  *AdjustmentRepository
+	WarehouseRepo   *WarehouseRepository
+	TransferRepo    *TransferRepository
+	LotRepo         *LotRepository
+	TraceCodeRepo   *TraceCodeRepository
+	SequenceRepo    *SequenceRepository
+	ManifestRepo    *ManifestRepository
+	PriceRepo       *PriceRepository
+	ScaleRepo       *ScaleCaptureRepository
+	WeighTicketRepo *WeighTicketRepository
+	VehicleRepo     *VehicleRepository
+	PaymentRepo     *SupplierPaymentRepository
+	ReceiptRepo     *CustomerReceiptRepository
+	DB              *gorm.DB
 }
 
 // NewRepositories creates a new repositories instance
 func NewRepositories(db *gorm.DB) *Repositories {
 	return &Repositories{
-		UserRepo:      NewUserRepository(db),
-		CategoryRepo:  NewCategoryRepository(db),
-		InboundRepo:   NewInboundRepository(db),
-		OutboundRepo:  NewOutboundRepository(db),
-		InventoryRepo: NewInventoryRepository(db),
-		SellerRepo:    NewSellerRepository(db),
-		DB:            db,
+		UserRepo:        NewUserRepository(db),
+		CategoryRepo:    NewCategoryRepository(db),
+		InboundRepo:     NewInboundRepository(db),
+		OutboundRepo:    NewOutboundRepository(db),
+		InventoryRepo:   NewInventoryRepository(db),
+		SellerRepo:      NewSellerRepository(db),
+		CustomerRepo:    NewCustomerRepository(db),
+		ReportRepo:      NewReportRepository(db),
+		StocktakeRepo:   NewStocktakeRepository(db),
+		AdjustmentRepo:  NewAdjustmentRepository(db),
+		WarehouseRepo:   NewWarehouseRepository(db),
+		TransferRepo:    NewTransferRepository(db),
+		LotRepo:         NewLotRepository(db),
+		TraceCodeRepo:   NewTraceCodeRepository(db),
+		SequenceRepo:    NewSequenceRepository(db),
+		ManifestRepo:    NewManifestRepository(db),
+		PriceRepo:       NewPriceRepository(db),
+		ScaleRepo:       NewScaleCaptureRepository(db),
+		WeighTicketRepo: NewWeighTicketRepository(db),
+		VehicleRepo:     NewVehicleRepository(db),
+		PaymentRepo:     NewSupplierPaymentRepository(db),
+		ReceiptRepo:     NewCustomerReceiptRepository(db),
+		DB:              db,
 	}
 }
 
+// Transaction 在同一个数据库事务中执行 fn
+// fn 收到的 txRepos 中所有仓库都绑定到该事务，fn 返回错误时整体回滚
+func (r *Repositories) Transaction(fn func(txRepos *Repositories) error) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		return fn(NewRepositories(tx))
+	})
+}
+
 // AutoMigrate 自动迁移数据库表结构
 func (r *Repositories) AutoMigrate() error {
-	return r.DB.AutoMigrate(
+	err := r.DB.AutoMigrate(
 		&models.User{},
 		&models.BatteryCategory{},
+		&models.Warehouse{},
 		&models.InboundOrder{},
+		&models.InboundOrderItem{},
 		&models.OutboundOrder{},
+		&models.OutboundOrderItem{},
 		&models.Inventory{},
+		&models.InventoryMovement{},
+		&models.CostLayer{},
 		&models.Seller{},
+		&models.Customer{},
+		&models.CustomerAddress{},
+		&models.Stocktake{},
+		&models.StocktakeItem{},
+		&models.InventoryAdjustment{},
+		&models.TransferOrder{},
+		&models.TransferOrderItem{},
+		&models.Lot{},
+		&models.LotAllocation{},
+		&models.TraceCode{},
+		&models.TraceCodeEvent{},
+		&models.OrderItemTraceCode{},
+		&models.DocumentSequence{},
+		&models.WasteManifest{},
+		&models.WasteManifestItem{},
+		&models.CategoryPrice{},
+		&models.ScaleCapture{},
+		&models.WeighTicket{},
+		&models.Vehicle{},
+		&models.Driver{},
+		&models.SupplierPayment{},
+		&models.SupplierPaymentAllocation{},
+		&models.CustomerReceipt{},
+		&models.CustomerReceiptAllocation{},
 	)
+	if err != nil {
+		return err
+	}
+	if err := r.migrateWarehouses(); err != nil {
+		return err
+	}
+	if err := r.migrateSettlementWeights(); err != nil {
+		return err
+	}
+	if err := r.migratePriceHistory(); err != nil {
+		return err
+	}
+	return r.migrateOpeningBalances()
+}
+
+// warehouseScopedTables 引入多仓库前已存在、需要回填仓库ID的表
+var warehouseScopedTables = []string{
+	"inventories",
+	"inventory_movements",
+	"cost_layers",
+	"inbound_orders",
+	"outbound_orders",
+	"stocktakes",
+	"inventory_adjustments",
+}
+
+// migrateWarehouses 升级到多仓库：删除库存表旧的分类唯一索引，
+// 创建默认仓库，并将没有仓库的历史库存、流水和单据归入默认仓库
+func (r *Repositories) migrateWarehouses() error {
+	migrator := r.DB.Migrator()
+	if migrator.HasIndex(&models.Inventory{}, "idx_inventories_category_id") {
+		if err := migrator.DropIndex(&models.Inventory{}, "idx_inventories_category_id"); err != nil {
+			return err
+		}
+	}
+
+	return r.Transaction(func(tx *Repositories) error {
+		warehouse, err := tx.WarehouseRepo.GetDefault()
+		if err == gorm.ErrRecordNotFound {
+			warehouse = &models.Warehouse{
+				Code:      models.DefaultWarehouseCode,
+				Name:      "默认仓库",
+				Type:      models.WarehouseTypeYard,
+				IsDefault: true,
+				IsActive:  true,
+			}
+			err = tx.WarehouseRepo.Create(warehouse)
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, table := range warehouseScopedTables {
+			if err := tx.DB.Table(table).Where("warehouse_id = 0").Update("warehouse_id", warehouse.ID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// migrateSettlementWeights 引入扣杂前的入库订单项按净重结算，回填结算重量
+func (r *Repositories) migrateSettlementWeights() error {
+	return r.DB.Model(&models.InboundOrderItem{}).
+		Where("settlement_weight = 0 AND deduction_weight = 0 AND net_weight > 0").
+		Update("settlement_weight", gorm.Expr("net_weight")).Error
+}
+
+// migratePriceHistory 引入价格历史前已设置单价的分类，以分类单价建立期初收购价
+func (r *Repositories) migratePriceHistory() error {
+	var categories []models.BatteryCategory
+	err := r.DB.Where("unit_price > 0").
+		Where("NOT EXISTS (SELECT 1 FROM category_prices p WHERE p.category_id = battery_categories.id AND p.price_type = ?)", models.PriceTypeBuy).
+		Find(&categories).Error
+	if err != nil {
+		return err
+	}
+
+	for _, category := range categories {
+		price := &models.CategoryPrice{
+			CategoryID:    category.ID,
+			PriceType:     models.PriceTypeBuy,
+			UnitPrice:     category.UnitPrice,
+			EffectiveFrom: category.CreatedAt,
+			Notes:         "期初价格",
+		}
+		if err := r.PriceRepo.Create(price); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openingBalancesMigration 期初流水补录完成后在 document_sequences 中写入的标记，标记存在时不再补录
+const openingBalancesMigration = "migration_opening_balances"
+
+// migrateOpeningBalances 引入库存流水和库存成本前已有的库存没有对应流水，也没有成本。
+// 升级时执行一次：为还没有任何流水的库存按仓库和分类补录一条期初流水，发生时间取库存记录的创建时间，
+// 以分类当前有效的收购价 (没有价格历史时取分类单价) 作为期初成本计入库存金额和平均成本，先进先出的分类同时建立期初成本层。
+// 之后结存与流水的差异不再补录，由 /inventory/ledger-check 报告
+func (r *Repositories) migrateOpeningBalances() error {
+	return r.Transaction(func(tx *Repositories) error {
+		var marker models.DocumentSequence
+		err := tx.DB.Clauses(clause.Locking{Strength: "UPDATE"}).Where("name = ?", openingBalancesMigration).First(&marker).Error
+		if err == nil {
+			return nil
+		}
+		if err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		var inventories []models.Inventory
+		err = tx.DB.Where("current_weight_kg >= ?", weightTolerance).
+			Where("NOT EXISTS (SELECT 1 FROM inventory_movements m WHERE m.warehouse_id = inventories.warehouse_id AND m.category_id = inventories.category_id)").
+			Order("warehouse_id, category_id").
+			Find(&inventories).Error
+		if err != nil {
+			return err
+		}
+
+		var categories []models.BatteryCategory
+		if err := tx.DB.Select("id", "unit_price", "costing_method").Find(&categories).Error; err != nil {
+			return err
+		}
+		categoryByID := make(map[uint]models.BatteryCategory, len(categories))
+		for _, category := range categories {
+			categoryByID[category.ID] = category
+		}
+		// 分类单价只在写入价格时同步，以价格历史中当前有效的收购价为准
+		prices, err := tx.PriceRepo.GetAllEffective(time.Now())
+		if err != nil {
+			return err
+		}
+		for _, price := range prices {
+			if category, ok := categoryByID[price.CategoryID]; ok && price.PriceType == models.PriceTypeBuy {
+				category.UnitPrice = price.UnitPrice
+				categoryByID[price.CategoryID] = category
+			}
+		}
+
+		for _, inventory := range inventories {
+			category := categoryByID[inventory.CategoryID]
+			weight := inventory.CurrentWeightKg
+			value := weight * category.UnitPrice
+
+			movement := models.InventoryMovement{
+				WarehouseID:  inventory.WarehouseID,
+				CategoryID:   inventory.CategoryID,
+				Delta:        weight,
+				BalanceAfter: weight,
+				UnitCost:     category.UnitPrice,
+				CostAmount:   value,
+				ValueAfter:   value,
+				SourceType:   models.MovementSourceOpening,
+				CreatedAt:    inventory.CreatedAt,
+			}
+			if err := tx.DB.Create(&movement).Error; err != nil {
+				return err
+			}
+
+			err := tx.InventoryRepo.UpdateFields(inventory.WarehouseID, inventory.CategoryID, map[string]interface{}{
+				"total_value":   value,
+				"avg_unit_cost": category.UnitPrice,
+			})
+			if err != nil {
+				return err
+			}
+
+			if category.CostingMethod == models.CostingMethodFIFO {
+				layer := models.CostLayer{
+					WarehouseID:     inventory.WarehouseID,
+					CategoryID:      inventory.CategoryID,
+					SourceType:      models.MovementSourceOpening,
+					OriginalWeight:  weight,
+					RemainingWeight: weight,
+					UnitCost:        category.UnitPrice,
+				}
+				if err := tx.DB.Create(&layer).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		return tx.DB.Create(&models.DocumentSequence{Name: openingBalancesMigration, Value: 1}).Error
+	})
 }
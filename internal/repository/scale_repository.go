@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"battery-erp-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ScaleCaptureRepository 地磅采集记录数据仓库
+type ScaleCaptureRepository struct {
+	db *gorm.DB
+}
+
+// NewScaleCaptureRepository 创建地磅采集记录仓库实例
+func NewScaleCaptureRepository(db *gorm.DB) *ScaleCaptureRepository {
+	return &ScaleCaptureRepository{db: db}
+}
+
+// Create 新增采集记录
+func (r *ScaleCaptureRepository) Create(capture *models.ScaleCapture) error {
+	return r.db.Create(capture).Error
+}
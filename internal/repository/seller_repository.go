@@ -54,3 +54,21 @@ func (r *SellerRepository) GetByName(name string) ([]models.Seller, error) {
 	err := r.db.Where("name LIKE ?", "%"+name+"%").Find(&sellers).Error
 	return sellers, err
 }
+
+// Search 根据关键字搜索卖家 (名称或电话模糊匹配)
+func (r *SellerRepository) Search(keyword string) ([]models.Seller, error) {
+	var sellers []models.Seller
+	query := r.db.Model(&models.Seller{})
+	if keyword != "" {
+		query = query.Where("name LIKE ? OR phone LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+	}
+	err := query.Order("id DESC").Find(&sellers).Error
+	return sellers, err
+}
+
+// GetByExactName 根据名称精确获取卖家
+func (r *SellerRepository) GetByExactName(name string) ([]models.Seller, error) {
+	var sellers []models.Seller
+	err := r.db.Where("name = ?", name).Find(&sellers).Error
+	return sellers, err
+}
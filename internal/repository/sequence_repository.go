@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"battery-erp-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SequenceRepository 单据流水号数据仓库
+type SequenceRepository struct {
+	db *gorm.DB
+}
+
+// NewSequenceRepository 创建流水号仓库实例
+func NewSequenceRepository(db *gorm.DB) *SequenceRepository {
+	return &SequenceRepository{db: db}
+}
+
+// Next 取指定名称的下一个流水号 (从 1 开始)，需在事务中调用，流水号行锁持有到事务结束，回滚时流水号不会被占用
+func (r *SequenceRepository) Next(name string) (int64, error) {
+	sequence := models.DocumentSequence{Name: name, Value: 1}
+	err := r.db.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{"value": gorm.Expr("value + 1")}),
+	}).Create(&sequence).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var current models.DocumentSequence
+	if err := r.db.Where("name = ?", name).First(&current).Error; err != nil {
+		return 0, err
+	}
+	return current.Value, nil
+}
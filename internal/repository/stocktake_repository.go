@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"battery-erp-backend/internal/models"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StocktakeRepository 盘点单数据仓库
+type StocktakeRepository struct {
+	db *gorm.DB
+}
+
+// NewStocktakeRepository 创建盘点单仓库实例
+func NewStocktakeRepository(db *gorm.DB) *StocktakeRepository {
+	return &StocktakeRepository{db: db}
+}
+
+// Create 创建盘点单
+func (r *StocktakeRepository) Create(stocktake *models.Stocktake) error {
+	return r.db.Create(stocktake).Error
+}
+
+// CreateItems 批量创建盘点明细
+func (r *StocktakeRepository) CreateItems(items []models.StocktakeItem) error {
+	return r.db.Create(&items).Error
+}
+
+// GetByID 根据ID获取盘点单
+func (r *StocktakeRepository) GetByID(id uint) (*models.Stocktake, error) {
+	var stocktake models.Stocktake
+	err := r.db.First(&stocktake, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stocktake, nil
+}
+
+// GetByIDForUpdate 根据ID获取盘点单并加行锁，需在事务中调用
+func (r *StocktakeRepository) GetByIDForUpdate(id uint) (*models.Stocktake, error) {
+	var stocktake models.Stocktake
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&stocktake, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stocktake, nil
+}
+
+// GetAll 分页获取盘点单
+func (r *StocktakeRepository) GetAll(req *models.GetStocktakesRequest) ([]models.Stocktake, int64, error) {
+	query := r.db.Model(&models.Stocktake{})
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.WarehouseID != 0 {
+		query = query.Where("warehouse_id = ?", req.WarehouseID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var stocktakes []models.Stocktake
+	err := query.Order("id DESC").
+		Offset((req.Page - 1) * req.PageSize).
+		Limit(req.PageSize).
+		Find(&stocktakes).Error
+
+	return stocktakes, total, err
+}
+
+// GetItems 获取盘点单明细
+func (r *StocktakeRepository) GetItems(stocktakeID uint) ([]models.StocktakeItem, error) {
+	var items []models.StocktakeItem
+	err := r.db.Where("stocktake_id = ?", stocktakeID).Order("category_id").Find(&items).Error
+	return items, err
+}
+
+// UpdateFields 显式更新盘点单字段
+func (r *StocktakeRepository) UpdateFields(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&models.Stocktake{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// UpdateItemFields 显式更新盘点明细字段
+func (r *StocktakeRepository) UpdateItemFields(itemID uint, updates map[string]interface{}) error {
+	return r.db.Model(&models.StocktakeItem{}).Where("id = ?", itemID).Updates(updates).Error
+}
+
+// GetOpenByCategoryIDs 获取仓库中包含指定分类的进行中盘点，返回 分类ID -> 盘点单号
+func (r *StocktakeRepository) GetOpenByCategoryIDs(warehouseID uint, categoryIDs []uint) (map[uint]string, error) {
+	var rows []struct {
+		CategoryID  uint
+		StocktakeNo string
+	}
+	err := r.db.Table("stocktake_items as i").
+		Select("i.category_id, s.stocktake_no").
+		Joins("JOIN stocktakes s ON s.id = i.stocktake_id").
+		Where("s.status = ? AND s.warehouse_id = ? AND i.category_id IN ?", models.StocktakeStatusOpen, warehouseID, categoryIDs).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	open := make(map[uint]string, len(rows))
+	for _, row := range rows {
+		open[row.CategoryID] = row.StocktakeNo
+	}
+	return open, nil
+}
+
+// GenerateStocktakeNo 生成盘点单号
+func (r *StocktakeRepository) GenerateStocktakeNo() (string, error) {
+	now := time.Now()
+
+	// 格式：ST-20240101-123456789-1234
+	stocktakeNo := fmt.Sprintf("ST-%s-%05d-%04d", now.Format("20060102"), now.Nanosecond(), rand.Intn(10000))
+	return stocktakeNo, nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"battery-erp-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TraceCodeRepository 溯源编码数据仓库
+type TraceCodeRepository struct {
+	db *gorm.DB
+}
+
+// NewTraceCodeRepository 创建溯源编码仓库实例
+func NewTraceCodeRepository(db *gorm.DB) *TraceCodeRepository {
+	return &TraceCodeRepository{db: db}
+}
+
+// Create 登记溯源编码
+func (r *TraceCodeRepository) Create(traceCode *models.TraceCode) error {
+	return r.db.Create(traceCode).Error
+}
+
+// GetByCode 根据编码获取登记记录
+func (r *TraceCodeRepository) GetByCode(code string) (*models.TraceCode, error) {
+	var traceCode models.TraceCode
+	err := r.db.Where("code = ?", code).First(&traceCode).Error
+	if err != nil {
+		return nil, err
+	}
+	return &traceCode, nil
+}
+
+// GetByCodeForUpdate 根据编码获取登记记录并加行锁，需在事务中调用
+func (r *TraceCodeRepository) GetByCodeForUpdate(code string) (*models.TraceCode, error) {
+	var traceCode models.TraceCode
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("code = ?", code).First(&traceCode).Error
+	if err != nil {
+		return nil, err
+	}
+	return &traceCode, nil
+}
+
+// GetRegistered 获取已登记的编码
+func (r *TraceCodeRepository) GetRegistered(codes []string) ([]models.TraceCode, error) {
+	var traceCodes []models.TraceCode
+	err := r.db.Where("code IN ?", codes).Find(&traceCodes).Error
+	return traceCodes, err
+}
+
+// UpdateFields 显式更新登记记录字段
+func (r *TraceCodeRepository) UpdateFields(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&models.TraceCode{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// Delete 删除登记记录
+func (r *TraceCodeRepository) Delete(id uint) error {
+	return r.db.Delete(&models.TraceCode{}, id).Error
+}
+
+// CreateEvent 记录溯源编码事件
+func (r *TraceCodeRepository) CreateEvent(event *models.TraceCodeEvent) error {
+	return r.db.Create(event).Error
+}
+
+// GetEvents 获取编码的全部事件
+func (r *TraceCodeRepository) GetEvents(code string) ([]models.TraceCodeEvent, error) {
+	var events []models.TraceCodeEvent
+	err := r.db.Where("code = ?", code).Order("id").Find(&events).Error
+	return events, err
+}
+
+// CreateItemCodes 保存订单项采集的溯源编码
+func (r *TraceCodeRepository) CreateItemCodes(codes []models.OrderItemTraceCode) error {
+	return r.db.Create(&codes).Error
+}
+
+// GetItemCodes 获取订单采集的溯源编码
+func (r *TraceCodeRepository) GetItemCodes(sourceType string, sourceID uint) ([]models.OrderItemTraceCode, error) {
+	var codes []models.OrderItemTraceCode
+	err := r.db.Where("source_type = ? AND source_id = ?", sourceType, sourceID).Order("id").Find(&codes).Error
+	return codes, err
+}
+
+// DeleteItemCodes 删除订单采集的溯源编码，itemID 非 0 时仅删除该订单项的编码
+func (r *TraceCodeRepository) DeleteItemCodes(sourceType string, sourceID, itemID uint) error {
+	query := r.db.Where("source_type = ? AND source_id = ?", sourceType, sourceID)
+	if itemID != 0 {
+		query = query.Where("source_item_id = ?", itemID)
+	}
+	return query.Delete(&models.OrderItemTraceCode{}).Error
+}
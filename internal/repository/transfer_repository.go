@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"battery-erp-backend/internal/models"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TransferRepository 调拨单数据仓库
+type TransferRepository struct {
+	db *gorm.DB
+}
+
+// NewTransferRepository 创建调拨单仓库实例
+func NewTransferRepository(db *gorm.DB) *TransferRepository {
+	return &TransferRepository{db: db}
+}
+
+// Create 创建调拨单
+func (r *TransferRepository) Create(transfer *models.TransferOrder) error {
+	return r.db.Create(transfer).Error
+}
+
+// CreateItems 批量创建调拨明细
+func (r *TransferRepository) CreateItems(items []models.TransferOrderItem) error {
+	return r.db.Create(&items).Error
+}
+
+// GetByID 根据ID获取调拨单
+func (r *TransferRepository) GetByID(id uint) (*models.TransferOrder, error) {
+	var transfer models.TransferOrder
+	err := r.db.First(&transfer, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// GetByIDForUpdate 根据ID获取调拨单并加行锁，需在事务中调用
+func (r *TransferRepository) GetByIDForUpdate(id uint) (*models.TransferOrder, error) {
+	var transfer models.TransferOrder
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transfer, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// GetAll 分页获取调拨单
+func (r *TransferRepository) GetAll(req *models.GetTransferOrdersRequest) ([]models.TransferOrder, int64, error) {
+	query := r.db.Model(&models.TransferOrder{})
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.WarehouseID != 0 {
+		query = query.Where("from_warehouse_id = ? OR to_warehouse_id = ?", req.WarehouseID, req.WarehouseID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var transfers []models.TransferOrder
+	err := query.Order("id DESC").
+		Offset((req.Page - 1) * req.PageSize).
+		Limit(req.PageSize).
+		Find(&transfers).Error
+
+	return transfers, total, err
+}
+
+// GetItems 获取调拨明细
+func (r *TransferRepository) GetItems(transferID uint) ([]models.TransferOrderItem, error) {
+	var items []models.TransferOrderItem
+	err := r.db.Where("transfer_id = ?", transferID).Order("category_id").Find(&items).Error
+	return items, err
+}
+
+// UpdateFields 显式更新调拨单字段
+func (r *TransferRepository) UpdateFields(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&models.TransferOrder{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// UpdateItemFields 显式更新调拨明细字段
+func (r *TransferRepository) UpdateItemFields(itemID uint, updates map[string]interface{}) error {
+	return r.db.Model(&models.TransferOrderItem{}).Where("id = ?", itemID).Updates(updates).Error
+}
+
+// GetInTransit 按调出仓、调入仓和分类汇总在途调拨的重量和金额，warehouseID 非 0 时仅统计调出或调入该仓库的调拨
+func (r *TransferRepository) GetInTransit(warehouseID uint) ([]models.InTransitStock, error) {
+	query := r.db.Table("transfer_order_items as i").
+		Select(`
+			t.from_warehouse_id,
+			t.to_warehouse_id,
+			i.category_id,
+			COALESCE(c.name, '未知分类') as category_name,
+			COUNT(DISTINCT t.id) as transfer_count,
+			SUM(i.weight) as weight,
+			SUM(i.weight * i.unit_cost) as value
+		`).
+		Joins("JOIN transfer_orders t ON t.id = i.transfer_id").
+		Joins("LEFT JOIN battery_categories c ON c.id = i.category_id").
+		Where("t.status = ?", models.TransferStatusInTransit)
+	if warehouseID != 0 {
+		query = query.Where("t.from_warehouse_id = ? OR t.to_warehouse_id = ?", warehouseID, warehouseID)
+	}
+
+	var rows []models.InTransitStock
+	err := query.Group("t.from_warehouse_id, t.to_warehouse_id, i.category_id, c.name").
+		Order("t.from_warehouse_id, t.to_warehouse_id, i.category_id").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// GenerateTransferNo 生成调拨单号
+func (r *TransferRepository) GenerateTransferNo() (string, error) {
+	now := time.Now()
+
+	// 格式：TR-20240101-123456789-1234
+	transferNo := fmt.Sprintf("TR-%s-%05d-%04d", now.Format("20060102"), now.Nanosecond(), rand.Intn(10000))
+	return transferNo, nil
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"battery-erp-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// VehicleRepository 车辆和司机档案数据仓库
+type VehicleRepository struct {
+	db *gorm.DB
+}
+
+// NewVehicleRepository 创建车辆仓库实例
+func NewVehicleRepository(db *gorm.DB) *VehicleRepository {
+	return &VehicleRepository{db: db}
+}
+
+// Create 创建车辆
+func (r *VehicleRepository) Create(vehicle *models.Vehicle) error {
+	return r.db.Create(vehicle).Error
+}
+
+// GetByID 根据ID获取启用的车辆
+func (r *VehicleRepository) GetByID(id uint) (*models.Vehicle, error) {
+	var vehicle models.Vehicle
+	if err := r.db.Where("is_active = ?", true).First(&vehicle, id).Error; err != nil {
+		return nil, err
+	}
+	return &vehicle, nil
+}
+
+// GetByPlate 根据车牌号获取车辆 (包括已停用的)
+func (r *VehicleRepository) GetByPlate(plateNumber string) (*models.Vehicle, error) {
+	var vehicle models.Vehicle
+	if err := r.db.Where("plate_number = ?", plateNumber).First(&vehicle).Error; err != nil {
+		return nil, err
+	}
+	return &vehicle, nil
+}
+
+// GetAll 获取启用的车辆
+func (r *VehicleRepository) GetAll(req *models.GetVehiclesRequest) ([]models.Vehicle, error) {
+	query := r.db.Where("is_active = ?", true)
+	if req.Keyword != "" {
+		keyword := "%" + req.Keyword + "%"
+		query = query.Where("plate_number LIKE ? OR carrier LIKE ?", keyword, keyword)
+	}
+
+	var vehicles []models.Vehicle
+	err := query.Order("plate_number").Find(&vehicles).Error
+	return vehicles, err
+}
+
+// UpdateFields 显式更新车辆字段
+func (r *VehicleRepository) UpdateFields(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&models.Vehicle{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// Delete 停用车辆 (软删除)
+func (r *VehicleRepository) Delete(id uint) error {
+	return r.db.Model(&models.Vehicle{}).Where("id = ?", id).Update("is_active", false).Error
+}
+
+// CreateDriver 创建司机
+func (r *VehicleRepository) CreateDriver(driver *models.Driver) error {
+	return r.db.Create(driver).Error
+}
+
+// GetDriverByID 根据ID获取启用的司机
+func (r *VehicleRepository) GetDriverByID(id uint) (*models.Driver, error) {
+	var driver models.Driver
+	if err := r.db.Where("is_active = ?", true).First(&driver, id).Error; err != nil {
+		return nil, err
+	}
+	return &driver, nil
+}
+
+// GetDriverByPhone 根据手机号获取启用的司机
+func (r *VehicleRepository) GetDriverByPhone(phone string) (*models.Driver, error) {
+	var driver models.Driver
+	if err := r.db.Where("phone = ? AND is_active = ?", phone, true).First(&driver).Error; err != nil {
+		return nil, err
+	}
+	return &driver, nil
+}
+
+// GetAllDrivers 获取启用的司机
+func (r *VehicleRepository) GetAllDrivers(req *models.GetDriversRequest) ([]models.Driver, error) {
+	query := r.db.Where("is_active = ?", true)
+	if req.Keyword != "" {
+		keyword := "%" + req.Keyword + "%"
+		query = query.Where("name LIKE ? OR phone LIKE ?", keyword, keyword)
+	}
+
+	var drivers []models.Driver
+	err := query.Order("name").Find(&drivers).Error
+	return drivers, err
+}
+
+// UpdateDriverFields 显式更新司机字段
+func (r *VehicleRepository) UpdateDriverFields(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&models.Driver{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// DeleteDriver 停用司机 (软删除)，同时解除车辆的常用司机
+func (r *VehicleRepository) DeleteDriver(id uint) error {
+	if err := r.db.Model(&models.Vehicle{}).Where("default_driver_id = ?", id).Update("default_driver_id", nil).Error; err != nil {
+		return err
+	}
+	return r.db.Model(&models.Driver{}).Where("id = ?", id).Update("is_active", false).Error
+}
+
+// GetTicketTrips 获取车辆的磅单车次，按车辆ID或车牌号匹配 (包括登记车辆前的磅单)
+func (r *VehicleRepository) GetTicketTrips(vehicleID uint, plateNumber string, req *models.GetVehicleTripsRequest) ([]models.WeighTicket, error) {
+	query := r.db.Where("vehicle_id = ? OR car_number = ?", vehicleID, plateNumber)
+	if req.StartDate != "" {
+		query = query.Where("first_weighed_at >= ?", req.StartDate)
+	}
+	if req.EndDate != "" {
+		query = query.Where("first_weighed_at < DATE_ADD(?, INTERVAL 1 DAY)", req.EndDate)
+	}
+
+	var tickets []models.WeighTicket
+	err := query.Order("first_weighed_at DESC").Find(&tickets).Error
+	return tickets, err
+}
+
+// GetOrderTrips 获取车辆未经磅单开具的出库订单车次，净重为订单各项重量之和
+func (r *VehicleRepository) GetOrderTrips(vehicleID uint, plateNumber string, req *models.GetVehicleTripsRequest) ([]models.VehicleTrip, error) {
+	query := r.db.Table("outbound_orders as o").
+		Select(`
+			'outbound_order' as source,
+			o.id as source_id,
+			o.order_no as document_no,
+			'outbound' as direction,
+			o.status,
+			o.driver_name,
+			o.created_at as trip_at,
+			COALESCE((SELECT SUM(i.weight) FROM outbound_order_items i WHERE i.order_id = o.id), 0) as net_weight,
+			o.order_no
+		`).
+		Where("o.is_deleted = 0 AND o.weigh_ticket_id IS NULL").
+		Where("o.vehicle_id = ? OR o.car_number = ?", vehicleID, plateNumber)
+	if req.StartDate != "" {
+		query = query.Where("o.created_at >= ?", req.StartDate)
+	}
+	if req.EndDate != "" {
+		query = query.Where("o.created_at < DATE_ADD(?, INTERVAL 1 DAY)", req.EndDate)
+	}
+
+	var trips []models.VehicleTrip
+	err := query.Order("o.created_at DESC").Scan(&trips).Error
+	return trips, err
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"battery-erp-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// WarehouseRepository 仓库数据仓库
+type WarehouseRepository struct {
+	db *gorm.DB
+}
+
+// NewWarehouseRepository 创建仓库数据仓库实例
+func NewWarehouseRepository(db *gorm.DB) *WarehouseRepository {
+	return &WarehouseRepository{db: db}
+}
+
+// Create 创建仓库
+func (r *WarehouseRepository) Create(warehouse *models.Warehouse) error {
+	return r.db.Create(warehouse).Error
+}
+
+// GetByID 根据ID获取启用的仓库
+func (r *WarehouseRepository) GetByID(id uint) (*models.Warehouse, error) {
+	var warehouse models.Warehouse
+	err := r.db.Where("is_active = ?", true).First(&warehouse, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &warehouse, nil
+}
+
+// GetAll 获取所有启用的仓库
+func (r *WarehouseRepository) GetAll() ([]models.Warehouse, error) {
+	var warehouses []models.Warehouse
+	err := r.db.Where("is_active = ?", true).Order("id").Find(&warehouses).Error
+	return warehouses, err
+}
+
+// GetDefault 获取默认仓库
+func (r *WarehouseRepository) GetDefault() (*models.Warehouse, error) {
+	var warehouse models.Warehouse
+	err := r.db.Where("is_default = ?", true).First(&warehouse).Error
+	if err != nil {
+		return nil, err
+	}
+	return &warehouse, nil
+}
+
+// UpdateFields 显式更新仓库字段
+func (r *WarehouseRepository) UpdateFields(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&models.Warehouse{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// Delete 停用仓库 (软删除)
+func (r *WarehouseRepository) Delete(id uint) error {
+	return r.db.Model(&models.Warehouse{}).Where("id = ?", id).Update("is_active", false).Error
+}
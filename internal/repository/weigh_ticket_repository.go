@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"battery-erp-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WeighTicketRepository 磅单数据仓库
+type WeighTicketRepository struct {
+	db *gorm.DB
+}
+
+// NewWeighTicketRepository 创建磅单仓库实例
+func NewWeighTicketRepository(db *gorm.DB) *WeighTicketRepository {
+	return &WeighTicketRepository{db: db}
+}
+
+// Create 创建磅单
+func (r *WeighTicketRepository) Create(ticket *models.WeighTicket) error {
+	return r.db.Create(ticket).Error
+}
+
+// GetByID 根据ID获取磅单
+func (r *WeighTicketRepository) GetByID(id uint) (*models.WeighTicket, error) {
+	var ticket models.WeighTicket
+	if err := r.db.First(&ticket, id).Error; err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+// GetByIDForUpdate 根据ID获取磅单并加行锁，需在事务中调用
+func (r *WeighTicketRepository) GetByIDForUpdate(id uint) (*models.WeighTicket, error) {
+	var ticket models.WeighTicket
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&ticket, id).Error; err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+// GetOpenByCarNumber 获取车辆未完成二次称重的磅单
+func (r *WeighTicketRepository) GetOpenByCarNumber(carNumber string) (*models.WeighTicket, error) {
+	var ticket models.WeighTicket
+	err := r.db.Where("car_number = ? AND status = ?", carNumber, models.WeighTicketStatusOpen).First(&ticket).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+// GetAll 分页获取磅单
+func (r *WeighTicketRepository) GetAll(req *models.GetWeighTicketsRequest) ([]models.WeighTicket, int64, error) {
+	query := r.db.Model(&models.WeighTicket{})
+	if req.Direction != "" {
+		query = query.Where("direction = ?", req.Direction)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.CarNumber != "" {
+		query = query.Where("car_number LIKE ?", "%"+req.CarNumber+"%")
+	}
+	if req.TareFlagged {
+		query = query.Where("tare_flagged = ?", true)
+	}
+	if req.StartDate != "" {
+		query = query.Where("first_weighed_at >= ?", req.StartDate)
+	}
+	if req.EndDate != "" {
+		query = query.Where("first_weighed_at < DATE_ADD(?, INTERVAL 1 DAY)", req.EndDate)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var tickets []models.WeighTicket
+	err := query.Order("id DESC").
+		Offset((req.Page - 1) * req.PageSize).
+		Limit(req.PageSize).
+		Find(&tickets).Error
+
+	return tickets, total, err
+}
+
+// UpdateFields 显式更新磅单字段
+func (r *WeighTicketRepository) UpdateFields(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&models.WeighTicket{}).Where("id = ?", id).Updates(updates).Error
+}
@@ -0,0 +1,75 @@
+package scale
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// asciiProtocol 按行输出的文本协议，如 "ST,GS,+0012340kg"
+// 第一段为状态 (ST 稳定, US 不稳定, OL 超载)，第二段为毛重/净重标记，第三段为带单位的重量
+type asciiProtocol struct{}
+
+func (asciiProtocol) Name() string { return "ascii" }
+
+func (asciiProtocol) Split(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, bytes.TrimRight(data[:i], "\r"), nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), bytes.TrimRight(data, "\r"), nil
+	}
+	return 0, nil, nil
+}
+
+func (asciiProtocol) Parse(frame []byte) (Frame, error) {
+	fields := strings.Split(strings.TrimSpace(string(frame)), ",")
+	if len(fields) != 3 {
+		return Frame{}, fmt.Errorf("ascii: malformed frame %q", frame)
+	}
+
+	var result Frame
+	switch strings.TrimSpace(fields[0]) {
+	case "ST":
+	case "US":
+		result.Motion = true
+	case "OL":
+		return Frame{}, fmt.Errorf("ascii: scale overload")
+	default:
+		return Frame{}, fmt.Errorf("ascii: unknown status %q", fields[0])
+	}
+
+	value := strings.TrimSpace(fields[2])
+	factor := 1.0
+	for _, unit := range []struct {
+		suffix string
+		factor float64
+	}{{"kg", 1}, {"lb", toledoPoundsToKg}, {"t", 1000}, {"g", 0.001}} {
+		if strings.HasSuffix(strings.ToLower(value), unit.suffix) {
+			value = strings.TrimSpace(value[:len(value)-len(unit.suffix)])
+			factor = unit.factor
+			break
+		}
+	}
+
+	weight, err := strconv.ParseFloat(strings.ReplaceAll(value, " ", ""), 64)
+	if err != nil {
+		return Frame{}, fmt.Errorf("ascii: invalid weight %q", fields[2])
+	}
+	result.Weight = weight * factor
+	return result, nil
+}
+
+func (asciiProtocol) Format(frame Frame) []byte {
+	status := "ST"
+	if frame.Motion {
+		status = "US"
+	}
+	sign := '+'
+	if frame.Weight < 0 {
+		sign = '-'
+	}
+	return []byte(fmt.Sprintf("%s,GS,%c%07dkg\r\n", status, sign, int64(math.Round(math.Abs(frame.Weight)))))
+}
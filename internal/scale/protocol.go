@@ -0,0 +1,56 @@
+package scale
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Frame 仪表输出的一帧重量数据
+type Frame struct {
+	Weight float64 // 重量 kg
+	Motion bool    // 仪表报告秤台晃动 (不支持该状态的协议始终为 false)
+}
+
+// Protocol 仪表输出协议驱动
+type Protocol interface {
+	// Name 协议名称
+	Name() string
+	// Split 从字节流中切分出一帧，签名与 bufio.SplitFunc 相同，无法识别的字节直接跳过
+	Split(data []byte, atEOF bool) (advance int, token []byte, err error)
+	// Parse 解析一帧数据
+	Parse(frame []byte) (Frame, error)
+	// Format 按协议编码一帧数据，供模拟器使用
+	Format(frame Frame) []byte
+}
+
+var protocols = map[string]func() Protocol{}
+
+// RegisterProtocol 注册仪表协议，重复注册时覆盖
+func RegisterProtocol(name string, factory func() Protocol) {
+	protocols[name] = factory
+}
+
+// NewProtocol 按名称创建仪表协议
+func NewProtocol(name string) (Protocol, error) {
+	factory, ok := protocols[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown scale protocol %q, supported: %v", name, ProtocolNames())
+	}
+	return factory(), nil
+}
+
+// ProtocolNames 已注册的协议名称
+func ProtocolNames() []string {
+	names := make([]string, 0, len(protocols))
+	for name := range protocols {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	RegisterProtocol("yaohua", func() Protocol { return yaohuaProtocol{} })
+	RegisterProtocol("toledo", func() Protocol { return toledoProtocol{} })
+	RegisterProtocol("ascii", func() Protocol { return asciiProtocol{} })
+}
@@ -0,0 +1,101 @@
+package scale
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func TestProtocolRoundTrip(t *testing.T) {
+	frames := []Frame{
+		{Weight: 0},
+		{Weight: 12340},
+		{Weight: 35680, Motion: true},
+		{Weight: -560},
+	}
+
+	tests := []struct {
+		name string
+		// noise 第一帧之前的线路噪声或从帧中间开始读取的残帧
+		noise string
+		// motion 协议是否能传输晃动状态
+		motion bool
+	}{
+		{name: "yaohua", noise: "\x00\xff+01\x03", motion: false},
+		{name: "toledo", noise: "\x00\xff000000\r", motion: true},
+		{name: "ascii", noise: "0kg\r\n", motion: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			protocol, err := NewProtocol(tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var stream bytes.Buffer
+			stream.WriteString(tt.noise)
+			for _, frame := range frames {
+				stream.Write(protocol.Format(frame))
+			}
+
+			scanner := bufio.NewScanner(&stream)
+			scanner.Split(protocol.Split)
+			var got []Frame
+			for scanner.Scan() {
+				frame, err := protocol.Parse(scanner.Bytes())
+				if err != nil {
+					// 噪声只允许出现在第一帧之前
+					if len(got) > 0 {
+						t.Fatalf("Parse(%q): %v", scanner.Bytes(), err)
+					}
+					continue
+				}
+				got = append(got, frame)
+			}
+			if err := scanner.Err(); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(got) != len(frames) {
+				t.Fatalf("got %d frames, want %d: %+v", len(got), len(frames), got)
+			}
+			for i, want := range frames {
+				if !tt.motion {
+					want.Motion = false
+				}
+				if got[i] != want {
+					t.Errorf("frame %d: got %+v, want %+v", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestProtocolParseErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol string
+		frame    []byte
+	}{
+		{"yaohua checksum mismatch", "yaohua", []byte("\x02+0123400FF\x03")},
+		{"yaohua truncated", "yaohua", []byte("\x02+012340\x03")},
+		{"toledo over range", "toledo", []byte("\x02\x22\x34\x20012340000000\r")},
+		{"toledo truncated", "toledo", []byte("\x02\x22\x30\x20\r")},
+		{"ascii overload", "ascii", []byte("OL,GS,+0000000kg")},
+		{"ascii unknown status", "ascii", []byte("XX,GS,+0012340kg")},
+		{"ascii missing field", "ascii", []byte("ST,+0012340kg")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			protocol, err := NewProtocol(tt.protocol)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if frame, err := protocol.Parse(tt.frame); err == nil {
+				t.Errorf("Parse(%q) = %+v, want error", tt.frame, frame)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+// Package scale 读取地磅仪表的连续输出，解析重量并判断读数是否稳定
+package scale
+
+import (
+	"battery-erp-backend/config"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrOffline 地磅未连接或长时间没有数据
+	ErrOffline = errors.New("scale is offline")
+)
+
+// 重连退避时间
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+// Reading 地磅当前读数
+type Reading struct {
+	Weight     float64
+	Stable     bool
+	Motion     bool
+	ReceivedAt time.Time
+}
+
+// Scale 地磅连接管理，在后台持续读取仪表数据并保存最新读数
+type Scale struct {
+	cfg      config.ScaleConfig
+	protocol Protocol
+	stable   *stabilityDetector
+
+	mu      sync.RWMutex
+	reading Reading
+	lastErr error
+	conn    io.Closer
+
+	startOnce sync.Once
+	done      chan struct{}
+	closed    chan struct{}
+}
+
+// New 按配置创建地磅，未填写的参数使用默认值
+func New(cfg config.ScaleConfig) (*Scale, error) {
+	if cfg.Transport == "" {
+		cfg.Transport = "simulator"
+	}
+	if cfg.Protocol == "" {
+		cfg.Protocol = "yaohua"
+	}
+	if cfg.BaudRate <= 0 {
+		cfg.BaudRate = 9600
+	}
+	if cfg.StableWindowMs <= 0 {
+		cfg.StableWindowMs = 2000
+	}
+	if cfg.StableToleranceKg <= 0 {
+		cfg.StableToleranceKg = 20
+	}
+	if cfg.StaleAfterMs <= 0 {
+		cfg.StaleAfterMs = 3000
+	}
+	if cfg.Transport != "simulator" && cfg.Address == "" {
+		return nil, fmt.Errorf("scale address is required for %s transport", cfg.Transport)
+	}
+
+	protocol, err := NewProtocol(cfg.Protocol)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Scale{
+		cfg:      cfg,
+		protocol: protocol,
+		stable:   newStabilityDetector(time.Duration(cfg.StableWindowMs)*time.Millisecond, cfg.StableToleranceKg),
+		lastErr:  ErrOffline,
+		done:     make(chan struct{}),
+		closed:   make(chan struct{}),
+	}, nil
+}
+
+// Start 启动后台读取，连接断开后自动重连
+func (s *Scale) Start() {
+	s.startOnce.Do(func() {
+		go s.run()
+	})
+}
+
+// Close 停止读取并关闭连接
+func (s *Scale) Close() error {
+	select {
+	case <-s.closed:
+		return nil
+	default:
+	}
+	close(s.closed)
+
+	s.mu.Lock()
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	s.mu.Unlock()
+
+	started := true
+	s.startOnce.Do(func() { started = false })
+	if started {
+		<-s.done
+	}
+	return nil
+}
+
+// Current 返回最新读数，超过 StaleAfterMs 未收到数据时返回 ErrOffline
+func (s *Scale) Current() (Reading, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.reading.ReceivedAt.IsZero() || time.Since(s.reading.ReceivedAt) > time.Duration(s.cfg.StaleAfterMs)*time.Millisecond {
+		if s.lastErr != nil && s.lastErr != ErrOffline {
+			return Reading{}, fmt.Errorf("%w: %v", ErrOffline, s.lastErr)
+		}
+		return Reading{}, ErrOffline
+	}
+	return s.reading, nil
+}
+
+func (s *Scale) run() {
+	defer close(s.done)
+
+	delay := minReconnectDelay
+	for {
+		connected, err := s.readLoop()
+		select {
+		case <-s.closed:
+			return
+		default:
+		}
+		// 连接成功过说明设备恢复，重新从最短间隔开始退避
+		if connected {
+			delay = minReconnectDelay
+		}
+		if err != nil {
+			log.Printf("scale: %v, reconnecting in %s", err, delay)
+		}
+
+		select {
+		case <-s.closed:
+			return
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// readLoop 建立一次连接并持续读取，直到连接出错或关闭，connected 表示本次是否连接成功
+func (s *Scale) readLoop() (connected bool, err error) {
+	conn, err := s.dial()
+	if err != nil {
+		s.setError(err)
+		return false, err
+	}
+
+	s.mu.Lock()
+	select {
+	case <-s.closed:
+		s.mu.Unlock()
+		conn.Close()
+		return true, nil
+	default:
+	}
+	s.conn = conn
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.conn = nil
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Split(s.protocol.Split)
+	for scanner.Scan() {
+		frame, err := s.protocol.Parse(scanner.Bytes())
+		if err != nil {
+			// 单帧校验失败 (线路干扰) 不影响连接和稳定窗口，超载等状态记录为最近错误
+			s.setFrameError(err)
+			continue
+		}
+		s.update(frame)
+	}
+	if err := scanner.Err(); err != nil {
+		s.setError(err)
+		return true, err
+	}
+	err = io.ErrUnexpectedEOF
+	s.setError(err)
+	return true, err
+}
+
+func (s *Scale) update(frame Frame) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reading = Reading{
+		Weight:     frame.Weight,
+		Stable:     s.stable.Add(now, frame),
+		Motion:     frame.Motion,
+		ReceivedAt: now,
+	}
+	s.lastErr = nil
+}
+
+// setError 记录连接错误并清空稳定窗口
+func (s *Scale) setError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastErr = err
+	s.stable.Reset()
+}
+
+// setFrameError 记录单帧解析错误，保留稳定窗口
+func (s *Scale) setFrameError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastErr = err
+}
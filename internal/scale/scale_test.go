@@ -0,0 +1,46 @@
+package scale
+
+import (
+	"battery-erp-backend/config"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestScaleReconnectsSilentTCPConnection(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	// 串口服务器接受连接并发送一帧后不再发送数据，也不关闭连接
+	accepted := make(chan net.Conn, 4)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write(yaohuaProtocol{}.Format(Frame{Weight: 12340}))
+			accepted <- conn
+		}
+	}()
+
+	s, err := New(config.ScaleConfig{Transport: "tcp", Address: ln.Addr().String(), Protocol: "yaohua", StaleAfterMs: 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Start()
+	defer s.Close()
+
+	timeout := time.After(5 * time.Second)
+	for i := 0; i < 2; i++ {
+		select {
+		case conn := <-accepted:
+			defer conn.Close()
+		case <-timeout:
+			t.Fatalf("got %d connections, want the scale to reconnect after the read timeout", i)
+		}
+	}
+}
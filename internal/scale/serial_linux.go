@@ -0,0 +1,63 @@
+//go:build linux
+
+package scale
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var baudRates = map[int]uint32{
+	1200:   syscall.B1200,
+	2400:   syscall.B2400,
+	4800:   syscall.B4800,
+	9600:   syscall.B9600,
+	19200:  syscall.B19200,
+	38400:  syscall.B38400,
+	57600:  syscall.B57600,
+	115200: syscall.B115200,
+}
+
+// openSerial 以原始模式 8N1 打开串口
+func openSerial(device string, baudRate int) (io.ReadCloser, error) {
+	speed, ok := baudRates[baudRate]
+	if !ok {
+		return nil, fmt.Errorf("unsupported baud rate %d", baudRate)
+	}
+
+	file, err := os.OpenFile(device, os.O_RDWR|syscall.O_NOCTTY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// 通过 SyscallConn 操作文件描述符，避免 Fd() 将其切回阻塞模式导致 Close 无法中断 Read
+	conn, err := file.SyscallConn()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	var ioctlErr error
+	if err := conn.Control(func(fd uintptr) {
+		termios := syscall.Termios{
+			Cflag:  speed | syscall.CS8 | syscall.CREAD | syscall.CLOCAL,
+			Ispeed: speed,
+			Ospeed: speed,
+		}
+		termios.Cc[syscall.VMIN] = 1
+		termios.Cc[syscall.VTIME] = 0
+		if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, uintptr(syscall.TCSETS), uintptr(unsafe.Pointer(&termios))); errno != 0 {
+			ioctlErr = errno
+		}
+	}); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if ioctlErr != nil {
+		file.Close()
+		return nil, fmt.Errorf("configure serial port %s: %w", device, ioctlErr)
+	}
+	return file, nil
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package scale
+
+import (
+	"errors"
+	"io"
+)
+
+func openSerial(device string, baudRate int) (io.ReadCloser, error) {
+	return nil, errors.New("serial transport is only supported on linux, use a serial-to-TCP converter instead")
+}
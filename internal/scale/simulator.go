@@ -0,0 +1,99 @@
+package scale
+
+import (
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// simulatorInterval 模拟仪表的输出间隔，与常见仪表 10Hz 连续输出一致
+const simulatorInterval = 100 * time.Millisecond
+
+// 模拟一辆车上磅、停稳、下磅的各阶段时长
+const (
+	simEmpty    = 3 * time.Second
+	simRamp     = 4 * time.Second
+	simHold     = 8 * time.Second
+	simCycle    = simEmpty + simRamp + simHold + simRamp
+	simJitterKg = 4
+)
+
+// simulator 不接硬件时使用的模拟地磅，按所配置的协议输出帧。
+// 车辆交替以重车和空车上磅，便于测试毛重和皮重两次称重
+type simulator struct {
+	protocol Protocol
+	started  time.Time
+	rng      *rand.Rand
+	ticker   *time.Ticker
+	pending  []byte
+	cycle    int
+	target   float64
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func newSimulator(protocol Protocol) *simulator {
+	return &simulator{
+		protocol: protocol,
+		started:  time.Now(),
+		rng:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		ticker:   time.NewTicker(simulatorInterval),
+		cycle:    -1,
+		closed:   make(chan struct{}),
+	}
+}
+
+func (s *simulator) Read(p []byte) (int, error) {
+	for len(s.pending) == 0 {
+		select {
+		case <-s.closed:
+			return 0, io.EOF
+		case now := <-s.ticker.C:
+			s.pending = s.protocol.Format(s.frameAt(now.Sub(s.started)))
+		}
+	}
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+func (s *simulator) Close() error {
+	s.closeOnce.Do(func() {
+		s.ticker.Stop()
+		close(s.closed)
+	})
+	return nil
+}
+
+// frameAt 计算模拟开始后 elapsed 时刻的读数
+func (s *simulator) frameAt(elapsed time.Duration) Frame {
+	if cycle := int(elapsed / simCycle); cycle != s.cycle {
+		s.cycle = cycle
+		if cycle%2 == 0 {
+			s.target = float64(25000 + s.rng.Intn(15000)) // 重车
+		} else {
+			s.target = float64(12000 + s.rng.Intn(4000)) // 空车
+		}
+	}
+
+	phase := elapsed % simCycle
+	switch {
+	case phase < simEmpty:
+		return Frame{}
+	case phase < simEmpty+simRamp:
+		progress := float64(phase-simEmpty) / float64(simRamp)
+		return Frame{Weight: roundKg(s.target * progress), Motion: true}
+	case phase < simEmpty+simRamp+simHold:
+		jitter := float64(s.rng.Intn(2*simJitterKg+1) - simJitterKg)
+		return Frame{Weight: s.target + jitter}
+	default:
+		progress := float64(phase-simEmpty-simRamp-simHold) / float64(simRamp)
+		return Frame{Weight: roundKg(s.target * (1 - progress)), Motion: true}
+	}
+}
+
+func roundKg(v float64) float64 {
+	return float64(int64(v/10)) * 10
+}
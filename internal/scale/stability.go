@@ -0,0 +1,58 @@
+package scale
+
+import (
+	"time"
+)
+
+type sample struct {
+	at     time.Time
+	weight float64
+	motion bool
+}
+
+// stabilityDetector 判断读数是否稳定:
+// 窗口内至少覆盖 window 时长、所有读数与最新读数的差值不超过 tolerance，且仪表未报告晃动
+type stabilityDetector struct {
+	window    time.Duration
+	tolerance float64
+	samples   []sample
+}
+
+func newStabilityDetector(window time.Duration, tolerance float64) *stabilityDetector {
+	return &stabilityDetector{window: window, tolerance: tolerance}
+}
+
+// Add 记录一次读数并返回当前是否稳定
+func (d *stabilityDetector) Add(at time.Time, frame Frame) bool {
+	d.samples = append(d.samples, sample{at: at, weight: frame.Weight, motion: frame.Motion})
+
+	// 保留覆盖整个窗口所需的最早一个读数，其余更早的丢弃
+	cutoff := at.Add(-d.window)
+	drop := 0
+	for drop+1 < len(d.samples) && !d.samples[drop+1].at.After(cutoff) {
+		drop++
+	}
+	d.samples = d.samples[drop:]
+
+	if at.Sub(d.samples[0].at) < d.window {
+		return false
+	}
+	for _, s := range d.samples {
+		if s.motion || abs(s.weight-frame.Weight) > d.tolerance {
+			return false
+		}
+	}
+	return true
+}
+
+// Reset 清空历史读数 (如连接断开后)
+func (d *stabilityDetector) Reset() {
+	d.samples = d.samples[:0]
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
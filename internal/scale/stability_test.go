@@ -0,0 +1,106 @@
+package scale
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStabilityDetectorAdd(t *testing.T) {
+	type step struct {
+		ms     int
+		weight float64
+		motion bool
+		want   bool
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "single reading",
+			steps: []step{
+				{ms: 0, weight: 1000, want: false},
+			},
+		},
+		{
+			name: "steady for the whole window",
+			steps: []step{
+				{ms: 0, weight: 1000},
+				{ms: 500, weight: 1000},
+				{ms: 1000, weight: 1000},
+				{ms: 1500, weight: 1000},
+				{ms: 2000, weight: 1000, want: true},
+				{ms: 2500, weight: 1000, want: true},
+			},
+		},
+		{
+			name: "variation within tolerance",
+			steps: []step{
+				{ms: 0, weight: 1000},
+				{ms: 1000, weight: 1015},
+				{ms: 2000, weight: 995, want: true},
+			},
+		},
+		{
+			name: "spike beyond tolerance until it leaves the window",
+			steps: []step{
+				{ms: 0, weight: 1000},
+				{ms: 500, weight: 1000},
+				{ms: 1000, weight: 1100},
+				{ms: 1500, weight: 1000},
+				{ms: 2000, weight: 1000, want: false},
+				{ms: 3000, weight: 1000, want: false},
+				{ms: 3500, weight: 1000, want: true},
+			},
+		},
+		{
+			name: "motion reported by the indicator",
+			steps: []step{
+				{ms: 0, weight: 1000, motion: true},
+				{ms: 500, weight: 1000},
+				{ms: 1000, weight: 1000},
+				{ms: 2000, weight: 1000, want: false},
+				{ms: 2500, weight: 1000, want: true},
+			},
+		},
+		{
+			name: "gap longer than the window",
+			steps: []step{
+				{ms: 0, weight: 800},
+				{ms: 5000, weight: 1000, want: false},
+				{ms: 7000, weight: 1000, want: true},
+			},
+		},
+	}
+
+	start := time.Date(2024, 1, 1, 8, 0, 0, 0, time.Local)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newStabilityDetector(2*time.Second, 20)
+			for _, s := range tt.steps {
+				at := start.Add(time.Duration(s.ms) * time.Millisecond)
+				if got := d.Add(at, Frame{Weight: s.weight, Motion: s.motion}); got != s.want {
+					t.Fatalf("Add at %dms (%.0f kg) = %v, want %v", s.ms, s.weight, got, s.want)
+				}
+			}
+		})
+	}
+}
+
+func TestStabilityDetectorReset(t *testing.T) {
+	d := newStabilityDetector(2*time.Second, 20)
+	start := time.Date(2024, 1, 1, 8, 0, 0, 0, time.Local)
+	d.Add(start, Frame{Weight: 1000})
+	if !d.Add(start.Add(2*time.Second), Frame{Weight: 1000}) {
+		t.Fatal("expected stable before reset")
+	}
+
+	d.Reset()
+	if d.Add(start.Add(3*time.Second), Frame{Weight: 1000}) {
+		t.Fatal("expected unstable right after reset")
+	}
+	if !d.Add(start.Add(5*time.Second), Frame{Weight: 1000}) {
+		t.Fatal("expected stable once the window is covered again")
+	}
+}
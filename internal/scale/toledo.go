@@ -0,0 +1,85 @@
+package scale
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// toledoFrameLen 梅特勒-托利多连续输出帧长度 (不含可选校验字节):
+// STX, 状态字 A/B/C, 6 位显示重量, 6 位皮重, CR
+const toledoFrameLen = 17
+
+// 状态字 B 的标志位
+const (
+	toledoNegative   = 0x02
+	toledoOverRange  = 0x04
+	toledoMotion     = 0x08
+	toledoKilograms  = 0x10
+	toledoPoundsToKg = 0.45359237
+)
+
+// toledoProtocol 梅特勒-托利多 (Mettler Toledo) 标准连续输出协议
+type toledoProtocol struct{}
+
+func (toledoProtocol) Name() string { return "toledo" }
+
+func (toledoProtocol) Split(data []byte, atEOF bool) (int, []byte, error) {
+	start := bytes.IndexByte(data, stx)
+	if start < 0 {
+		return len(data), nil, nil
+	}
+	if len(data)-start < toledoFrameLen {
+		return start, nil, nil
+	}
+	frame := data[start : start+toledoFrameLen]
+	if frame[toledoFrameLen-1] != '\r' {
+		return start + 1, nil, nil
+	}
+	// 帧后的校验字节 (如有) 在查找下一个帧头时跳过
+	return start + toledoFrameLen, frame, nil
+}
+
+func (toledoProtocol) Parse(frame []byte) (Frame, error) {
+	if len(frame) != toledoFrameLen || frame[0] != stx || frame[toledoFrameLen-1] != '\r' {
+		return Frame{}, errors.New("toledo: malformed frame")
+	}
+	swa, swb := frame[1], frame[2]
+	if swb&toledoOverRange != 0 {
+		return Frame{}, errors.New("toledo: weight out of range")
+	}
+
+	value, err := strconv.Atoi(string(bytes.TrimSpace(frame[4:10])))
+	if err != nil {
+		return Frame{}, fmt.Errorf("toledo: invalid weight %q", frame[4:10])
+	}
+
+	// 状态字 A 低 3 位为小数点位置: 0 → ×100, 1 → ×10, 2 → ×1, 3 → 0.1, ... 7 → 0.00001
+	weight := float64(value) * math.Pow10(2-int(swa&0x07))
+	if swb&toledoNegative != 0 {
+		weight = -weight
+	}
+	if swb&toledoKilograms == 0 {
+		weight *= toledoPoundsToKg
+	}
+	return Frame{Weight: weight, Motion: swb&toledoMotion != 0}, nil
+}
+
+func (toledoProtocol) Format(frame Frame) []byte {
+	// 状态字 A: 小数点位置 2 (整数)，固定位 0x20
+	swa := byte(0x20 | 0x02)
+	swb := byte(0x20 | toledoKilograms)
+	if frame.Weight < 0 {
+		swb |= toledoNegative
+	}
+	if frame.Motion {
+		swb |= toledoMotion
+	}
+	swc := byte(0x20)
+
+	out := []byte{stx, swa, swb, swc}
+	out = append(out, []byte(fmt.Sprintf("%06d%06d", int64(math.Round(math.Abs(frame.Weight))), 0))...)
+	return append(out, '\r')
+}
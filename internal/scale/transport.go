@@ -0,0 +1,43 @@
+package scale
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+const dialTimeout = 5 * time.Second
+
+// dial 按配置的连接方式打开仪表数据流
+func (s *Scale) dial() (io.ReadCloser, error) {
+	switch s.cfg.Transport {
+	case "serial":
+		return openSerial(s.cfg.Address, s.cfg.BaudRate)
+	case "tcp":
+		// 串口服务器 (如 USR、MOXA) 将仪表串口透传为 TCP
+		conn, err := net.DialTimeout("tcp", s.cfg.Address, dialTimeout)
+		if err != nil {
+			return nil, err
+		}
+		return &deadlineConn{Conn: conn, timeout: time.Duration(s.cfg.StaleAfterMs) * time.Millisecond}, nil
+	case "simulator":
+		return newSimulator(s.protocol), nil
+	default:
+		return nil, fmt.Errorf("unknown scale transport %q", s.cfg.Transport)
+	}
+}
+
+// deadlineConn 每次读取前设置读超时：串口服务器断电或 NAT 超时后连接不会关闭，
+// 超过 timeout 没有数据时读取返回超时错误，由 readLoop 断开重连
+type deadlineConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (c *deadlineConn) Read(p []byte) (int, error) {
+	if err := c.Conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+	return c.Conn.Read(p)
+}
@@ -0,0 +1,94 @@
+package scale
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+const (
+	stx = 0x02
+	etx = 0x03
+)
+
+// yaohuaFrameLen 耀华 XK3190 系列连续输出 (tF=0) 帧长度:
+// STX, 符号, 6 位重量, 小数位数, 2 位异或校验, ETX
+const yaohuaFrameLen = 12
+
+// yaohuaProtocol 耀华 XK3190 系列仪表连续输出协议，国内地磅最常见的输出格式
+type yaohuaProtocol struct{}
+
+func (yaohuaProtocol) Name() string { return "yaohua" }
+
+func (yaohuaProtocol) Split(data []byte, atEOF bool) (int, []byte, error) {
+	start := bytes.IndexByte(data, stx)
+	if start < 0 {
+		return len(data), nil, nil
+	}
+	if len(data)-start < yaohuaFrameLen {
+		return start, nil, nil
+	}
+	frame := data[start : start+yaohuaFrameLen]
+	if frame[yaohuaFrameLen-1] != etx {
+		// 不是完整帧，从下一个字节重新查找帧头
+		return start + 1, nil, nil
+	}
+	return start + yaohuaFrameLen, frame, nil
+}
+
+func (yaohuaProtocol) Parse(frame []byte) (Frame, error) {
+	if len(frame) != yaohuaFrameLen || frame[0] != stx || frame[yaohuaFrameLen-1] != etx {
+		return Frame{}, errors.New("yaohua: malformed frame")
+	}
+	if checksum := yaohuaChecksum(frame[1:9]); !bytes.Equal(checksum, frame[9:11]) {
+		return Frame{}, fmt.Errorf("yaohua: checksum mismatch, got %q want %q", frame[9:11], checksum)
+	}
+
+	value, err := strconv.Atoi(string(frame[2:8]))
+	if err != nil {
+		return Frame{}, fmt.Errorf("yaohua: invalid weight %q", frame[2:8])
+	}
+	decimals := int(frame[8] - '0')
+	if decimals < 0 || decimals > 4 {
+		return Frame{}, fmt.Errorf("yaohua: invalid decimal places %q", frame[8])
+	}
+
+	weight := float64(value) / math.Pow10(decimals)
+	switch frame[1] {
+	case '+':
+	case '-':
+		weight = -weight
+	default:
+		return Frame{}, fmt.Errorf("yaohua: invalid sign %q", frame[1])
+	}
+	return Frame{Weight: weight}, nil
+}
+
+func (yaohuaProtocol) Format(frame Frame) []byte {
+	sign := byte('+')
+	if frame.Weight < 0 {
+		sign = '-'
+	}
+	body := []byte(fmt.Sprintf("%c%06d0", sign, int64(math.Round(math.Abs(frame.Weight)))))
+	out := append([]byte{stx}, body...)
+	out = append(out, yaohuaChecksum(body)...)
+	return append(out, etx)
+}
+
+// yaohuaChecksum 异或校验，高低 4 位分别编码为一个字符 (0-9 为 '0'-'9'，10-15 为 'A'-'F')
+func yaohuaChecksum(body []byte) []byte {
+	var sum byte
+	for _, b := range body {
+		sum ^= b
+	}
+	return []byte{hexDigit(sum >> 4), hexDigit(sum & 0x0F)}
+}
+
+func hexDigit(n byte) byte {
+	if n <= 9 {
+		return '0' + n
+	}
+	return 'A' + n - 10
+}
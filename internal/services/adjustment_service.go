@@ -0,0 +1,152 @@
+package services
+
+import (
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/repository"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// AdjustmentService 库存调整服务
+type AdjustmentService struct {
+	repos *repository.Repositories
+	repo  *repository.AdjustmentRepository
+	// approvalThresholdKg 调整重量超过该值时需要超级管理员审核
+	approvalThresholdKg float64
+}
+
+// NewAdjustmentService 创建库存调整服务实例
+func NewAdjustmentService(repos *repository.Repositories, approvalThresholdKg float64) *AdjustmentService {
+	return &AdjustmentService{
+		repos:               repos,
+		repo:                repos.AdjustmentRepo,
+		approvalThresholdKg: approvalThresholdKg,
+	}
+}
+
+// Create 创建库存调整单，未超过审核阈值时直接记入库存
+func (s *AdjustmentService) Create(req *models.CreateInventoryAdjustmentRequest, userID uint) (*models.InventoryAdjustment, error) {
+	if _, err := s.repos.WarehouseRepo.GetByID(req.WarehouseID); err != nil {
+		return nil, errors.New("warehouse not found")
+	}
+	if _, err := s.repos.CategoryRepo.GetByID(req.CategoryID); err != nil {
+		return nil, errors.New("category not found")
+	}
+
+	adjustmentNo, err := s.repo.GenerateAdjustmentNo()
+	if err != nil {
+		return nil, err
+	}
+
+	adjustment := &models.InventoryAdjustment{
+		AdjustmentNo:     adjustmentNo,
+		WarehouseID:      req.WarehouseID,
+		CategoryID:       req.CategoryID,
+		Direction:        req.Direction,
+		Weight:           req.Weight,
+		Reason:           req.Reason,
+		Notes:            req.Notes,
+		AttachmentURL:    req.AttachmentURL,
+		Status:           models.AdjustmentStatusPending,
+		RequiresApproval: req.Weight > s.approvalThresholdKg,
+		CreatedBy:        userID,
+	}
+
+	if !adjustment.RequiresApproval {
+		adjustment.Status = models.AdjustmentStatusApproved
+	}
+
+	err = s.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.AdjustmentRepo.Create(adjustment); err != nil {
+			return err
+		}
+		if adjustment.RequiresApproval {
+			return nil
+		}
+		return postAdjustment(tx, adjustment, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return adjustment, nil
+}
+
+// GetByID 根据ID获取库存调整单
+func (s *AdjustmentService) GetByID(id uint) (*models.InventoryAdjustment, error) {
+	return s.repo.GetByID(id)
+}
+
+// GetAll 分页获取库存调整单
+func (s *AdjustmentService) GetAll(req *models.GetInventoryAdjustmentsRequest) (*models.GetInventoryAdjustmentsResponse, error) {
+	adjustments, total, err := s.repo.GetAll(req)
+	if err != nil {
+		return nil, err
+	}
+	return &models.GetInventoryAdjustmentsResponse{
+		Adjustments: adjustments,
+		Total:       total,
+	}, nil
+}
+
+// Approve 审核待审核的库存调整单并记入库存
+func (s *AdjustmentService) Approve(id uint, req *models.ReviewInventoryAdjustmentRequest, userID uint) error {
+	return s.review(id, req, userID, models.AdjustmentStatusApproved)
+}
+
+// Reject 驳回待审核的库存调整单，不调整库存
+func (s *AdjustmentService) Reject(id uint, req *models.ReviewInventoryAdjustmentRequest, userID uint) error {
+	return s.review(id, req, userID, models.AdjustmentStatusRejected)
+}
+
+// review 审核或驳回库存调整单
+func (s *AdjustmentService) review(id uint, req *models.ReviewInventoryAdjustmentRequest, userID uint, status string) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		adjustment, err := tx.AdjustmentRepo.GetByIDForUpdate(id)
+		if err != nil {
+			return errors.New("adjustment not found")
+		}
+		if adjustment.Status != models.AdjustmentStatusPending {
+			return fmt.Errorf("adjustment is %s, cannot be reviewed", adjustment.Status)
+		}
+
+		if status == models.AdjustmentStatusApproved {
+			if err := postAdjustment(tx, adjustment, userID); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		return tx.AdjustmentRepo.UpdateFields(id, map[string]interface{}{
+			"status":       status,
+			"reviewed_by":  userID,
+			"reviewed_at":  now,
+			"review_notes": req.ReviewNotes,
+			"updated_at":   now,
+		})
+	})
+}
+
+// postAdjustment 通过库存统一入口记入调整重量，盘点中的分类不允许调整
+func postAdjustment(tx *repository.Repositories, adjustment *models.InventoryAdjustment, userID uint) error {
+	if err := checkStocktakeLock(tx, adjustment.WarehouseID, []uint{adjustment.CategoryID}); err != nil {
+		return err
+	}
+
+	source := models.MovementSource{
+		SourceType: models.MovementSourceAdjustment,
+		SourceID:   adjustment.ID,
+		UserID:     userID,
+		Reason:     adjustment.Reason,
+	}
+	isInbound := adjustment.Direction == models.AdjustmentDirectionIncrease
+	if err := tx.InventoryRepo.UpdateWeight(adjustment.WarehouseID, adjustment.CategoryID, adjustment.Weight, isInbound, source); err != nil {
+		return err
+	}
+
+	// 减少的重量按先进先出从批次中扣除
+	if isInbound {
+		return nil
+	}
+	return allocateLots(tx, adjustment.WarehouseID, adjustment.CategoryID, adjustment.Weight, source, 0)
+}
@@ -7,41 +7,78 @@ import (
 
 // CategoryService 类别服务 (不再使用接口)
 type CategoryService struct {
+	repos         *repository.Repositories
 	categoryRepo  *repository.CategoryRepository
 	inventoryRepo *repository.InventoryRepository
+	warehouseRepo *repository.WarehouseRepository
 }
 
 // NewCategoryService 创建类别服务实例
-func NewCategoryService(categoryRepo *repository.CategoryRepository, inventoryRepo *repository.InventoryRepository) *CategoryService {
+func NewCategoryService(repos *repository.Repositories) *CategoryService {
 	return &CategoryService{
-		categoryRepo:  categoryRepo,
-		inventoryRepo: inventoryRepo,
+		repos:         repos,
+		categoryRepo:  repos.CategoryRepo,
+		inventoryRepo: repos.InventoryRepo,
+		warehouseRepo: repos.WarehouseRepo,
 	}
 }
 
-// Create 创建分类
-func (s *CategoryService) Create(category *models.BatteryCategory) error {
+// Create 创建分类，填写单价时同时记录为立即生效的收购价
+func (s *CategoryService) Create(category *models.BatteryCategory, userID uint) error {
+	if category.CostingMethod == "" {
+		category.CostingMethod = models.CostingMethodMovingAverage
+	}
+
 	// 创建分类
 	if err := s.categoryRepo.Create(category); err != nil {
 		return err
 	}
 
-	// 为新分类初始化库存记录
-	inventory := &models.Inventory{
-		CategoryID:      category.ID,
-		CurrentWeightKg: 0,
+	// 为新分类在各仓库初始化库存记录
+	warehouses, err := s.warehouseRepo.GetAll()
+	if err != nil {
+		return err
+	}
+	for _, warehouse := range warehouses {
+		inventory := &models.Inventory{
+			WarehouseID:     warehouse.ID,
+			CategoryID:      category.ID,
+			CurrentWeightKg: 0,
+		}
+		if err := s.inventoryRepo.Create(inventory); err != nil {
+			return err
+		}
+	}
+
+	if category.UnitPrice > 0 {
+		return recordBuyPrice(s.repos, category.ID, category.UnitPrice, "", userID)
 	}
-	return s.inventoryRepo.Create(inventory)
+	return nil
 }
 
-// GetByID 根据ID获取分类
+// GetByID 根据ID获取分类，单价为当前有效的收购价
 func (s *CategoryService) GetByID(id uint) (*models.BatteryCategory, error) {
-	return s.categoryRepo.GetByID(id)
+	category, err := s.categoryRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	categories := []models.BatteryCategory{*category}
+	if err := applyCurrentBuyPrices(s.repos, categories); err != nil {
+		return nil, err
+	}
+	return &categories[0], nil
 }
 
-// GetAll 获取所有分类
+// GetAll 获取所有分类，单价为当前有效的收购价
 func (s *CategoryService) GetAll() ([]models.BatteryCategory, error) {
-	return s.categoryRepo.GetAll()
+	categories, err := s.categoryRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	if err := applyCurrentBuyPrices(s.repos, categories); err != nil {
+		return nil, err
+	}
+	return categories, nil
 }
 
 // UpdateName 显式更新分类名称
@@ -54,9 +91,11 @@ func (s *CategoryService) UpdateDescription(id uint, description string) error {
 	return s.categoryRepo.UpdateDescription(id, description)
 }
 
-// UpdateUnitPrice 显式更新单价
-func (s *CategoryService) UpdateUnitPrice(id uint, unitPrice float64) error {
-	return s.categoryRepo.UpdateUnitPrice(id, unitPrice)
+// UpdateUnitPrice 显式更新单价，记录为立即生效的收购价并保留价格历史
+func (s *CategoryService) UpdateUnitPrice(id uint, unitPrice float64, userID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		return recordBuyPrice(tx, id, unitPrice, "", userID)
+	})
 }
 
 // UpdateCategory 显式更新分类字段
@@ -64,6 +103,11 @@ func (s *CategoryService) UpdateCategory(id uint, updates map[string]interface{}
 	return s.categoryRepo.UpdateFields(id, updates)
 }
 
+// UpdateCostingMethod 切换分类的库存计价方法
+func (s *CategoryService) UpdateCostingMethod(id uint, method string) error {
+	return s.inventoryRepo.ChangeCostingMethod(id, method)
+}
+
 // Delete 软删除分类
 func (s *CategoryService) Delete(id uint) error {
 	return s.categoryRepo.Delete(id)
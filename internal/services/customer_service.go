@@ -0,0 +1,169 @@
+package services
+
+import (
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/repository"
+	"errors"
+	"strings"
+)
+
+// CustomerService 客户服务
+type CustomerService struct {
+	repos *repository.Repositories
+	repo  *repository.CustomerRepository
+}
+
+// NewCustomerService 创建客户服务实例
+func NewCustomerService(repos *repository.Repositories) *CustomerService {
+	return &CustomerService{
+		repos: repos,
+		repo:  repos.CustomerRepo,
+	}
+}
+
+// Create 创建客户及其地址
+func (s *CustomerService) Create(req *models.CreateCustomerRequest) (*models.Customer, error) {
+	customer := &models.Customer{
+		Name:          req.Name,
+		ContactPerson: req.ContactPerson,
+		Phone:         req.Phone,
+		TaxID:         req.TaxID,
+		Notes:         req.Notes,
+		CreditLimit:   roundAmount(req.CreditLimit),
+		IsActive:      true,
+	}
+
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.CustomerRepo.Create(customer); err != nil {
+			return err
+		}
+		addresses := buildCustomerAddresses(req.Addresses)
+		if err := tx.CustomerRepo.ReplaceAddresses(customer.ID, addresses); err != nil {
+			return err
+		}
+		customer.Addresses = addresses
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return customer, nil
+}
+
+// GetByID 根据ID获取客户 (包含地址)
+func (s *CustomerService) GetByID(id uint) (*models.Customer, error) {
+	customer, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	addresses, err := s.repo.GetAddresses(id)
+	if err != nil {
+		return nil, err
+	}
+	customer.Addresses = addresses
+	return customer, nil
+}
+
+// Search 根据名称、联系人或电话搜索客户
+func (s *CustomerService) Search(keyword string) ([]models.Customer, error) {
+	return s.repo.Search(strings.TrimSpace(keyword))
+}
+
+// Update 更新客户信息，请求包含地址时整体替换地址
+func (s *CustomerService) Update(id uint, req *models.UpdateCustomerRequest) (*models.Customer, error) {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return nil, errors.New("customer not found")
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != "" {
+		updates["name"] = req.Name
+	}
+	if req.ContactPerson != "" {
+		updates["contact_person"] = req.ContactPerson
+	}
+	if req.Phone != "" {
+		updates["phone"] = req.Phone
+	}
+	if req.TaxID != "" {
+		updates["tax_id"] = req.TaxID
+	}
+	if req.Notes != "" {
+		updates["notes"] = req.Notes
+	}
+	if req.CreditLimit != nil {
+		updates["credit_limit"] = roundAmount(*req.CreditLimit)
+	}
+
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		if len(updates) > 0 {
+			if err := tx.CustomerRepo.UpdateFields(id, updates); err != nil {
+				return err
+			}
+		}
+		if req.Addresses != nil {
+			return tx.CustomerRepo.ReplaceAddresses(id, buildCustomerAddresses(req.Addresses))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetByID(id)
+}
+
+// Delete 软删除客户
+func (s *CustomerService) Delete(id uint) error {
+	return s.repo.Delete(id)
+}
+
+// GetOrderHistory 获取客户的历史出库订单和汇总
+func (s *CustomerService) GetOrderHistory(id uint, req *models.GetCustomerOrdersRequest) (*models.CustomerOrderHistory, error) {
+	customer, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("customer not found")
+	}
+
+	orders, total, err := s.repos.OutboundRepo.GetAllWithConditions(&models.GetOutboundOrderRequest{
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		StartDate:  req.StartDate,
+		EndDate:    req.EndDate,
+		CustomerID: id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	totalWeight, totalAmount, err := s.repos.OutboundRepo.GetCustomerTotals(id, req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CustomerOrderHistory{
+		Customer:    *customer,
+		Orders:      orders,
+		Total:       total,
+		TotalWeight: totalWeight,
+		TotalAmount: totalAmount,
+	}, nil
+}
+
+// buildCustomerAddresses 将请求中的地址转换为模型，保证最多一个默认地址
+func buildCustomerAddresses(inputs []models.CustomerAddressInput) []models.CustomerAddress {
+	addresses := make([]models.CustomerAddress, 0, len(inputs))
+	hasDefault := false
+	for _, input := range inputs {
+		isDefault := input.IsDefault && !hasDefault
+		hasDefault = hasDefault || isDefault
+		addresses = append(addresses, models.CustomerAddress{
+			Label:     input.Label,
+			Address:   input.Address,
+			IsDefault: isDefault,
+		})
+	}
+	if !hasDefault && len(addresses) > 0 {
+		addresses[0].IsDefault = true
+	}
+	return addresses
+}
@@ -3,77 +3,139 @@ package services
 import (
 	"battery-erp-backend/internal/models"
 	"battery-erp-backend/internal/repository"
+	"errors"
+	"fmt"
+	"math"
+	"time"
 )
 
 // InboundService 入库服务 (不再使用接口)
 type InboundService struct {
+	repos         *repository.Repositories
 	inboundRepo   *repository.InboundRepository
 	inventoryRepo *repository.InventoryRepository
+	// priceDeviationPercent 单价偏离价目表超过该百分比时标记订单
+	priceDeviationPercent float64
 }
 
 // NewInboundService 创建入库服务实例
-func NewInboundService(inboundRepo *repository.InboundRepository, inventoryRepo *repository.InventoryRepository) *InboundService {
+func NewInboundService(repos *repository.Repositories, priceDeviationPercent float64) *InboundService {
 	return &InboundService{
-		inboundRepo:   inboundRepo,
-		inventoryRepo: inventoryRepo,
+		repos:                 repos,
+		inboundRepo:           repos.InboundRepo,
+		inventoryRepo:         repos.InventoryRepo,
+		priceDeviationPercent: priceDeviationPercent,
 	}
 }
 
 // Create 创建入库订单
 func (s *InboundService) Create(req *models.CreateInboundOrderRequest, createdBy uint) (*models.InboundOrder, error) {
+	var order *models.InboundOrder
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		var err error
+		order, err = s.create(tx, req, createdBy)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// create 在事务中创建入库订单，订单头、订单项和库存变动一起提交
+func (s *InboundService) create(tx *repository.Repositories, req *models.CreateInboundOrderRequest, createdBy uint) (*models.InboundOrder, error) {
 	// Generate order number
-	orderNo, err := s.inboundRepo.GenerateOrderNo()
+	orderNo, err := tx.InboundRepo.GenerateOrderNo()
 	if err != nil {
 		return nil, err
 	}
 
-	// Calculate item totals
+	if _, err := tx.WarehouseRepo.GetByID(req.WarehouseID); err != nil {
+		return nil, errors.New("warehouse not found")
+	}
+
+	// 指定卖家时以卖家名称作为供应商名称快照
+	supplierName := req.SupplierName
+	if req.SellerID != nil {
+		seller, err := tx.SellerRepo.GetByID(*req.SellerID)
+		if err != nil {
+			return nil, errors.New("seller not found")
+		}
+		supplierName = seller.Name
+	}
+
+	// Calculate item totals (未填写单价时取当前收购价)
 	var totalAmount float64
 	var orderItems []models.InboundOrderItem
+	var priceFlagged bool
+	now := time.Now()
 
 	for _, reqItem := range req.Items {
-		netWeight := reqItem.GrossWeight - reqItem.TareWeight
-		subTotal := netWeight * reqItem.UnitPrice
-		totalAmount += subTotal
-
+		unitPrice, listPrice, err := resolveUnitPrice(tx, reqItem.CategoryID, models.PriceTypeBuy, now, reqItem.UnitPrice)
+		if err != nil {
+			return nil, err
+		}
+		if err := validateInboundItem(reqItem.CategoryID, reqItem.GrossWeight, reqItem.TareWeight, unitPrice); err != nil {
+			return nil, err
+		}
+		if err := validateDeductionInput(&reqItem.InboundDeductions); err != nil {
+			return nil, err
+		}
 		orderItem := models.InboundOrderItem{
-			CategoryID:  reqItem.CategoryID,
-			GrossWeight: reqItem.GrossWeight,
-			TareWeight:  reqItem.TareWeight,
-			NetWeight:   netWeight,
-			UnitPrice:   reqItem.UnitPrice,
-			SubTotal:    subTotal,
+			CategoryID:        reqItem.CategoryID,
+			GrossWeight:       reqItem.GrossWeight,
+			TareWeight:        reqItem.TareWeight,
+			GrossFromScale:    reqItem.GrossFromScale,
+			TareFromScale:     reqItem.TareFromScale,
+			UnitPrice:         unitPrice,
+			ListPrice:         listPrice,
+			Grade:             reqItem.Grade,
+			InboundDeductions: reqItem.InboundDeductions,
+		}
+		if err := calcInboundItem(&orderItem); err != nil {
+			return nil, err
 		}
+		totalAmount += orderItem.SubTotal
+		priceFlagged = priceFlagged || priceDeviates(unitPrice, listPrice, s.priceDeviationPercent)
 		orderItems = append(orderItems, orderItem)
 	}
 
 	// Create order
 	order := &models.InboundOrder{
-		OrderNo:      orderNo,
-		SupplierName: req.SupplierName,
-		TotalAmount:  totalAmount,
-		Status:       "completed",
-		Notes:        req.Notes,
-		CreatedBy:    createdBy,
+		OrderNo:       orderNo,
+		WarehouseID:   req.WarehouseID,
+		SellerID:      req.SellerID,
+		SupplierName:  supplierName,
+		TotalAmount:   totalAmount,
+		PaymentStatus: models.OrderPaymentUnpaid,
+		Status:        initialOrderStatus(req.Status),
+		Notes:         req.Notes,
+		PriceFlagged:  priceFlagged,
+		WeighTicketID: req.WeighTicketID,
+		CreatedBy:     createdBy,
 	}
 
-	if err := s.inboundRepo.Create(order); err != nil {
+	if err := tx.InboundRepo.Create(order); err != nil {
 		return nil, err
 	}
 
 	// Create order items
 	for i := range orderItems {
 		orderItems[i].OrderID = order.ID
-		if err := s.inboundRepo.CreateItem(&orderItems[i]); err != nil {
+		if err := tx.InboundRepo.CreateItem(&orderItems[i]); err != nil {
 			return nil, err
 		}
-
-		// Update inventory
-		if err := s.inventoryRepo.UpdateWeight(orderItems[i].CategoryID, orderItems[i].NetWeight, true); err != nil {
+		if err := saveItemTraceCodes(tx, models.MovementSourceInbound, order.ID, orderItems[i].ID, req.Items[i].TraceCodes); err != nil {
 			return nil, err
 		}
 	}
 
+	// Update inventory (草稿不记入库存)
+	if isStockPosted(order.Status) {
+		if err := s.postStock(tx, order, createdBy); err != nil {
+			return nil, err
+		}
+	}
 	return order, nil
 }
 
@@ -97,9 +159,31 @@ func (s *InboundService) GetAll(req *models.GetInboundOrderRequest) ([]models.In
 	return s.inboundRepo.GetAllWithConditions(req)
 }
 
+// Confirm 确认草稿订单并记入库存
+func (s *InboundService) Confirm(id uint, userID uint) error {
+	return s.UpdateStatus(id, models.OrderStatusConfirmed, userID)
+}
+
+// Complete 完成已确认的订单
+func (s *InboundService) Complete(id uint, userID uint) error {
+	return s.UpdateStatus(id, models.OrderStatusCompleted, userID)
+}
+
+// Cancel 取消订单，已记入库存的订单同时冲回库存
+func (s *InboundService) Cancel(id uint, userID uint) error {
+	return s.UpdateStatus(id, models.OrderStatusCancelled, userID)
+}
+
 // UpdateStatus 显式更新订单状态
-func (s *InboundService) UpdateStatus(id uint, status string) error {
-	return s.inboundRepo.UpdateStatus(id, status)
+// 按订单状态机校验流转：确认时记入库存，取消已记账订单时冲回库存
+func (s *InboundService) UpdateStatus(id uint, status string, userID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		order, err := lockInboundOrder(tx, id)
+		if err != nil {
+			return err
+		}
+		return s.applyStatus(tx, order, status, userID)
+	})
 }
 
 // UpdateSupplierName 显式更新供应商名称
@@ -118,6 +202,484 @@ func (s *InboundService) UpdateOrder(id uint, updates map[string]interface{}) er
 }
 
 // Delete 删除入库订单
-func (s *InboundService) Delete(id uint) error {
-	return s.inboundRepo.Delete(id)
+// 已记入库存的订单删除前先冲回入库重量
+func (s *InboundService) Delete(id uint, userID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		order, err := lockInboundOrder(tx, id)
+		if err != nil {
+			return err
+		}
+		if order.PaidAmount >= amountTolerance {
+			return errors.New("order has supplier payments, void them first")
+		}
+
+		if isStockPosted(order.Status) {
+			if err := s.reverseStock(tx, order, userID); err != nil {
+				return err
+			}
+		}
+		return tx.InboundRepo.Delete(id)
+	})
+}
+
+// UpdateOrderComplete 完整更新入库订单（包括订单项）
+// 订单项按 action 新增/修改/删除，未提及的订单项保持不变；
+// 重新计算净重、小计和总金额，已记账订单按分类把净重差额记入库存
+func (s *InboundService) UpdateOrderComplete(id uint, req *models.UpdateInboundOrderRequest, userID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		order, err := lockInboundOrder(tx, id)
+		if err != nil {
+			return err
+		}
+		if order.Status == models.OrderStatusCancelled {
+			return errors.New("cancelled order cannot be modified")
+		}
+
+		currentItems, err := tx.InboundRepo.GetRawItemsByOrderID(id)
+		if err != nil {
+			return err
+		}
+
+		// 修改前各分类的净重和金额，用于按原入库成本冲减
+		prevWeights, prevAmounts := inboundCategoryTotals(currentItems)
+
+		items := make(map[uint]*models.InboundOrderItem, len(currentItems))
+		deltas := make(map[uint]float64)
+		for i := range currentItems {
+			items[currentItems[i].ID] = &currentItems[i]
+			deltas[currentItems[i].CategoryID] -= currentItems[i].NetWeight
+		}
+
+		for _, reqItem := range req.Items {
+			action := reqItem.Action
+			if action == "" {
+				action = "update"
+				if reqItem.ID == 0 {
+					action = "add"
+				}
+			}
+
+			if action != "add" {
+				if _, ok := items[reqItem.ID]; !ok {
+					return fmt.Errorf("item %d does not belong to order", reqItem.ID)
+				}
+			}
+
+			switch action {
+			case "delete":
+				if isStockPosted(order.Status) {
+					if err := deleteInboundLot(tx, reqItem.ID); err != nil {
+						return err
+					}
+					if err := reverseTraceCodeReceipt(tx, order, reqItem.ID, userID); err != nil {
+						return err
+					}
+				}
+				if err := tx.TraceCodeRepo.DeleteItemCodes(models.MovementSourceInbound, id, reqItem.ID); err != nil {
+					return err
+				}
+				if err := tx.InboundRepo.DeleteItem(reqItem.ID); err != nil {
+					return err
+				}
+				delete(items, reqItem.ID)
+			case "add", "update":
+				// 未填写单价时取下单时的收购价
+				unitPrice, listPrice, err := resolveUnitPrice(tx, reqItem.CategoryID, models.PriceTypeBuy, order.CreatedAt, reqItem.UnitPrice)
+				if err != nil {
+					return err
+				}
+				if err := validateInboundItem(reqItem.CategoryID, reqItem.GrossWeight, reqItem.TareWeight, unitPrice); err != nil {
+					return err
+				}
+				if err := validateDeductionInput(&reqItem.InboundDeductions); err != nil {
+					return err
+				}
+
+				item := &models.InboundOrderItem{OrderID: id}
+				if action == "update" {
+					item = items[reqItem.ID]
+				}
+				prevCategoryID := item.CategoryID
+				// 手工修改了地磅采集的重量时取消地磅采集标记
+				if math.Abs(item.GrossWeight-reqItem.GrossWeight) >= weightTolerance {
+					item.GrossFromScale = false
+				}
+				if math.Abs(item.TareWeight-reqItem.TareWeight) >= weightTolerance {
+					item.TareFromScale = false
+				}
+				item.CategoryID = reqItem.CategoryID
+				item.GrossWeight = reqItem.GrossWeight
+				item.TareWeight = reqItem.TareWeight
+				item.UnitPrice = unitPrice
+				item.ListPrice = listPrice
+				item.Grade = reqItem.Grade
+				item.InboundDeductions = reqItem.InboundDeductions
+				if err := calcInboundItem(item); err != nil {
+					return err
+				}
+
+				if action == "add" {
+					if err := tx.InboundRepo.CreateItem(item); err != nil {
+						return err
+					}
+					items[item.ID] = item
+				} else if err := tx.InboundRepo.UpdateItem(item); err != nil {
+					return err
+				}
+
+				// 已记账订单同步批次
+				if isStockPosted(order.Status) {
+					if action == "add" {
+						err = createInboundLot(tx, order, item)
+					} else {
+						err = syncInboundLot(tx, order, item)
+					}
+					if err != nil {
+						return err
+					}
+				}
+
+				// 替换溯源编码或修改分类时重新登记该订单项的编码
+				if action == "add" || reqItem.TraceCodes != nil || prevCategoryID != item.CategoryID {
+					if err := s.updateItemTraceCodes(tx, order, item, action, reqItem.TraceCodes, userID); err != nil {
+						return err
+					}
+				}
+			default:
+				return fmt.Errorf("invalid item action: %s", action)
+			}
+		}
+
+		if len(items) == 0 {
+			return errors.New("order must have at least one item")
+		}
+
+		// 重新计算总金额、价格偏离标记和各分类净重差额
+		var totalAmount float64
+		var priceFlagged bool
+		newAmounts := make(map[uint]float64)
+		newWeights := make(map[uint]float64)
+		for _, item := range items {
+			totalAmount += item.SubTotal
+			priceFlagged = priceFlagged || priceDeviates(item.UnitPrice, item.ListPrice, s.priceDeviationPercent)
+			deltas[item.CategoryID] += item.NetWeight
+			newWeights[item.CategoryID] += item.NetWeight
+			newAmounts[item.CategoryID] += item.SubTotal
+		}
+
+		// 已入账的库存不重估，已记账订单保留下来的重量不能改变每公斤成本 (单价或扣杂)
+		if isStockPosted(order.Status) {
+			for categoryID, newWeight := range newWeights {
+				prevWeight := prevWeights[categoryID]
+				if prevWeight < weightTolerance || newWeight < weightTolerance {
+					continue
+				}
+				prevCost, newCost := prevAmounts[categoryID]/prevWeight, newAmounts[categoryID]/newWeight
+				if math.Abs(newCost-prevCost)*math.Min(prevWeight, newWeight) >= amountTolerance {
+					return fmt.Errorf("unit cost of category %d cannot change from %.4f to %.4f on a posted order, cancel it and enter it again", categoryID, prevCost, newCost)
+				}
+			}
+		}
+
+		// 按分类调整库存，增加按修改后单价入账，减少按修改前单价冲减；减少量超过现有库存(已出库)时整体回滚
+		for _, categoryID := range sortedCategoryIDs(deltas) {
+			delta := deltas[categoryID]
+			if !isStockPosted(order.Status) || math.Abs(delta) < weightTolerance {
+				continue
+			}
+
+			source := models.MovementSource{SourceType: models.MovementSourceInbound, SourceID: id, UserID: userID}
+			if delta > 0 {
+				source.UnitCost = newAmounts[categoryID] / newWeights[categoryID]
+			} else {
+				source.UnitCost = prevAmounts[categoryID] / prevWeights[categoryID]
+			}
+			if err := tx.InventoryRepo.UpdateWeight(order.WarehouseID, categoryID, math.Abs(delta), delta > 0, source); err != nil {
+				return err
+			}
+		}
+
+		updates, err := s.headerUpdates(order, req)
+		if err != nil {
+			return err
+		}
+		// 已付款的订单金额不能低于已付金额
+		if order.PaidAmount >= amountTolerance && roundAmount(totalAmount) < order.PaidAmount-amountTolerance {
+			return fmt.Errorf("total amount %.2f is less than paid amount %.2f", totalAmount, order.PaidAmount)
+		}
+		updates["total_amount"] = totalAmount
+		updates["payment_status"] = orderPaymentStatus(totalAmount, order.PaidAmount)
+		updates["price_flagged"] = priceFlagged
+		if err := tx.InboundRepo.UpdateFields(id, updates); err != nil {
+			return err
+		}
+
+		if req.Status != "" {
+			return s.applyStatus(tx, order, req.Status, userID)
+		}
+		return nil
+	})
+}
+
+// UpdateOrderBasic 仅更新订单基本信息（不包括订单项）
+func (s *InboundService) UpdateOrderBasic(id uint, req *models.UpdateInboundOrderRequest, userID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		order, err := lockInboundOrder(tx, id)
+		if err != nil {
+			return err
+		}
+
+		updates, err := s.headerUpdates(order, req)
+		if err != nil {
+			return err
+		}
+		if len(updates) == 0 && req.Status == "" {
+			return errors.New("no fields to update")
+		}
+
+		if len(updates) > 0 {
+			if err := tx.InboundRepo.UpdateFields(id, updates); err != nil {
+				return err
+			}
+		}
+		if req.Status != "" {
+			return s.applyStatus(tx, order, req.Status, userID)
+		}
+		return nil
+	})
+}
+
+// lockInboundOrder 在事务中读取入库订单并加行锁，并发的状态变更、修改和删除按顺序执行
+func lockInboundOrder(tx *repository.Repositories, id uint) (*models.InboundOrder, error) {
+	order, err := tx.InboundRepo.GetByIDForUpdate(id)
+	if err != nil || order.IsDeleted == 1 {
+		return nil, errors.New("order not found")
+	}
+	return order, nil
+}
+
+// headerUpdates 根据更新请求构建订单头字段，指定卖家时同步供应商名称快照
+// 已有付款的订单不能更换卖家
+func (s *InboundService) headerUpdates(order *models.InboundOrder, req *models.UpdateInboundOrderRequest) (map[string]interface{}, error) {
+	updates := make(map[string]interface{})
+
+	if req.SellerID != nil {
+		seller, err := s.repos.SellerRepo.GetByID(*req.SellerID)
+		if err != nil {
+			return nil, errors.New("seller not found")
+		}
+		if order.PaidAmount >= amountTolerance && (order.SellerID == nil || *order.SellerID != seller.ID) {
+			return nil, errors.New("order has supplier payments, seller cannot be changed")
+		}
+		updates["seller_id"] = seller.ID
+		updates["supplier_name"] = seller.Name
+	} else if req.SupplierName != "" {
+		updates["supplier_name"] = req.SupplierName
+	}
+	if req.Notes != "" {
+		updates["notes"] = req.Notes
+	}
+	return updates, nil
+}
+
+// applyStatus 校验并更新订单状态，确认时记入库存，取消已记账订单时冲回库存
+func (s *InboundService) applyStatus(tx *repository.Repositories, order *models.InboundOrder, status string, userID uint) error {
+	if order.Status == status {
+		return nil
+	}
+	if err := checkOrderTransition(order.Status, status); err != nil {
+		return err
+	}
+
+	switch {
+	case status == models.OrderStatusConfirmed:
+		if err := s.postStock(tx, order, userID); err != nil {
+			return err
+		}
+	case status == models.OrderStatusCancelled && order.PaidAmount >= amountTolerance:
+		return errors.New("order has supplier payments, void them first")
+	case status == models.OrderStatusCancelled && isStockPosted(order.Status):
+		if err := s.reverseStock(tx, order, userID); err != nil {
+			return err
+		}
+	}
+	if err := tx.InboundRepo.UpdateStatus(order.ID, status); err != nil {
+		return err
+	}
+	order.Status = status
+	return nil
+}
+
+// postStock 将入库订单各分类净重记入库存
+func (s *InboundService) postStock(tx *repository.Repositories, order *models.InboundOrder, userID uint) error {
+	items, err := tx.InboundRepo.GetRawItemsByOrderID(order.ID)
+	if err != nil {
+		return err
+	}
+
+	weights, amounts := inboundCategoryTotals(items)
+	for _, categoryID := range sortedCategoryIDs(weights) {
+		source := models.MovementSource{
+			SourceType: models.MovementSourceInbound,
+			SourceID:   order.ID,
+			UserID:     userID,
+			UnitCost:   amounts[categoryID] / weights[categoryID],
+		}
+		if err := tx.InventoryRepo.UpdateWeight(order.WarehouseID, categoryID, weights[categoryID], true, source); err != nil {
+			return err
+		}
+	}
+
+	// 每个订单项生成一个批次
+	for i := range items {
+		if err := createInboundLot(tx, order, &items[i]); err != nil {
+			return err
+		}
+	}
+	return receiveTraceCodes(tx, order, items, userID)
+}
+
+// reverseStock 冲回入库订单增加的库存，库存已被出库消耗时拒绝
+func (s *InboundService) reverseStock(tx *repository.Repositories, order *models.InboundOrder, userID uint) error {
+	items, err := tx.InboundRepo.GetRawItemsByOrderID(order.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := deleteInboundLots(tx, order.ID); err != nil {
+		return fmt.Errorf("cannot reverse inbound order %s: %w", order.OrderNo, err)
+	}
+	if err := reverseTraceCodeReceipt(tx, order, 0, userID); err != nil {
+		return fmt.Errorf("cannot reverse inbound order %s: %w", order.OrderNo, err)
+	}
+
+	weights, amounts := inboundCategoryTotals(items)
+	for _, categoryID := range sortedCategoryIDs(weights) {
+		source := models.MovementSource{
+			SourceType: models.MovementSourceInbound,
+			SourceID:   order.ID,
+			UserID:     userID,
+			UnitCost:   amounts[categoryID] / weights[categoryID],
+		}
+		if err := tx.InventoryRepo.UpdateWeight(order.WarehouseID, categoryID, weights[categoryID], false, source); err != nil {
+			return fmt.Errorf("cannot reverse inbound order %s, stock has already been sold: %w", order.OrderNo, err)
+		}
+	}
+	return nil
+}
+
+// updateItemTraceCodes 重新登记订单项的溯源编码，codes 为 nil 时沿用已采集的编码
+func (s *InboundService) updateItemTraceCodes(tx *repository.Repositories, order *models.InboundOrder, item *models.InboundOrderItem, action string, codes []string, userID uint) error {
+	posted := isStockPosted(order.Status)
+	if posted && action == "update" {
+		if err := reverseTraceCodeReceipt(tx, order, item.ID, userID); err != nil {
+			return err
+		}
+	}
+	if codes != nil {
+		if err := tx.TraceCodeRepo.DeleteItemCodes(models.MovementSourceInbound, order.ID, item.ID); err != nil {
+			return err
+		}
+		if err := saveItemTraceCodes(tx, models.MovementSourceInbound, order.ID, item.ID, codes); err != nil {
+			return err
+		}
+	}
+	if posted {
+		return receiveTraceCodes(tx, order, []models.InboundOrderItem{*item}, userID)
+	}
+	return nil
+}
+
+// inboundCategoryTotals 按分类汇总入库订单项的净重和金额
+func inboundCategoryTotals(items []models.InboundOrderItem) (weights, amounts map[uint]float64) {
+	weights = make(map[uint]float64)
+	amounts = make(map[uint]float64)
+	for _, item := range items {
+		weights[item.CategoryID] += item.NetWeight
+		amounts[item.CategoryID] += item.SubTotal
+	}
+	return weights, amounts
+}
+
+// validateInboundItem 校验入库订单项的重量和单价
+func validateInboundItem(categoryID uint, grossWeight, tareWeight, unitPrice float64) error {
+	if categoryID == 0 {
+		return errors.New("category_id is required")
+	}
+	if grossWeight <= 0 || unitPrice <= 0 {
+		return errors.New("gross_weight and unit_price must be greater than 0")
+	}
+	if tareWeight < 0 || tareWeight >= grossWeight {
+		return errors.New("tare_weight must be between 0 and gross_weight")
+	}
+	return nil
+}
+
+// validateDeductionInput 校验请求中的扣杂，每项扣杂只能按百分比或按重量填写其一
+func validateDeductionInput(d *models.InboundDeductions) error {
+	deductions := []struct {
+		name    string
+		percent float64
+		weight  float64
+	}{
+		{"moisture", d.MoisturePercent, d.MoistureWeight},
+		{"packaging", d.PackagingPercent, d.PackagingWeight},
+		{"impurity", d.ImpurityPercent, d.ImpurityWeight},
+		{"mixed", d.MixedPercent, d.MixedWeight},
+	}
+	for _, deduction := range deductions {
+		if deduction.percent > 0 && deduction.weight > 0 {
+			return fmt.Errorf("%s deduction accepts either a percentage or a weight, not both", deduction.name)
+		}
+	}
+	return nil
+}
+
+// calcInboundItem 根据毛重、皮重、扣杂和单价计算净重、结算重量和小计
+// 按百分比填写的扣杂每次按当前净重重新折算扣除重量 (覆盖上次折算的重量)，扣杂合计不能达到净重
+func calcInboundItem(item *models.InboundOrderItem) error {
+	item.NetWeight = item.GrossWeight - item.TareWeight
+
+	d := &item.InboundDeductions
+	deductions := []struct {
+		name    string
+		percent float64
+		weight  *float64
+	}{
+		{"moisture", d.MoisturePercent, &d.MoistureWeight},
+		{"packaging", d.PackagingPercent, &d.PackagingWeight},
+		{"impurity", d.ImpurityPercent, &d.ImpurityWeight},
+		{"mixed", d.MixedPercent, &d.MixedWeight},
+	}
+
+	item.DeductionWeight = 0
+	for _, deduction := range deductions {
+		if deduction.percent < 0 || deduction.percent >= 100 || *deduction.weight < 0 {
+			return fmt.Errorf("%s deduction must be a percentage between 0 and 100 or a non-negative weight", deduction.name)
+		}
+		if deduction.percent > 0 {
+			*deduction.weight = roundWeight(item.NetWeight * deduction.percent / 100)
+		}
+		item.DeductionWeight += *deduction.weight
+	}
+	if item.DeductionWeight >= item.NetWeight {
+		return errors.New("total deductions must be less than net weight")
+	}
+
+	item.SettlementWeight = item.NetWeight - item.DeductionWeight
+	item.SubTotal = item.SettlementWeight * item.UnitPrice
+	return nil
+}
+
+// inboundUnitCost 入库订单项每公斤净重的成本，扣杂后小计按净重分摊
+func inboundUnitCost(item *models.InboundOrderItem) float64 {
+	if item.NetWeight <= 0 {
+		return item.UnitPrice
+	}
+	return item.SubTotal / item.NetWeight
+}
+
+// roundWeight 重量保留三位小数，与数据库精度一致
+func roundWeight(weight float64) float64 {
+	return math.Round(weight*1000) / 1000
 }
@@ -3,61 +3,181 @@ package services
 import (
 	"battery-erp-backend/internal/models"
 	"battery-erp-backend/internal/repository"
+	"math"
+	"sort"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// weightTolerance 重量比较容差 (数据库保留3位小数)
+const weightTolerance = 0.0005
+
 // InventoryService 库存服务 (不再使用接口)
 type InventoryService struct {
 	inventoryRepo *repository.InventoryRepository
 	categoryRepo  *repository.CategoryRepository
+	warehouseRepo *repository.WarehouseRepository
 }
 
 // NewInventoryService 创建库存服务实例
-func NewInventoryService(inventoryRepo *repository.InventoryRepository, categoryRepo *repository.CategoryRepository) *InventoryService {
+func NewInventoryService(inventoryRepo *repository.InventoryRepository, categoryRepo *repository.CategoryRepository, warehouseRepo *repository.WarehouseRepository) *InventoryService {
 	return &InventoryService{
 		inventoryRepo: inventoryRepo,
 		categoryRepo:  categoryRepo,
+		warehouseRepo: warehouseRepo,
 	}
 }
 
-func (s *InventoryService) GetByCategoryID(categoryID uint) (*models.Inventory, error) {
+// GetByCategoryID 获取分类在指定仓库的库存，warehouseID 为 0 时返回全部仓库合计 (warehouse_id 为 0)
+func (s *InventoryService) GetByCategoryID(categoryID, warehouseID uint) (*models.Inventory, error) {
 	// 先检查分类是否存在
 	_, err := s.categoryRepo.GetByID(categoryID)
 	if err != nil {
 		return nil, err
 	}
 
-	inventory, err := s.inventoryRepo.GetByCategoryID(categoryID)
+	if warehouseID == 0 {
+		inventories, err := s.inventoryRepo.GetAllByCategoryID(categoryID)
+		if err != nil {
+			return nil, err
+		}
+		return consolidateInventory(categoryID, inventories), nil
+	}
+
+	if _, err := s.warehouseRepo.GetByID(warehouseID); err != nil {
+		return nil, err
+	}
+
+	inventory, err := s.inventoryRepo.GetByCategoryID(warehouseID, categoryID)
 	if err != nil {
 		// 使用正确的gorm错误判断
 		if err == gorm.ErrRecordNotFound {
 			// 如果库存记录不存在，创建一个新的
-			newInventory := &models.Inventory{
-				CategoryID:      categoryID,
-				CurrentWeightKg: 0,
-				CreatedAt:       time.Now(),
-				UpdatedAt:       time.Now(),
-			}
-			if createErr := s.inventoryRepo.Create(newInventory); createErr != nil {
+			if createErr := s.InitializeInventory(warehouseID, categoryID); createErr != nil {
 				return nil, createErr
 			}
-			return s.inventoryRepo.GetByCategoryID(categoryID)
+			return s.inventoryRepo.GetByCategoryID(warehouseID, categoryID)
 		}
 		return nil, err
 	}
 	return inventory, nil
 }
 
-func (s *InventoryService) GetAll() ([]models.Inventory, error) {
-	return s.inventoryRepo.GetAll()
+// GetAll 获取所有库存，warehouseID 为 0 时返回全部仓库
+func (s *InventoryService) GetAll(warehouseID uint) ([]models.Inventory, error) {
+	return s.inventoryRepo.GetAll(warehouseID)
+}
+
+// GetConsolidated 按分类汇总全部仓库的库存，并列出各仓库明细
+func (s *InventoryService) GetConsolidated() ([]models.ConsolidatedInventory, error) {
+	inventories, err := s.inventoryRepo.GetAll(0)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.categoryRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	categoryNames := make(map[uint]string, len(categories))
+	for _, category := range categories {
+		categoryNames[category.ID] = category.Name
+	}
+
+	warehouses, err := s.warehouseRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	warehouseNames := make(map[uint]string, len(warehouses))
+	for _, warehouse := range warehouses {
+		warehouseNames[warehouse.ID] = warehouse.Name
+	}
+
+	var result []models.ConsolidatedInventory
+	index := make(map[uint]int)
+	for _, inv := range inventories {
+		i, ok := index[inv.CategoryID]
+		if !ok {
+			result = append(result, models.ConsolidatedInventory{
+				CategoryID:   inv.CategoryID,
+				CategoryName: categoryNames[inv.CategoryID],
+				Warehouses:   []models.WarehouseInventoryLine{},
+			})
+			i = len(result) - 1
+			index[inv.CategoryID] = i
+		}
+
+		line := &result[i]
+		line.TotalWeightKg += inv.CurrentWeightKg
+		line.TotalValue += inv.TotalValue
+		line.Warehouses = append(line.Warehouses, models.WarehouseInventoryLine{
+			WarehouseID:     inv.WarehouseID,
+			WarehouseName:   warehouseNames[inv.WarehouseID],
+			CurrentWeightKg: inv.CurrentWeightKg,
+			TotalValue:      inv.TotalValue,
+		})
+	}
+
+	for i := range result {
+		if result[i].TotalWeightKg >= weightTolerance {
+			result[i].AvgUnitCost = result[i].TotalValue / result[i].TotalWeightKg
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CategoryID < result[j].CategoryID })
+	return result, nil
+}
+
+// GetMovements 获取分类的库存流水
+func (s *InventoryService) GetMovements(categoryID uint, req *models.GetInventoryMovementsRequest) ([]models.InventoryMovement, int64, error) {
+	if _, err := s.categoryRepo.GetByID(categoryID); err != nil {
+		return nil, 0, err
+	}
+	return s.inventoryRepo.GetMovements(categoryID, req)
+}
+
+// CheckLedger 根据库存流水重算各仓库各分类结存，并与当前库存对账
+func (s *InventoryService) CheckLedger() ([]models.InventoryLedgerCheck, error) {
+	inventories, err := s.inventoryRepo.GetAll(0)
+	if err != nil {
+		return nil, err
+	}
+
+	sums, err := s.inventoryRepo.SumMovementsByCategory()
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.categoryRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[uint]string, len(categories))
+	for _, category := range categories {
+		names[category.ID] = category.Name
+	}
+
+	checks := make([]models.InventoryLedgerCheck, 0, len(inventories))
+	for _, inv := range inventories {
+		ledgerWeight := sums[repository.StockKey{WarehouseID: inv.WarehouseID, CategoryID: inv.CategoryID}]
+		difference := inv.CurrentWeightKg - ledgerWeight
+		checks = append(checks, models.InventoryLedgerCheck{
+			WarehouseID:   inv.WarehouseID,
+			CategoryID:    inv.CategoryID,
+			CategoryName:  names[inv.CategoryID],
+			CurrentWeight: inv.CurrentWeightKg,
+			LedgerWeight:  ledgerWeight,
+			Difference:    difference,
+			Consistent:    math.Abs(difference) < weightTolerance,
+		})
+	}
+	return checks, nil
 }
 
-// InitializeInventoryForCategory 为新分类初始化库存记录
-func (s *InventoryService) InitializeInventoryForCategory(categoryID uint) error {
+// InitializeInventory 为分类在仓库中初始化库存记录
+func (s *InventoryService) InitializeInventory(warehouseID, categoryID uint) error {
 	// 检查是否已存在库存记录
-	_, err := s.inventoryRepo.GetByCategoryID(categoryID)
+	_, err := s.inventoryRepo.GetByCategoryID(warehouseID, categoryID)
 	if err == nil {
 		return nil // 已存在，无需初始化
 	}
@@ -68,6 +188,7 @@ func (s *InventoryService) InitializeInventoryForCategory(categoryID uint) error
 
 	// 创建新的库存记录
 	inventory := &models.Inventory{
+		WarehouseID:     warehouseID,
 		CategoryID:      categoryID,
 		CurrentWeightKg: 0,
 		CreatedAt:       time.Now(),
@@ -75,3 +196,39 @@ func (s *InventoryService) InitializeInventoryForCategory(categoryID uint) error
 	}
 	return s.inventoryRepo.Create(inventory)
 }
+
+// consolidateInventory 合计分类在各仓库的库存，返回 warehouse_id 为 0 的汇总记录
+func consolidateInventory(categoryID uint, inventories []models.Inventory) *models.Inventory {
+	total := &models.Inventory{CategoryID: categoryID}
+	for _, inv := range inventories {
+		total.CurrentWeightKg += inv.CurrentWeightKg
+		total.TotalValue += inv.TotalValue
+		if inv.LastInboundAt != nil && (total.LastInboundAt == nil || inv.LastInboundAt.After(*total.LastInboundAt)) {
+			total.LastInboundAt = inv.LastInboundAt
+		}
+		if inv.LastOutboundAt != nil && (total.LastOutboundAt == nil || inv.LastOutboundAt.After(*total.LastOutboundAt)) {
+			total.LastOutboundAt = inv.LastOutboundAt
+		}
+		if total.CreatedAt.IsZero() || inv.CreatedAt.Before(total.CreatedAt) {
+			total.CreatedAt = inv.CreatedAt
+		}
+		if inv.UpdatedAt.After(total.UpdatedAt) {
+			total.UpdatedAt = inv.UpdatedAt
+		}
+	}
+	if total.CurrentWeightKg >= weightTolerance {
+		total.AvgUnitCost = total.TotalValue / total.CurrentWeightKg
+	}
+	return total
+}
+
+// sortedCategoryIDs 返回按分类ID升序排列的键
+// 同一事务内按固定顺序锁定库存行，避免并发订单交叉加锁导致死锁
+func sortedCategoryIDs(weights map[uint]float64) []uint {
+	ids := make([]uint, 0, len(weights))
+	for id := range weights {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
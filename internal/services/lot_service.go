@@ -0,0 +1,393 @@
+package services
+
+import (
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/repository"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LotService 批次服务
+// 已记账的入库订单项生成批次，出库、调拨发货和库存减少按先进先出 (或出库手工指定) 分配批次；
+// 启用批次前的历史库存没有批次，分配不足部分视为无批次库存
+type LotService struct {
+	repos *repository.Repositories
+	repo  *repository.LotRepository
+}
+
+// NewLotService 创建批次服务实例
+func NewLotService(repos *repository.Repositories) *LotService {
+	return &LotService{
+		repos: repos,
+		repo:  repos.LotRepo,
+	}
+}
+
+// GetByID 根据ID获取批次
+func (s *LotService) GetByID(id uint) (*models.Lot, error) {
+	lot, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	names, err := s.categoryNames()
+	if err != nil {
+		return nil, err
+	}
+	lot.CategoryName = names[lot.CategoryID]
+	return lot, nil
+}
+
+// GetAll 分页获取批次
+func (s *LotService) GetAll(req *models.GetLotsRequest) (*models.GetLotsResponse, error) {
+	lots, total, err := s.repo.GetAll(req)
+	if err != nil {
+		return nil, err
+	}
+	names, err := s.categoryNames()
+	if err != nil {
+		return nil, err
+	}
+	for i := range lots {
+		lots[i].CategoryName = names[lots[i].CategoryID]
+	}
+	return &models.GetLotsResponse{
+		Lots:  lots,
+		Total: total,
+	}, nil
+}
+
+// GetGenealogy 获取批次谱系: 父批次链、本批次及调拨子批次的消耗明细
+func (s *LotService) GetGenealogy(id uint) (*models.LotGenealogy, error) {
+	lot, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	names, err := s.categoryNames()
+	if err != nil {
+		return nil, err
+	}
+
+	ancestors := []models.Lot{}
+	for parentID := lot.ParentLotID; parentID != nil; {
+		parent, err := s.repo.GetByID(*parentID)
+		if err != nil {
+			return nil, err
+		}
+		parent.CategoryName = names[parent.CategoryID]
+		ancestors = append(ancestors, *parent)
+		parentID = parent.ParentLotID
+	}
+
+	genealogy, err := s.descendants(lot, names)
+	if err != nil {
+		return nil, err
+	}
+	genealogy.Ancestors = ancestors
+	return genealogy, nil
+}
+
+// GetShipmentTrace 获取出库订单消耗的批次及其供应商来源
+func (s *LotService) GetShipmentTrace(outboundOrderID uint) ([]models.ShipmentLotTrace, error) {
+	if _, err := s.repos.OutboundRepo.GetByID(outboundOrderID); err != nil {
+		return nil, errors.New("order not found")
+	}
+	return s.repo.GetShipmentTrace(outboundOrderID)
+}
+
+// descendants 递归获取批次的消耗明细和子批次
+func (s *LotService) descendants(lot *models.Lot, names map[uint]string) (*models.LotGenealogy, error) {
+	lot.CategoryName = names[lot.CategoryID]
+	consumptions, err := s.repo.GetConsumptions(lot.ID)
+	if err != nil {
+		return nil, err
+	}
+	children, err := s.repo.GetChildren(lot.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	genealogy := &models.LotGenealogy{
+		Lot:          *lot,
+		Consumptions: consumptions,
+		Children:     []models.LotGenealogy{},
+	}
+	for i := range children {
+		child, err := s.descendants(&children[i], names)
+		if err != nil {
+			return nil, err
+		}
+		genealogy.Children = append(genealogy.Children, *child)
+	}
+	return genealogy, nil
+}
+
+// categoryNames 分类ID -> 分类名称
+func (s *LotService) categoryNames() (map[uint]string, error) {
+	categories, err := s.repos.CategoryRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[uint]string, len(categories))
+	for _, category := range categories {
+		names[category.ID] = category.Name
+	}
+	return names, nil
+}
+
+// createInboundLot 为已记账入库订单项生成批次
+func createInboundLot(tx *repository.Repositories, order *models.InboundOrder, item *models.InboundOrderItem) error {
+	lotNo, err := tx.LotRepo.GenerateLotNo()
+	if err != nil {
+		return err
+	}
+	lot := &models.Lot{
+		LotNo:           lotNo,
+		WarehouseID:     order.WarehouseID,
+		CategoryID:      item.CategoryID,
+		InboundOrderID:  order.ID,
+		InboundItemID:   item.ID,
+		SellerID:        order.SellerID,
+		SupplierName:    order.SupplierName,
+		Grade:           item.Grade,
+		ReceivedAt:      order.CreatedAt,
+		UnitCost:        inboundUnitCost(item),
+		OriginalWeight:  item.NetWeight,
+		RemainingWeight: item.NetWeight,
+	}
+	return tx.LotRepo.Create(lot)
+}
+
+// syncInboundLot 已记账入库订单项修改后同步批次的分类、等级、单价和重量，已被消耗的部分不能减少
+func syncInboundLot(tx *repository.Repositories, order *models.InboundOrder, item *models.InboundOrderItem) error {
+	lot, err := tx.LotRepo.GetByInboundItemForUpdate(item.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// 启用批次前记账的订单项没有批次
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	consumed := lot.OriginalWeight - lot.RemainingWeight
+	if consumed >= weightTolerance && lot.CategoryID != item.CategoryID {
+		return fmt.Errorf("lot %s has already been consumed, category cannot be changed", lot.LotNo)
+	}
+	if item.NetWeight < consumed-weightTolerance {
+		return fmt.Errorf("lot %s has already consumed %.3f kg, net weight cannot be lower", lot.LotNo, consumed)
+	}
+
+	return tx.LotRepo.UpdateFields(lot.ID, map[string]interface{}{
+		"category_id":      item.CategoryID,
+		"grade":            item.Grade,
+		"unit_cost":        inboundUnitCost(item),
+		"supplier_name":    order.SupplierName,
+		"original_weight":  item.NetWeight,
+		"remaining_weight": math.Max(item.NetWeight-consumed, 0),
+		"updated_at":       time.Now(),
+	})
+}
+
+// deleteInboundLot 删除入库订单项生成的批次，批次已被消耗时拒绝
+func deleteInboundLot(tx *repository.Repositories, itemID uint) error {
+	lot, err := tx.LotRepo.GetByInboundItemForUpdate(itemID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// 启用批次前记账的订单项没有批次
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return deleteUnconsumedLot(tx, lot)
+}
+
+// deleteInboundLots 冲回入库订单时删除其生成的批次，批次已被消耗时拒绝
+func deleteInboundLots(tx *repository.Repositories, orderID uint) error {
+	lots, err := tx.LotRepo.GetByInboundOrderForUpdate(orderID)
+	if err != nil {
+		return err
+	}
+	for i := range lots {
+		if err := deleteUnconsumedLot(tx, &lots[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deleteUnconsumedLot(tx *repository.Repositories, lot *models.Lot) error {
+	if lot.OriginalWeight-lot.RemainingWeight >= weightTolerance {
+		return fmt.Errorf("lot %s has already been consumed", lot.LotNo)
+	}
+	return tx.LotRepo.Delete(lot.ID)
+}
+
+// saveLotSelections 保存出库订单项手工指定的批次 (未记账)，订单扣减库存时再占用批次
+func saveLotSelections(tx *repository.Repositories, order *models.OutboundOrder, item *models.OutboundOrderItem, selections []models.LotSelection, userID uint) error {
+	var total float64
+	for _, selection := range selections {
+		lot, err := tx.LotRepo.GetByID(selection.LotID)
+		if err != nil {
+			return fmt.Errorf("lot %d not found", selection.LotID)
+		}
+		if lot.WarehouseID != order.WarehouseID || lot.CategoryID != item.CategoryID {
+			return fmt.Errorf("lot %s does not belong to the order's warehouse and category", lot.LotNo)
+		}
+		total += selection.Weight
+
+		allocation := &models.LotAllocation{
+			LotID:        lot.ID,
+			SourceType:   models.MovementSourceOutbound,
+			SourceID:     order.ID,
+			SourceItemID: item.ID,
+			Weight:       selection.Weight,
+			CreatedBy:    userID,
+		}
+		if err := tx.LotRepo.CreateAllocation(allocation); err != nil {
+			return err
+		}
+	}
+	if math.Abs(total-item.Weight) >= weightTolerance {
+		return fmt.Errorf("selected lots total %.3f kg, item weight is %.3f kg", total, item.Weight)
+	}
+	return nil
+}
+
+// postLotSelections 占用出库订单项手工指定的批次，批次剩余重量不足时返回错误
+func postLotSelections(tx *repository.Repositories, allocations []models.LotAllocation) error {
+	for _, allocation := range allocations {
+		lot, err := tx.LotRepo.GetByIDForUpdate(allocation.LotID)
+		if err != nil {
+			return fmt.Errorf("lot %d not found", allocation.LotID)
+		}
+		if lot.RemainingWeight < allocation.Weight-weightTolerance {
+			return fmt.Errorf("insufficient lot %s: remaining %.3f kg, requested %.3f kg", lot.LotNo, lot.RemainingWeight, allocation.Weight)
+		}
+		if err := consumeLot(tx, lot, allocation.Weight); err != nil {
+			return err
+		}
+		if err := tx.LotRepo.UpdateAllocationFields(allocation.ID, map[string]interface{}{"posted": true}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allocateLots 按先进先出从仓库中分类的批次分配重量，批次不足部分为无批次库存
+func allocateLots(tx *repository.Repositories, warehouseID, categoryID uint, weight float64, source models.MovementSource, itemID uint) error {
+	lots, err := tx.LotRepo.GetAvailableForUpdate(warehouseID, categoryID)
+	if err != nil {
+		return err
+	}
+
+	remaining := weight
+	for i := range lots {
+		if remaining < weightTolerance {
+			break
+		}
+		take := math.Min(remaining, lots[i].RemainingWeight)
+		if err := consumeLot(tx, &lots[i], take); err != nil {
+			return err
+		}
+
+		allocation := &models.LotAllocation{
+			LotID:        lots[i].ID,
+			SourceType:   source.SourceType,
+			SourceID:     source.SourceID,
+			SourceItemID: itemID,
+			Weight:       take,
+			Posted:       true,
+			CreatedBy:    source.UserID,
+		}
+		if err := tx.LotRepo.CreateAllocation(allocation); err != nil {
+			return err
+		}
+		remaining -= take
+	}
+	return nil
+}
+
+// releaseLots 将来源单据已占用的批次重量退回并删除其全部批次分配
+func releaseLots(tx *repository.Repositories, sourceType string, sourceID uint) error {
+	allocations, err := tx.LotRepo.GetAllocations(sourceType, sourceID)
+	if err != nil {
+		return err
+	}
+	for _, allocation := range allocations {
+		if !allocation.Posted {
+			continue
+		}
+		lot, err := tx.LotRepo.GetByIDForUpdate(allocation.LotID)
+		if err != nil {
+			return err
+		}
+		if err := consumeLot(tx, lot, -allocation.Weight); err != nil {
+			return err
+		}
+	}
+	return tx.LotRepo.DeleteAllocations(sourceType, sourceID)
+}
+
+// consumeLot 扣减批次剩余重量，weight 为负时退回
+func consumeLot(tx *repository.Repositories, lot *models.Lot, weight float64) error {
+	remaining := lot.RemainingWeight - weight
+	if remaining < weightTolerance {
+		remaining = 0
+	}
+	lot.RemainingWeight = remaining
+	return tx.LotRepo.UpdateFields(lot.ID, map[string]interface{}{
+		"remaining_weight": remaining,
+		"updated_at":       time.Now(),
+	})
+}
+
+// createTransferLots 调拨收货时按实收比例为发货占用的批次在调入仓生成子批次
+func createTransferLots(tx *repository.Repositories, transfer *models.TransferOrder, item *models.TransferOrderItem, receivedWeight float64) error {
+	allocations, err := tx.LotRepo.GetAllocations(models.MovementSourceTransferOut, transfer.ID)
+	if err != nil {
+		return err
+	}
+
+	ratio := receivedWeight / item.Weight
+	for _, allocation := range allocations {
+		if allocation.SourceItemID != item.ID || !allocation.Posted {
+			continue
+		}
+		weight := allocation.Weight * ratio
+		if weight < weightTolerance {
+			continue
+		}
+
+		parent, err := tx.LotRepo.GetByID(allocation.LotID)
+		if err != nil {
+			return err
+		}
+		lotNo, err := tx.LotRepo.GenerateLotNo()
+		if err != nil {
+			return err
+		}
+		parentID, transferID := parent.ID, transfer.ID
+		child := &models.Lot{
+			LotNo:           lotNo,
+			WarehouseID:     transfer.ToWarehouseID,
+			CategoryID:      parent.CategoryID,
+			InboundOrderID:  parent.InboundOrderID,
+			ParentLotID:     &parentID,
+			TransferID:      &transferID,
+			SellerID:        parent.SellerID,
+			SupplierName:    parent.SupplierName,
+			Grade:           parent.Grade,
+			ReceivedAt:      parent.ReceivedAt,
+			UnitCost:        parent.UnitCost,
+			OriginalWeight:  weight,
+			RemainingWeight: weight,
+		}
+		if err := tx.LotRepo.Create(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
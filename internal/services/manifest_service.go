@@ -0,0 +1,405 @@
+package services
+
+import (
+	"battery-erp-backend/config"
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/pdf"
+	"battery-erp-backend/internal/repository"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ManifestService 危险废物转移联单服务
+// 联单按已记账的出库订单开具，编号按年连续，签收后办结
+type ManifestService struct {
+	repos   *repository.Repositories
+	repo    *repository.ManifestRepository
+	company config.CompanyConfig
+}
+
+// NewManifestService 创建联单服务实例，company 为联单上的移出单位信息
+func NewManifestService(repos *repository.Repositories, company config.CompanyConfig) *ManifestService {
+	return &ManifestService{
+		repos:   repos,
+		repo:    repos.ManifestRepo,
+		company: company,
+	}
+}
+
+// Generate 为出库订单开具联单
+// 订单须已确认或已完成，出库分类须已设置危险废物代码；接收单位取自订单客户，未关联客户时取自请求
+func (s *ManifestService) Generate(orderID uint, req *models.CreateWasteManifestRequest, userID uint) (*models.WasteManifest, error) {
+	if strings.TrimSpace(s.company.Name) == "" {
+		return nil, errors.New("company name is not configured, cannot issue manifest")
+	}
+
+	order, err := s.repos.OutboundRepo.GetByID(orderID)
+	if err != nil || order.IsDeleted == 1 {
+		return nil, errors.New("order not found")
+	}
+	if !isStockPosted(order.Status) {
+		return nil, fmt.Errorf("order is %s, only confirmed or completed orders can be issued a manifest", order.Status)
+	}
+
+	receiver := models.ManifestParty{
+		Name:      strings.TrimSpace(req.ReceiverName),
+		Address:   order.DeliveryAddress,
+		LicenseNo: strings.TrimSpace(req.ReceiverLicenseNo),
+	}
+	if order.CustomerID != nil {
+		customer, err := s.repos.CustomerRepo.GetByID(*order.CustomerID)
+		if err != nil {
+			return nil, errors.New("customer not found")
+		}
+		receiver.Name = customer.Name
+		receiver.ContactPerson = customer.ContactPerson
+		receiver.Phone = customer.Phone
+	}
+	if receiver.Name == "" {
+		receiver.Name = strings.TrimSpace(order.CustomerName)
+	}
+	if receiver.Name == "" {
+		return nil, errors.New("receiver name is required for orders without a customer")
+	}
+
+	items, err := s.manifestItems(order)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest *models.WasteManifest
+	err = s.repos.Transaction(func(tx *repository.Repositories) error {
+		if _, err := tx.ManifestRepo.GetByOutboundOrderID(order.ID); err == nil {
+			return errors.New("manifest already issued for this order")
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		now := time.Now()
+		year := now.Format("2006")
+		seq, err := tx.SequenceRepo.Next("waste_manifest_" + year)
+		if err != nil {
+			return err
+		}
+
+		var total float64
+		for _, item := range items {
+			total += item.Weight
+		}
+
+		manifest = &models.WasteManifest{
+			ManifestNo:        fmt.Sprintf("HW%s%06d", year, seq),
+			OutboundOrderID:   order.ID,
+			OutboundOrderNo:   order.OrderNo,
+			Status:            models.ManifestStatusIssued,
+			ShipperName:       s.company.Name,
+			ShipperAddress:    s.company.Address,
+			ShipperLicenseNo:  s.company.LicenseNo,
+			ShipperContact:    s.company.ContactPerson,
+			ShipperPhone:      s.company.Phone,
+			CarNumber:         order.CarNumber,
+			DriverName:        order.DriverName,
+			DriverPhone:       order.DriverPhone,
+			ReceiverName:      receiver.Name,
+			ReceiverAddress:   receiver.Address,
+			ReceiverLicenseNo: receiver.LicenseNo,
+			ReceiverContact:   receiver.ContactPerson,
+			ReceiverPhone:     receiver.Phone,
+			TotalWeight:       total,
+			Notes:             req.Notes,
+			IssuedBy:          userID,
+			IssuedAt:          now,
+		}
+		if err := tx.ManifestRepo.Create(manifest); err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].ManifestID = manifest.ID
+		}
+		if err := tx.ManifestRepo.CreateItems(items); err != nil {
+			return err
+		}
+		manifest.Items = items
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// GetByID 根据ID获取联单 (包含废物明细)
+func (s *ManifestService) GetByID(id uint) (*models.WasteManifest, error) {
+	manifest, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.repo.GetItems(id)
+	if err != nil {
+		return nil, err
+	}
+	manifest.Items = items
+	return manifest, nil
+}
+
+// GetAll 分页获取联单
+func (s *ManifestService) GetAll(req *models.GetWasteManifestsRequest) (*models.GetWasteManifestsResponse, error) {
+	manifests, total, err := s.repo.GetAll(req)
+	if err != nil {
+		return nil, err
+	}
+	return &models.GetWasteManifestsResponse{
+		Manifests: manifests,
+		Total:     total,
+	}, nil
+}
+
+// Receive 登记接收单位签收，未填写签收重量时视为按转移重量全部签收
+func (s *ManifestService) Receive(id uint, req *models.ReceiveWasteManifestRequest, userID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		manifest, err := tx.ManifestRepo.GetByIDForUpdate(id)
+		if err != nil {
+			return errors.New("manifest not found")
+		}
+		if manifest.Status != models.ManifestStatusIssued {
+			return fmt.Errorf("manifest is %s, cannot receive", manifest.Status)
+		}
+
+		receivedWeight := manifest.TotalWeight
+		if req.ReceivedWeight != nil {
+			receivedWeight = *req.ReceivedWeight
+		}
+		updates := map[string]interface{}{
+			"status":          models.ManifestStatusReceived,
+			"received_weight": receivedWeight,
+			"received_by":     userID,
+			"received_at":     time.Now(),
+		}
+		if req.Notes != "" {
+			updates["notes"] = req.Notes
+		}
+		return tx.ManifestRepo.UpdateFields(id, updates)
+	})
+}
+
+// Close 办结已签收的联单
+func (s *ManifestService) Close(id uint, userID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		manifest, err := tx.ManifestRepo.GetByIDForUpdate(id)
+		if err != nil {
+			return errors.New("manifest not found")
+		}
+		if manifest.Status != models.ManifestStatusReceived {
+			return fmt.Errorf("manifest is %s, only received manifests can be closed", manifest.Status)
+		}
+		return tx.ManifestRepo.UpdateFields(id, map[string]interface{}{
+			"status":    models.ManifestStatusClosed,
+			"closed_by": userID,
+			"closed_at": time.Now(),
+		})
+	})
+}
+
+// ExportJSON 导出联单上报用的结构化数据
+func (s *ManifestService) ExportJSON(id uint) (*models.WasteManifestExport, error) {
+	manifest, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	wastes := make([]models.ManifestWaste, 0, len(manifest.Items))
+	for _, item := range manifest.Items {
+		wastes = append(wastes, models.ManifestWaste{
+			WasteCode: item.WasteCode,
+			WasteName: item.CategoryName,
+			WeightKg:  item.Weight,
+		})
+	}
+
+	return &models.WasteManifestExport{
+		ManifestNo:      manifest.ManifestNo,
+		Status:          manifest.Status,
+		IssuedAt:        manifest.IssuedAt,
+		ReceivedAt:      manifest.ReceivedAt,
+		ClosedAt:        manifest.ClosedAt,
+		OutboundOrderNo: manifest.OutboundOrderNo,
+		Shipper: models.ManifestParty{
+			Name:          manifest.ShipperName,
+			Address:       manifest.ShipperAddress,
+			LicenseNo:     manifest.ShipperLicenseNo,
+			ContactPerson: manifest.ShipperContact,
+			Phone:         manifest.ShipperPhone,
+		},
+		Carrier: models.ManifestCarrier{
+			VehicleNo:   manifest.CarNumber,
+			DriverName:  manifest.DriverName,
+			DriverPhone: manifest.DriverPhone,
+		},
+		Receiver: models.ManifestParty{
+			Name:          manifest.ReceiverName,
+			Address:       manifest.ReceiverAddress,
+			LicenseNo:     manifest.ReceiverLicenseNo,
+			ContactPerson: manifest.ReceiverContact,
+			Phone:         manifest.ReceiverPhone,
+		},
+		Wastes:           wastes,
+		TotalWeightKg:    manifest.TotalWeight,
+		ReceivedWeightKg: manifest.ReceivedWeight,
+	}, nil
+}
+
+// ExportPDF 导出联单打印用的 PDF，返回联单编号和文件内容
+func (s *ManifestService) ExportPDF(id uint) (string, []byte, error) {
+	manifest, err := s.GetByID(id)
+	if err != nil {
+		return "", nil, err
+	}
+
+	const (
+		left   = 50.0
+		right  = pdf.PageWidth - 50
+		size   = 10.5
+		rowGap = 20.0
+	)
+	doc := pdf.New()
+	doc.AddPage()
+
+	doc.TextCenter(pdf.PageWidth/2, 50, 18, "危险废物转移联单")
+	y := 85.0
+	doc.Text(left, y, size, "联单编号: "+manifest.ManifestNo)
+	doc.TextRight(right, y, size, "开具日期: "+manifest.IssuedAt.Format("2006-01-02"))
+	y += rowGap
+	doc.Text(left, y, size, "出库单号: "+manifest.OutboundOrderNo)
+	doc.TextRight(right, y, size, "状态: "+manifestStatusLabel(manifest.Status))
+	y += rowGap + 5
+
+	parties := []struct {
+		title string
+		lines []string
+	}{
+		{"移出单位", []string{
+			"名称: " + manifest.ShipperName,
+			"地址: " + manifest.ShipperAddress,
+			"许可证编号: " + manifest.ShipperLicenseNo,
+			"联系人: " + manifest.ShipperContact + "    电话: " + manifest.ShipperPhone,
+		}},
+		{"运输信息", []string{
+			"车牌号: " + manifest.CarNumber,
+			"驾驶员: " + manifest.DriverName + "    电话: " + manifest.DriverPhone,
+		}},
+		{"接收单位", []string{
+			"名称: " + manifest.ReceiverName,
+			"地址: " + manifest.ReceiverAddress,
+			"许可证编号: " + manifest.ReceiverLicenseNo,
+			"联系人: " + manifest.ReceiverContact + "    电话: " + manifest.ReceiverPhone,
+		}},
+	}
+	for _, party := range parties {
+		height := float64(len(party.lines))*rowGap + 10
+		doc.Rect(left, y, right-left, height)
+		doc.Line(left+80, y, left+80, y+height)
+		doc.Text(left+12, y+height/2-size/2, size+1, party.title)
+		for i, line := range party.lines {
+			doc.Text(left+92, y+10+float64(i)*rowGap, size, line)
+		}
+		y += height + 10
+	}
+
+	// 废物明细表
+	columns := []float64{left, left + 40, left + 180, left + 380, right}
+	headers := []string{"序号", "废物代码", "废物名称", "重量 (kg)"}
+	doc.Rect(left, y, right-left, rowGap)
+	for i, header := range headers {
+		doc.Text(columns[i]+6, y+5, size, header)
+	}
+	y += rowGap
+	for i, item := range manifest.Items {
+		doc.Rect(left, y, right-left, rowGap)
+		doc.Text(columns[0]+6, y+5, size, fmt.Sprintf("%d", i+1))
+		doc.Text(columns[1]+6, y+5, size, item.WasteCode)
+		doc.Text(columns[2]+6, y+5, size, item.CategoryName)
+		doc.TextRight(columns[4]-6, y+5, size, fmt.Sprintf("%.3f", item.Weight))
+		y += rowGap
+	}
+	doc.Rect(left, y, right-left, rowGap)
+	doc.Text(columns[2]+6, y+5, size, "合计")
+	doc.TextRight(columns[4]-6, y+5, size, fmt.Sprintf("%.3f", manifest.TotalWeight))
+	tableBottom := y + rowGap
+	for _, x := range columns[1:4] {
+		doc.Line(x, tableBottom-float64(len(manifest.Items)+2)*rowGap, x, tableBottom)
+	}
+	y = tableBottom + 15
+
+	if manifest.ReceivedWeight != nil {
+		doc.Text(left, y, size, fmt.Sprintf("接收重量 (kg): %.3f", *manifest.ReceivedWeight))
+		y += rowGap
+	}
+	if manifest.Notes != "" {
+		doc.Text(left, y, size, "备注: "+manifest.Notes)
+		y += rowGap
+	}
+
+	// 签字栏
+	y += 25
+	signatures := []string{"移出单位 (盖章):", "运输单位 (签字):", "接收单位 (盖章):"}
+	width := (right - left) / float64(len(signatures))
+	for i, label := range signatures {
+		x := left + float64(i)*width
+		doc.Text(x, y, size, label)
+		doc.Line(x, y+45, x+width-20, y+45)
+		doc.Text(x, y+52, size-1.5, "日期:")
+	}
+
+	return manifest.ManifestNo, doc.Bytes(), nil
+}
+
+// manifestItems 按分类汇总出库订单重量生成联单废物明细，分类未设置危险废物代码时返回错误
+func (s *ManifestService) manifestItems(order *models.OutboundOrder) ([]models.WasteManifestItem, error) {
+	orderItems, err := s.repos.OutboundRepo.GetRawItemsByOrderID(order.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(orderItems) == 0 {
+		return nil, errors.New("order has no items")
+	}
+
+	weights := make(map[uint]float64)
+	for _, item := range orderItems {
+		weights[item.CategoryID] += item.Weight
+	}
+
+	items := make([]models.WasteManifestItem, 0, len(weights))
+	for _, categoryID := range sortedCategoryIDs(weights) {
+		category, err := s.repos.CategoryRepo.GetByID(categoryID)
+		if err != nil {
+			return nil, fmt.Errorf("category %d not found", categoryID)
+		}
+		if strings.TrimSpace(category.WasteCode) == "" {
+			return nil, fmt.Errorf("category %s has no waste code", category.Name)
+		}
+		items = append(items, models.WasteManifestItem{
+			CategoryID:   categoryID,
+			CategoryName: category.Name,
+			WasteCode:    category.WasteCode,
+			Weight:       weights[categoryID],
+		})
+	}
+	return items, nil
+}
+
+// manifestStatusLabel 联单状态的中文名称
+func manifestStatusLabel(status string) string {
+	switch status {
+	case models.ManifestStatusIssued:
+		return "已开具"
+	case models.ManifestStatusReceived:
+		return "已签收"
+	case models.ManifestStatusClosed:
+		return "已办结"
+	}
+	return status
+}
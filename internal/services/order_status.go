@@ -0,0 +1,36 @@
+package services
+
+import (
+	"battery-erp-backend/internal/models"
+	"fmt"
+)
+
+// orderTransitions 入库/出库订单允许的状态流转
+var orderTransitions = map[string][]string{
+	models.OrderStatusDraft:     {models.OrderStatusConfirmed, models.OrderStatusCancelled},
+	models.OrderStatusConfirmed: {models.OrderStatusCompleted, models.OrderStatusCancelled},
+	models.OrderStatusCompleted: {models.OrderStatusCancelled},
+}
+
+// checkOrderTransition 校验订单状态能否从 from 流转到 to
+func checkOrderTransition(from, to string) error {
+	for _, allowed := range orderTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("order status cannot change from %s to %s", from, to)
+}
+
+// isStockPosted 该状态的订单是否已记入库存
+func isStockPosted(status string) bool {
+	return status == models.OrderStatusConfirmed || status == models.OrderStatusCompleted
+}
+
+// initialOrderStatus 创建订单时的状态，未指定时为 completed 以兼容旧客户端
+func initialOrderStatus(status string) string {
+	if status == "" {
+		return models.OrderStatusCompleted
+	}
+	return status
+}
@@ -4,86 +4,178 @@ import (
 	"battery-erp-backend/internal/models"
 	"battery-erp-backend/internal/repository"
 	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
 )
 
 // OutboundService 出库服务 (不再使用接口)
 type OutboundService struct {
+	repos         *repository.Repositories
 	outboundRepo  *repository.OutboundRepository
 	inventoryRepo *repository.InventoryRepository
+	// priceDeviationPercent 单价偏离价目表超过该百分比时标记订单
+	priceDeviationPercent float64
 }
 
 // NewOutboundService 创建出库服务实例
-func NewOutboundService(outboundRepo *repository.OutboundRepository, inventoryRepo *repository.InventoryRepository) *OutboundService {
+func NewOutboundService(repos *repository.Repositories, priceDeviationPercent float64) *OutboundService {
 	return &OutboundService{
-		outboundRepo:  outboundRepo,
-		inventoryRepo: inventoryRepo,
+		repos:                 repos,
+		outboundRepo:          repos.OutboundRepo,
+		inventoryRepo:         repos.InventoryRepo,
+		priceDeviationPercent: priceDeviationPercent,
 	}
 }
 
 func (s *OutboundService) Create(req *models.CreateOutboundOrderRequest, createdBy uint) (*models.OutboundOrder, error) {
+	var order *models.OutboundOrder
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		var err error
+		order, err = s.create(tx, req, createdBy)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// create 在事务中创建出库订单，订单头、订单项和库存变动一起提交
+func (s *OutboundService) create(tx *repository.Repositories, req *models.CreateOutboundOrderRequest, createdBy uint) (*models.OutboundOrder, error) {
 	// Generate order number
-	orderNo, err := s.outboundRepo.GenerateOrderNo()
+	orderNo, err := tx.OutboundRepo.GenerateOrderNo()
 	if err != nil {
 		return nil, err
 	}
 
-	// Check inventory and calculate totals
-	var totalAmount float64
-	var orderItems []models.OutboundOrderItem
+	if _, err := tx.WarehouseRepo.GetByID(req.WarehouseID); err != nil {
+		return nil, errors.New("warehouse not found")
+	}
 
-	for _, reqItem := range req.Items {
-		// Check inventory availability
-		inventory, err := s.inventoryRepo.GetByCategoryID(reqItem.CategoryID)
+	// 指定客户时记录客户名称快照，未填写送货地址时取客户默认地址
+	// 客户行加锁，同一客户的并发下单按顺序校验信用额度
+	deliveryAddress := req.DeliveryAddress
+	var customer *models.Customer
+	var customerName string
+	if req.CustomerID != nil {
+		customer, err = tx.CustomerRepo.GetByIDForUpdate(*req.CustomerID)
 		if err != nil {
-			return nil, errors.New("inventory not found for category")
+			return nil, errors.New("customer not found")
 		}
+		customerName = customer.Name
 
-		if inventory.CurrentWeightKg < reqItem.Weight {
-			return nil, errors.New("insufficient inventory")
+		if deliveryAddress == "" {
+			addresses, err := tx.CustomerRepo.GetAddresses(customer.ID)
+			if err != nil {
+				return nil, err
+			}
+			if len(addresses) > 0 {
+				deliveryAddress = addresses[0].Address
+			}
 		}
+	}
+	if deliveryAddress == "" {
+		return nil, errors.New("delivery_address is required")
+	}
+
+	// 指定车辆/司机档案时带出车号和司机信息
+	carrier, err := resolveCarrier(tx, req.VehicleID, req.DriverID, req.CarNumber, req.DriverName, req.DriverPhone)
+	if err != nil {
+		return nil, err
+	}
+	if carrier.CarNumber == "" {
+		return nil, errors.New("car_number is required")
+	}
+	if carrier.DriverName == "" || carrier.DriverPhone == "" {
+		return nil, errors.New("driver_name and driver_phone are required")
+	}
 
-		subTotal := reqItem.Weight * reqItem.UnitPrice
+	// Calculate totals (库存充足校验在事务内加锁后由 UpdateWeight 完成，未填写单价时取当前销售价)
+	var totalAmount float64
+	var orderItems []models.OutboundOrderItem
+	var priceFlagged bool
+	now := time.Now()
+
+	for _, reqItem := range req.Items {
+		unitPrice, listPrice, err := resolveUnitPrice(tx, reqItem.CategoryID, models.PriceTypeSell, now, reqItem.UnitPrice)
+		if err != nil {
+			return nil, err
+		}
+		subTotal := reqItem.Weight * unitPrice
 		totalAmount += subTotal
+		priceFlagged = priceFlagged || priceDeviates(unitPrice, listPrice, s.priceDeviationPercent)
 
 		orderItem := models.OutboundOrderItem{
 			CategoryID: reqItem.CategoryID,
 			Weight:     reqItem.Weight,
-			UnitPrice:  reqItem.UnitPrice,
+			UnitPrice:  unitPrice,
+			ListPrice:  listPrice,
 			SubTotal:   subTotal,
 		}
 		orderItems = append(orderItems, orderItem)
 	}
 
+	// 超过客户信用额度时拒绝下单，超级管理员可指定 credit_override 放行并在订单上留下标记
+	var creditOverride bool
+	if customer != nil {
+		if err := checkCreditLimit(tx, customer, totalAmount); err != nil {
+			if !errors.Is(err, ErrCreditLimitExceeded) || !req.CreditOverride {
+				return nil, err
+			}
+			creditOverride = true
+		}
+	}
+
 	// Create order
 	order := &models.OutboundOrder{
 		OrderNo:         orderNo,
-		DeliveryAddress: req.DeliveryAddress,
-		CarNumber:       req.CarNumber,
-		DriverName:      req.DriverName,
-		DriverPhone:     req.DriverPhone,
+		WarehouseID:     req.WarehouseID,
+		CustomerID:      req.CustomerID,
+		CustomerName:    customerName,
+		DeliveryAddress: deliveryAddress,
+		CarNumber:       carrier.CarNumber,
+		DriverName:      carrier.DriverName,
+		DriverPhone:     carrier.DriverPhone,
+		VehicleID:       carrier.VehicleID,
+		DriverID:        carrier.DriverID,
 		TotalAmount:     totalAmount,
-		Status:          "completed",
+		PaymentStatus:   models.OrderPaymentUnpaid,
+		CreditOverride:  creditOverride,
+		Status:          initialOrderStatus(req.Status),
 		Notes:           req.Notes,
+		PriceFlagged:    priceFlagged,
+		WeighTicketID:   req.WeighTicketID,
 		CreatedBy:       createdBy,
 	}
 
-	if err := s.outboundRepo.Create(order); err != nil {
+	if err := tx.OutboundRepo.Create(order); err != nil {
 		return nil, err
 	}
 
-	// Create order items and update inventory
+	// Create order items
 	for i := range orderItems {
 		orderItems[i].OrderID = order.ID
-		if err := s.outboundRepo.CreateItem(&orderItems[i]); err != nil {
+		if err := tx.OutboundRepo.CreateItem(&orderItems[i]); err != nil {
 			return nil, err
 		}
-
-		// Update inventory
-		if err := s.inventoryRepo.UpdateWeight(orderItems[i].CategoryID, orderItems[i].Weight, false); err != nil {
+		if len(req.Items[i].Lots) > 0 {
+			if err := saveLotSelections(tx, order, &orderItems[i], req.Items[i].Lots, createdBy); err != nil {
+				return nil, err
+			}
+		}
+		if err := saveItemTraceCodes(tx, models.MovementSourceOutbound, order.ID, orderItems[i].ID, req.Items[i].TraceCodes); err != nil {
 			return nil, err
 		}
 	}
 
+	// Update inventory (草稿不扣减库存)
+	if isStockPosted(order.Status) {
+		if err := s.deductStock(tx, order, createdBy); err != nil {
+			return nil, err
+		}
+	}
 	return order, nil
 }
 
@@ -99,7 +191,14 @@ func (s *OutboundService) GetByID(id uint) (*models.GetOutboundOrderDetailResp,
 		return nil, err
 	}
 
-	return &models.GetOutboundOrderDetailResp{Order: *order, Detail: items}, nil
+	resp := &models.GetOutboundOrderDetailResp{Order: *order, Detail: items}
+	if order.CustomerID != nil {
+		// 客户可能已被停用，此时仅返回订单上的名称快照
+		if customer, err := s.repos.CustomerRepo.GetByID(*order.CustomerID); err == nil {
+			resp.Customer = customer
+		}
+	}
+	return resp, nil
 }
 
 // GetAll 获取所有出库订单 (支持条件筛选和分页)
@@ -112,16 +211,43 @@ func (s *OutboundService) GetAllSimple(limit, offset int) ([]models.OutboundOrde
 	return s.outboundRepo.GetAll(limit, offset)
 }
 
+// Confirm 确认草稿订单并扣减库存
+// 草稿不计入应收，确认时按订单金额校验客户信用额度，creditOverride 为 true 时放行并在订单上标记
+func (s *OutboundService) Confirm(id uint, userID uint, creditOverride bool) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		order, err := lockOutboundOrder(tx, id)
+		if err != nil {
+			return err
+		}
+		return s.applyStatus(tx, order, models.OrderStatusConfirmed, userID, creditOverride)
+	})
+}
+
+// Complete 完成已确认的订单
+func (s *OutboundService) Complete(id uint, userID uint) error {
+	return s.UpdateStatus(id, models.OrderStatusCompleted, userID)
+}
+
+// Cancel 取消订单，已扣减库存的订单同时退回库存
+func (s *OutboundService) Cancel(id uint, userID uint) error {
+	return s.UpdateStatus(id, models.OrderStatusCancelled, userID)
+}
+
 // UpdateStatus 显式更新订单状态
-func (s *OutboundService) UpdateStatus(id uint, status string) error {
-	updates := map[string]interface{}{"status": status}
-	return s.outboundRepo.UpdateFields(id, updates)
+// 按订单状态机校验流转：确认时扣减库存，取消已记账订单时退回库存
+func (s *OutboundService) UpdateStatus(id uint, status string, userID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		order, err := lockOutboundOrder(tx, id)
+		if err != nil {
+			return err
+		}
+		return s.applyStatus(tx, order, status, userID, false)
+	})
 }
 
 // UpdateCustomerName 显式更新客户名称
 func (s *OutboundService) UpdateCustomerName(id uint, customerName string) error {
-	updates := map[string]interface{}{"delivery_address": customerName}
-	return s.outboundRepo.UpdateFields(id, updates)
+	return s.outboundRepo.UpdateCustomerName(id, customerName)
 }
 
 // UpdateNotes 显式更新备注
@@ -135,143 +261,392 @@ func (s *OutboundService) UpdateOrder(id uint, updates map[string]interface{}) e
 	return s.outboundRepo.UpdateFields(id, updates)
 }
 
-func (s *OutboundService) Delete(id uint) error {
-	return s.outboundRepo.Delete(id)
+// Delete 删除出库订单
+// 已扣减库存的订单删除前先退回出库重量
+func (s *OutboundService) Delete(id uint, userID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		order, err := lockOutboundOrder(tx, id)
+		if err != nil {
+			return err
+		}
+		if order.ReceivedAmount >= amountTolerance {
+			return errors.New("order has customer receipts, void them first")
+		}
+
+		if isStockPosted(order.Status) {
+			if err := s.returnStock(tx, order, userID); err != nil {
+				return err
+			}
+		}
+		return tx.OutboundRepo.Delete(id)
+	})
 }
 
-// UpdateOrderComplete 完整更新出库订单（包括订单项）
-func (s *OutboundService) UpdateOrderComplete(id uint, req *models.UpdateOutboundOrderRequest) error {
-	// 检查订单是否存在
-	order, err := s.outboundRepo.GetByID(id)
+// applyStatus 校验并更新订单状态，确认时校验信用额度并扣减库存，取消已记账订单时退回库存
+func (s *OutboundService) applyStatus(tx *repository.Repositories, order *models.OutboundOrder, status string, userID uint, creditOverride bool) error {
+	if order.Status == status {
+		return nil
+	}
+	if err := checkOrderTransition(order.Status, status); err != nil {
+		return err
+	}
+
+	switch {
+	case status == models.OrderStatusConfirmed:
+		if err := checkOrderCredit(tx, order, order.TotalAmount, creditOverride); err != nil {
+			return err
+		}
+		if err := s.deductStock(tx, order, userID); err != nil {
+			return err
+		}
+	case status == models.OrderStatusCancelled && order.ReceivedAmount >= amountTolerance:
+		return errors.New("order has customer receipts, void them first")
+	case status == models.OrderStatusCancelled && isStockPosted(order.Status):
+		if err := s.returnStock(tx, order, userID); err != nil {
+			return err
+		}
+	}
+	if err := tx.OutboundRepo.UpdateStatus(order.ID, status); err != nil {
+		return err
+	}
+	order.Status = status
+	return nil
+}
+
+// deductStock 按出库订单各分类重量扣减库存，库存不足时返回错误
+func (s *OutboundService) deductStock(tx *repository.Repositories, order *models.OutboundOrder, userID uint) error {
+	items, err := tx.OutboundRepo.GetRawItemsByOrderID(order.ID)
 	if err != nil {
-		return errors.New("order not found")
+		return err
+	}
+
+	weights := make(map[uint]float64)
+	for _, item := range items {
+		weights[item.CategoryID] += item.Weight
+	}
+
+	// 进行中盘点的分类禁止出库
+	categoryIDs := sortedCategoryIDs(weights)
+	if err := checkStocktakeLock(tx, order.WarehouseID, categoryIDs); err != nil {
+		return err
 	}
 
-	// 如果需要更新订单项，先处理库存恢复
-	if len(req.Items) > 0 {
-		// 获取当前订单项以便恢复库存
-		currentItems, err := s.outboundRepo.GetRawItemsByOrderID(id)
+	source := models.MovementSource{SourceType: models.MovementSourceOutbound, SourceID: order.ID, UserID: userID}
+	for _, categoryID := range categoryIDs {
+		if err := tx.InventoryRepo.UpdateWeight(order.WarehouseID, categoryID, weights[categoryID], false, source); err != nil {
+			return err
+		}
+	}
+
+	// 订单项分配批次: 手工指定的批次优先，否则先进先出
+	selections, err := tx.LotRepo.GetAllocations(models.MovementSourceOutbound, order.ID)
+	if err != nil {
+		return err
+	}
+	selected := make(map[uint][]models.LotAllocation)
+	for _, allocation := range selections {
+		if !allocation.Posted {
+			selected[allocation.SourceItemID] = append(selected[allocation.SourceItemID], allocation)
+		}
+	}
+	for _, item := range items {
+		if allocations, ok := selected[item.ID]; ok {
+			err = postLotSelections(tx, allocations)
+		} else {
+			err = allocateLots(tx, order.WarehouseID, item.CategoryID, item.Weight, source, item.ID)
+		}
 		if err != nil {
 			return err
 		}
+	}
+	return shipTraceCodes(tx, order, items, userID)
+}
 
-		// 恢复当前订单项的库存
-		for _, item := range currentItems {
-			if err := s.inventoryRepo.UpdateWeight(item.CategoryID, item.Weight, true); err != nil {
-				return err
-			}
+// returnStock 将出库订单扣减的库存退回
+func (s *OutboundService) returnStock(tx *repository.Repositories, order *models.OutboundOrder, userID uint) error {
+	// 已开具转移联单的订单货物已随联单转移，不能再退回库存
+	if manifest, err := tx.ManifestRepo.GetByOutboundOrderID(order.ID); err == nil {
+		return fmt.Errorf("order %s has waste manifest %s, stock cannot be returned", order.OrderNo, manifest.ManifestNo)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	items, err := tx.OutboundRepo.GetRawItemsByOrderID(order.ID)
+	if err != nil {
+		return err
+	}
+
+	weights := make(map[uint]float64)
+	for _, item := range items {
+		weights[item.CategoryID] += item.Weight
+	}
+
+	if err := releaseLots(tx, models.MovementSourceOutbound, order.ID); err != nil {
+		return err
+	}
+	if err := reverseTraceCodeShipment(tx, order, userID); err != nil {
+		return err
+	}
+
+	for _, categoryID := range sortedCategoryIDs(weights) {
+		// 按出库时的成本退回
+		unitCost, err := tx.InventoryRepo.GetSourceUnitCost(categoryID, models.MovementSourceOutbound, order.ID)
+		if err != nil {
+			return err
+		}
+		source := models.MovementSource{SourceType: models.MovementSourceOutbound, SourceID: order.ID, UserID: userID, UnitCost: unitCost}
+		if err := tx.InventoryRepo.UpdateWeight(order.WarehouseID, categoryID, weights[categoryID], true, source); err != nil {
+			return fmt.Errorf("failed to return stock for outbound order %s: %w", order.OrderNo, err)
 		}
+	}
+	return nil
+}
 
-		// 删除当前所有订单项
-		if err := s.outboundRepo.DeleteItemsByOrderID(id); err != nil {
+// UpdateOrderComplete 完整更新出库订单（包括订单项）
+func (s *OutboundService) UpdateOrderComplete(id uint, req *models.UpdateOutboundOrderRequest, userID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		// 检查订单是否存在
+		order, err := lockOutboundOrder(tx, id)
+		if err != nil {
 			return err
 		}
+		if order.Status == models.OrderStatusCancelled {
+			return errors.New("cancelled order cannot be modified")
+		}
+		oldTotal := order.TotalAmount
+
+		// 如果需要更新订单项，先处理库存恢复
+		if len(req.Items) > 0 {
+			// 恢复当前订单项的库存 (草稿未扣减库存)
+			posted := isStockPosted(order.Status)
+			if posted {
+				if err := s.returnStock(tx, order, userID); err != nil {
+					return err
+				}
+			}
 
-		// 处理新的订单项并计算新的总金额
-		var totalAmount float64
-		for _, reqItem := range req.Items {
-			// 检查库存是否足够
-			inventory, err := s.inventoryRepo.GetByCategoryID(reqItem.CategoryID)
-			if err != nil {
-				return errors.New("inventory not found for category")
+			// 删除当前所有订单项及其手工指定的批次
+			if err := tx.OutboundRepo.DeleteItemsByOrderID(id); err != nil {
+				return err
+			}
+			if err := tx.LotRepo.DeleteAllocations(models.MovementSourceOutbound, id); err != nil {
+				return err
+			}
+			if err := tx.TraceCodeRepo.DeleteItemCodes(models.MovementSourceOutbound, id, 0); err != nil {
+				return err
 			}
 
-			if inventory.CurrentWeightKg < reqItem.Weight {
-				return errors.New("insufficient inventory for category")
+			// 处理新的订单项并计算新的总金额 (未填写单价时取下单时的销售价)
+			var totalAmount float64
+			var priceFlagged bool
+			var itemCount int
+			for _, reqItem := range req.Items {
+				// 订单项整体替换，标记删除的订单项不再创建
+				if reqItem.Action == "delete" {
+					continue
+				}
+				itemCount++
+
+				unitPrice, listPrice, err := resolveUnitPrice(tx, reqItem.CategoryID, models.PriceTypeSell, order.CreatedAt, reqItem.UnitPrice)
+				if err != nil {
+					return err
+				}
+
+				// 创建新订单项
+				subTotal := reqItem.Weight * unitPrice
+				totalAmount += subTotal
+				priceFlagged = priceFlagged || priceDeviates(unitPrice, listPrice, s.priceDeviationPercent)
+
+				newItem := &models.OutboundOrderItem{
+					OrderID:    id,
+					CategoryID: reqItem.CategoryID,
+					Weight:     reqItem.Weight,
+					UnitPrice:  unitPrice,
+					ListPrice:  listPrice,
+					SubTotal:   subTotal,
+				}
+
+				if err := tx.OutboundRepo.CreateItem(newItem); err != nil {
+					return err
+				}
+				if len(reqItem.Lots) > 0 {
+					if err := saveLotSelections(tx, order, newItem, reqItem.Lots, userID); err != nil {
+						return err
+					}
+				}
+				if err := saveItemTraceCodes(tx, models.MovementSourceOutbound, id, newItem.ID, reqItem.TraceCodes); err != nil {
+					return err
+				}
 			}
 
-			// 创建新订单项
-			subTotal := reqItem.Weight * reqItem.UnitPrice
-			totalAmount += subTotal
+			if itemCount == 0 {
+				return errors.New("order must have at least one item")
+			}
 
-			newItem := &models.OutboundOrderItem{
-				OrderID:    id,
-				CategoryID: reqItem.CategoryID,
-				Weight:     reqItem.Weight,
-				UnitPrice:  reqItem.UnitPrice,
-				SubTotal:   subTotal,
+			// 更新库存（减少），库存不足时整体回滚
+			if posted {
+				if err := s.deductStock(tx, order, userID); err != nil {
+					return err
+				}
 			}
 
-			if err := s.outboundRepo.CreateItem(newItem); err != nil {
-				return err
+			// 更新订单总金额和价格偏离标记
+			order.TotalAmount = totalAmount
+			order.PriceFlagged = priceFlagged
+		}
+
+		// 更新订单基本信息
+		updates, err := s.headerUpdates(order, req)
+		if err != nil {
+			return err
+		}
+		if len(req.Items) > 0 {
+			// 已收款的订单金额不能低于已收金额
+			if order.ReceivedAmount >= amountTolerance && roundAmount(order.TotalAmount) < order.ReceivedAmount-amountTolerance {
+				return fmt.Errorf("total amount %.2f is less than received amount %.2f", order.TotalAmount, order.ReceivedAmount)
 			}
+			updates["total_amount"] = order.TotalAmount
+			updates["payment_status"] = orderPaymentStatus(order.TotalAmount, order.ReceivedAmount)
+			updates["price_flagged"] = order.PriceFlagged
+		}
+		if err := recheckOrderCredit(tx, order, oldTotal, req); err != nil {
+			return err
+		}
 
-			// 更新库存（减少）
-			if err := s.inventoryRepo.UpdateWeight(reqItem.CategoryID, reqItem.Weight, false); err != nil {
+		// 执行更新
+		if len(updates) > 0 {
+			if err := tx.OutboundRepo.UpdateFields(id, updates); err != nil {
 				return err
 			}
 		}
 
-		// 更新订单总金额
-		order.TotalAmount = totalAmount
-	}
+		// 状态变更 (取消时退回更新后订单项的库存)
+		if req.Status != "" {
+			return s.applyStatus(tx, order, req.Status, userID, req.CreditOverride)
+		}
 
-	// 更新订单基本信息
-	updates := make(map[string]interface{})
-	if req.DeliveryAddress != "" {
-		updates["delivery_address"] = req.DeliveryAddress
-		order.DeliveryAddress = req.DeliveryAddress
-	}
-	if req.CarNumber != "" {
-		updates["car_number"] = req.CarNumber
-		order.CarNumber = req.CarNumber
-	}
-	if req.DriverName != "" {
-		updates["driver_name"] = req.DriverName
-		order.DriverName = req.DriverName
-	}
-	if req.DriverPhone != "" {
-		updates["driver_phone"] = req.DriverPhone
-		order.DriverPhone = req.DriverPhone
+		return nil
+	})
+}
+
+// UpdateOrderBasic 仅更新订单基本信息（不包括订单项）
+func (s *OutboundService) UpdateOrderBasic(id uint, req *models.UpdateOutboundOrderRequest, userID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		order, err := lockOutboundOrder(tx, id)
+		if err != nil {
+			return err
+		}
+
+		updates, err := s.headerUpdates(order, req)
+		if err != nil {
+			return err
+		}
+		if len(updates) == 0 && req.Status == "" {
+			return errors.New("no fields to update")
+		}
+		if err := recheckOrderCredit(tx, order, order.TotalAmount, req); err != nil {
+			return err
+		}
+
+		if len(updates) > 0 {
+			if err := tx.OutboundRepo.UpdateFields(id, updates); err != nil {
+				return err
+			}
+		}
+		if req.Status != "" {
+			return s.applyStatus(tx, order, req.Status, userID, req.CreditOverride)
+		}
+		return nil
+	})
+}
+
+// recheckOrderCredit 修改订单后同步客户，已记账订单增加金额或更换客户时重新校验信用额度
+// 须在新金额和客户写入前调用：此时客户应收中仍是修改前的金额，同一客户按增加的金额校验，更换客户时按订单全额校验新客户
+// 草稿不计入应收，在确认时校验
+func recheckOrderCredit(tx *repository.Repositories, order *models.OutboundOrder, oldTotal float64, req *models.UpdateOutboundOrderRequest) error {
+	amount := order.TotalAmount - oldTotal
+	if req.CustomerID != nil {
+		if order.CustomerID == nil || *order.CustomerID != *req.CustomerID {
+			amount = order.TotalAmount
+		}
+		order.CustomerID = req.CustomerID
 	}
-	if req.Status != "" {
-		updates["status"] = req.Status
-		order.Status = req.Status
+	if !isStockPosted(order.Status) || req.Status == models.OrderStatusCancelled {
+		return nil
 	}
-	if req.Notes != "" {
-		updates["notes"] = req.Notes
-		order.Notes = req.Notes
+	return checkOrderCredit(tx, order, amount, req.CreditOverride)
+}
+
+// checkOrderCredit 校验订单新增的应收金额是否超过客户信用额度
+// 超过额度且 creditOverride 为 true 时放行并在订单上标记 credit_override
+func checkOrderCredit(tx *repository.Repositories, order *models.OutboundOrder, amount float64, creditOverride bool) error {
+	if order.CustomerID == nil || amount < amountTolerance {
+		return nil
 	}
-	if len(req.Items) > 0 {
-		updates["total_amount"] = order.TotalAmount
+	// 客户行加锁，同一客户的并发下单和修改按顺序校验信用额度
+	customer, err := tx.CustomerRepo.GetByIDForUpdate(*order.CustomerID)
+	if err != nil {
+		return errors.New("customer not found")
 	}
-
-	// 执行更新
-	if len(updates) > 0 {
-		if err := s.outboundRepo.UpdateFields(id, updates); err != nil {
+	if err := checkCreditLimit(tx, customer, amount); err != nil {
+		if !errors.Is(err, ErrCreditLimitExceeded) || !creditOverride {
 			return err
 		}
+		order.CreditOverride = true
+		return tx.OutboundRepo.UpdateFields(order.ID, map[string]interface{}{"credit_override": true})
 	}
-
 	return nil
 }
 
-// UpdateOrderBasic 仅更新订单基本信息（不包括订单项）
-func (s *OutboundService) UpdateOrderBasic(id uint, req *models.UpdateOutboundOrderRequest) error {
+// lockOutboundOrder 在事务中读取出库订单并加行锁，并发的状态变更、修改和删除按顺序执行
+func lockOutboundOrder(tx *repository.Repositories, id uint) (*models.OutboundOrder, error) {
+	order, err := tx.OutboundRepo.GetByIDForUpdate(id)
+	if err != nil || order.IsDeleted == 1 {
+		return nil, errors.New("order not found")
+	}
+	return order, nil
+}
+
+// headerUpdates 根据更新请求构建订单头字段，指定客户时同步客户名称快照
+// 已有收款的订单不能更换客户
+func (s *OutboundService) headerUpdates(order *models.OutboundOrder, req *models.UpdateOutboundOrderRequest) (map[string]interface{}, error) {
 	updates := make(map[string]interface{})
 
+	if req.CustomerID != nil {
+		customer, err := s.repos.CustomerRepo.GetByID(*req.CustomerID)
+		if err != nil {
+			return nil, errors.New("customer not found")
+		}
+		if order.ReceivedAmount >= amountTolerance && (order.CustomerID == nil || *order.CustomerID != customer.ID) {
+			return nil, errors.New("order has customer receipts, customer cannot be changed")
+		}
+		updates["customer_id"] = customer.ID
+		updates["customer_name"] = customer.Name
+	}
 	if req.DeliveryAddress != "" {
 		updates["delivery_address"] = req.DeliveryAddress
 	}
-	if req.CarNumber != "" {
-		updates["car_number"] = req.CarNumber
-	}
-	if req.DriverName != "" {
-		updates["driver_name"] = req.DriverName
+
+	// 修改车辆或司机时按档案补全，手工填写未登记的车号/司机时解除档案关联
+	carrier, err := resolveCarrier(s.repos, req.VehicleID, req.DriverID, req.CarNumber, req.DriverName, req.DriverPhone)
+	if err != nil {
+		return nil, err
 	}
-	if req.DriverPhone != "" {
-		updates["driver_phone"] = req.DriverPhone
+	if req.VehicleID != nil || req.CarNumber != "" {
+		updates["car_number"] = carrier.CarNumber
+		updates["vehicle_id"] = carrier.VehicleID
 	}
-	if req.Status != "" {
-		updates["status"] = req.Status
+	if req.DriverID != nil || req.DriverName != "" || req.DriverPhone != "" {
+		if carrier.DriverName != "" {
+			updates["driver_name"] = carrier.DriverName
+		}
+		if carrier.DriverPhone != "" {
+			updates["driver_phone"] = carrier.DriverPhone
+		}
+		updates["driver_id"] = carrier.DriverID
 	}
 	if req.Notes != "" {
 		updates["notes"] = req.Notes
 	}
-
-	if len(updates) == 0 {
-		return errors.New("no fields to update")
-	}
-
-	return s.outboundRepo.UpdateFields(id, updates)
+	return updates, nil
 }
@@ -0,0 +1,331 @@
+package services
+
+import (
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/repository"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// amountTolerance 金额比较容差 (数据库保留2位小数)
+const amountTolerance = 0.005
+
+// SupplierPaymentService 供应商付款和应付账款服务
+type SupplierPaymentService struct {
+	repos *repository.Repositories
+	repo  *repository.SupplierPaymentRepository
+}
+
+// NewSupplierPaymentService 创建供应商付款服务实例
+func NewSupplierPaymentService(repos *repository.Repositories) *SupplierPaymentService {
+	return &SupplierPaymentService{
+		repos: repos,
+		repo:  repos.PaymentRepo,
+	}
+}
+
+// Create 登记付款并分摊到入库订单，支持部分付款
+// 分摊的订单须已记账、属于同一供应商，且分摊金额不超过订单未付金额
+func (s *SupplierPaymentService) Create(req *models.CreateSupplierPaymentRequest, userID uint) (*models.SupplierPayment, error) {
+	paidAt := time.Now()
+	if req.PaidAt != "" {
+		day, err := time.ParseInLocation(reportDateLayout, req.PaidAt, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid paid_at: %s", req.PaidAt)
+		}
+		paidAt = day
+	}
+	paidAt = time.Date(paidAt.Year(), paidAt.Month(), paidAt.Day(), 0, 0, 0, 0, time.Local)
+
+	// 按订单ID顺序加锁，避免并发付款死锁
+	allocations := make([]models.PaymentAllocationInput, len(req.Allocations))
+	copy(allocations, req.Allocations)
+	sort.Slice(allocations, func(i, j int) bool { return allocations[i].OrderID < allocations[j].OrderID })
+	for i := 1; i < len(allocations); i++ {
+		if allocations[i].OrderID == allocations[i-1].OrderID {
+			return nil, fmt.Errorf("order %d is allocated more than once", allocations[i].OrderID)
+		}
+	}
+
+	var payment *models.SupplierPayment
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		orders := make([]*models.InboundOrder, len(allocations))
+		var total float64
+		for i, allocation := range allocations {
+			order, err := tx.InboundRepo.GetByIDForUpdate(allocation.OrderID)
+			if err != nil || order.IsDeleted == 1 {
+				return fmt.Errorf("order %d not found", allocation.OrderID)
+			}
+			if !isStockPosted(order.Status) {
+				return fmt.Errorf("order %s is %s and cannot be paid", order.OrderNo, order.Status)
+			}
+			if i > 0 && !sameSupplier(orders[0], order) {
+				return fmt.Errorf("order %s belongs to a different supplier", order.OrderNo)
+			}
+
+			amount := roundAmount(allocation.Amount)
+			outstanding := roundAmount(order.TotalAmount - order.PaidAmount)
+			if amount > outstanding+amountTolerance {
+				return fmt.Errorf("payment %.2f exceeds outstanding %.2f of order %s", amount, outstanding, order.OrderNo)
+			}
+			allocations[i].Amount = amount
+			orders[i] = order
+			total += amount
+		}
+
+		date := time.Now().Format("20060102")
+		seq, err := tx.SequenceRepo.Next("supplier_payment_" + date)
+		if err != nil {
+			return err
+		}
+
+		payment = &models.SupplierPayment{
+			PaymentNo:    fmt.Sprintf("SP%s%04d", date, seq),
+			SellerID:     orders[0].SellerID,
+			SupplierName: orders[0].SupplierName,
+			Amount:       roundAmount(total),
+			Method:       req.Method,
+			PaidAt:       paidAt,
+			Reference:    strings.TrimSpace(req.Reference),
+			Status:       models.PaymentStatusPosted,
+			Notes:        req.Notes,
+			CreatedBy:    userID,
+		}
+		if err := tx.PaymentRepo.Create(payment); err != nil {
+			return err
+		}
+
+		for i, order := range orders {
+			allocation := models.SupplierPaymentAllocation{
+				PaymentID:      payment.ID,
+				InboundOrderID: order.ID,
+				OrderNo:        order.OrderNo,
+				Amount:         allocations[i].Amount,
+			}
+			if err := tx.PaymentRepo.CreateAllocation(&allocation); err != nil {
+				return err
+			}
+			payment.Allocations = append(payment.Allocations, allocation)
+
+			if err := applyOrderPayment(tx, order, allocation.Amount); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// GetByID 获取付款单及分摊明细
+func (s *SupplierPaymentService) GetByID(id uint) (*models.SupplierPayment, error) {
+	payment, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("payment not found")
+	}
+	allocations, err := s.repo.GetAllocations(id)
+	if err != nil {
+		return nil, err
+	}
+	payment.Allocations = allocations
+	return payment, nil
+}
+
+// GetAll 分页获取付款单
+func (s *SupplierPaymentService) GetAll(req *models.GetSupplierPaymentsRequest) (*models.GetSupplierPaymentsResponse, error) {
+	payments, total, err := s.repo.GetAll(req)
+	if err != nil {
+		return nil, err
+	}
+	return &models.GetSupplierPaymentsResponse{
+		Payments: payments,
+		Total:    total,
+	}, nil
+}
+
+// Void 作废付款单，冲回各订单的已付金额
+func (s *SupplierPaymentService) Void(id uint, userID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		payment, err := tx.PaymentRepo.GetByIDForUpdate(id)
+		if err != nil {
+			return errors.New("payment not found")
+		}
+		if payment.Status != models.PaymentStatusPosted {
+			return fmt.Errorf("payment is %s and cannot be voided", payment.Status)
+		}
+
+		allocations, err := tx.PaymentRepo.GetAllocations(id)
+		if err != nil {
+			return err
+		}
+		sort.Slice(allocations, func(i, j int) bool { return allocations[i].InboundOrderID < allocations[j].InboundOrderID })
+		for _, allocation := range allocations {
+			order, err := tx.InboundRepo.GetByIDForUpdate(allocation.InboundOrderID)
+			if err != nil {
+				return err
+			}
+			if err := applyOrderPayment(tx, order, -allocation.Amount); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		return tx.PaymentRepo.UpdateFields(id, map[string]interface{}{
+			"status":    models.PaymentStatusVoided,
+			"voided_by": userID,
+			"voided_at": now,
+		})
+	})
+}
+
+// GetSupplierBalance 获取卖家的应付余额和未付清订单
+func (s *SupplierPaymentService) GetSupplierBalance(sellerID uint) (*models.SupplierBalance, error) {
+	seller, err := s.repos.SellerRepo.GetByID(sellerID)
+	if err != nil {
+		return nil, errors.New("seller not found")
+	}
+
+	payable, paid, err := s.repos.InboundRepo.GetSellerPayableTotals(sellerID)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := s.repos.InboundRepo.GetUnpaidBySeller(sellerID)
+	if err != nil {
+		return nil, err
+	}
+	lastPaymentAt, err := s.repo.GetLastPaymentAt(sellerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SupplierBalance{
+		Seller:        *seller,
+		TotalPayable:  roundAmount(payable),
+		TotalPaid:     roundAmount(paid),
+		Outstanding:   roundAmount(payable - paid),
+		UnpaidOrders:  orders,
+		LastPaymentAt: lastPaymentAt,
+	}, nil
+}
+
+// GetAging 应付账款账龄报表，按供应商汇总截至 asOf 当天的未付金额
+// 账龄按订单创建日期计算，分为 0-30、31-60、61-90 和 90 天以上
+func (s *SupplierPaymentService) GetAging(req *models.GetAgingRequest) (*models.PayablesAgingReport, error) {
+	day, err := parseAsOfDay(req.AsOf)
+	if err != nil {
+		return nil, err
+	}
+
+	balances, err := s.repo.GetOpenPayables(day.Format(reportDateLayout))
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.PayablesAgingReport{
+		AsOf:      day.Format(reportDateLayout),
+		Suppliers: []models.SupplierAging{},
+	}
+	// 关联了卖家的订单按卖家汇总，其余按供应商名称汇总
+	index := make(map[string]int)
+	for _, balance := range balances {
+		key := "name:" + balance.PartyName
+		if balance.PartyID != nil {
+			key = fmt.Sprintf("id:%d", *balance.PartyID)
+		}
+		i, ok := index[key]
+		if !ok {
+			report.Suppliers = append(report.Suppliers, models.SupplierAging{
+				SellerID:     balance.PartyID,
+				SupplierName: balance.PartyName,
+			})
+			i = len(report.Suppliers) - 1
+			index[key] = i
+		}
+
+		days := agingDays(day, balance.CreatedAt)
+		report.Suppliers[i].OrderCount++
+		addAging(&report.Suppliers[i].AgingBuckets, days, balance.Outstanding)
+		addAging(&report.Total, days, balance.Outstanding)
+	}
+
+	sort.SliceStable(report.Suppliers, func(i, j int) bool {
+		return report.Suppliers[i].Outstanding > report.Suppliers[j].Outstanding
+	})
+	return report, nil
+}
+
+// applyOrderPayment 把付款 (负数为冲回) 记入入库订单的已付金额并更新付款状态
+func applyOrderPayment(tx *repository.Repositories, order *models.InboundOrder, amount float64) error {
+	paid := math.Max(roundAmount(order.PaidAmount+amount), 0)
+	order.PaidAmount = paid
+	order.PaymentStatus = orderPaymentStatus(order.TotalAmount, paid)
+	return tx.InboundRepo.UpdateFields(order.ID, map[string]interface{}{
+		"paid_amount":    order.PaidAmount,
+		"payment_status": order.PaymentStatus,
+	})
+}
+
+// orderPaymentStatus 根据订单金额和已付金额计算付款状态
+func orderPaymentStatus(total, paid float64) string {
+	switch {
+	case paid < amountTolerance:
+		return models.OrderPaymentUnpaid
+	case paid > total-amountTolerance:
+		return models.OrderPaymentPaid
+	default:
+		return models.OrderPaymentPartial
+	}
+}
+
+// sameSupplier 两张入库订单是否属于同一供应商：关联了卖家时比较卖家，否则比较供应商名称
+func sameSupplier(a, b *models.InboundOrder) bool {
+	if a.SellerID != nil || b.SellerID != nil {
+		return a.SellerID != nil && b.SellerID != nil && *a.SellerID == *b.SellerID
+	}
+	return a.SupplierName == b.SupplierName
+}
+
+// parseAsOfDay 解析截止日期 (YYYY-MM-DD)，为空时取今天，返回当天零点
+func parseAsOfDay(asOf string) (time.Time, error) {
+	day := time.Now()
+	if asOf != "" {
+		var err error
+		day, err = time.ParseInLocation(reportDateLayout, asOf, time.Local)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid as_of: %s", asOf)
+		}
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.Local), nil
+}
+
+// agingDays 单据创建日到截止日的天数
+func agingDays(asOf, createdAt time.Time) int {
+	created := time.Date(createdAt.Year(), createdAt.Month(), createdAt.Day(), 0, 0, 0, 0, time.Local)
+	return int(math.Round(asOf.Sub(created).Hours() / 24))
+}
+
+// addAging 把未结金额按账龄天数计入对应分段
+func addAging(buckets *models.AgingBuckets, days int, amount float64) {
+	switch {
+	case days <= 30:
+		buckets.Days0To30 = roundAmount(buckets.Days0To30 + amount)
+	case days <= 60:
+		buckets.Days31To60 = roundAmount(buckets.Days31To60 + amount)
+	case days <= 90:
+		buckets.Days61To90 = roundAmount(buckets.Days61To90 + amount)
+	default:
+		buckets.DaysOver90 = roundAmount(buckets.DaysOver90 + amount)
+	}
+	buckets.Outstanding = roundAmount(buckets.Outstanding + amount)
+}
+
+// roundAmount 金额保留两位小数，与数据库精度一致
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/repository"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PriceService 分类价格服务
+// 收购价和销售价按生效时间保留历史，订单未填写单价时取下单时有效的价格
+type PriceService struct {
+	repos *repository.Repositories
+	repo  *repository.PriceRepository
+}
+
+// NewPriceService 创建价格服务实例
+func NewPriceService(repos *repository.Repositories) *PriceService {
+	return &PriceService{
+		repos: repos,
+		repo:  repos.PriceRepo,
+	}
+}
+
+// GetHistory 获取分类的当前价格和价格历史，priceType 为空时返回收购价和销售价
+func (s *PriceService) GetHistory(categoryID uint, priceType string) (*models.CategoryPriceHistory, error) {
+	category, err := s.repos.CategoryRepo.GetByID(categoryID)
+	if err != nil {
+		return nil, errors.New("category not found")
+	}
+
+	prices, err := s.repo.GetByCategory(categoryID, priceType)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &models.CategoryPriceHistory{
+		CategoryID:   category.ID,
+		CategoryName: category.Name,
+		Prices:       prices,
+	}
+	now := time.Now()
+	if history.BuyPrice, err = s.currentPrice(categoryID, models.PriceTypeBuy, now); err != nil {
+		return nil, err
+	}
+	if history.SellPrice, err = s.currentPrice(categoryID, models.PriceTypeSell, now); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// Create 新增分类价格，生效时间为空时立即生效；收购价生效后同步分类单价
+func (s *PriceService) Create(categoryID uint, req *models.CreateCategoryPriceRequest, userID uint) (*models.CategoryPrice, error) {
+	if _, err := s.repos.CategoryRepo.GetByID(categoryID); err != nil {
+		return nil, errors.New("category not found")
+	}
+
+	effectiveFrom := time.Now()
+	if req.EffectiveFrom != "" {
+		var err error
+		effectiveFrom, err = parseEffectiveFrom(req.EffectiveFrom)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	price := &models.CategoryPrice{
+		CategoryID:    categoryID,
+		PriceType:     req.PriceType,
+		UnitPrice:     req.UnitPrice,
+		EffectiveFrom: effectiveFrom,
+		Notes:         req.Notes,
+		CreatedBy:     userID,
+	}
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.PriceRepo.Create(price); err != nil {
+			return err
+		}
+		if price.PriceType == models.PriceTypeBuy {
+			return syncCategoryUnitPrice(tx, categoryID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return price, nil
+}
+
+// GetPriceList 获取 asOf 当天结束时各分类有效的收购价和销售价，asOf 为空时取当前价格
+func (s *PriceService) GetPriceList(asOf string) (*models.PriceList, error) {
+	at := time.Now()
+	if asOf != "" {
+		day, err := time.ParseInLocation(reportDateLayout, asOf, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid as_of: %s", asOf)
+		}
+		at = day.AddDate(0, 0, 1).Add(-time.Millisecond)
+	}
+
+	categories, err := s.repos.CategoryRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	prices, err := s.repo.GetAllEffective(at)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(categories, func(i, j int) bool { return categories[i].ID < categories[j].ID })
+	list := &models.PriceList{AsOf: at, Entries: make([]models.PriceListEntry, len(categories))}
+	index := make(map[uint]int, len(categories))
+	for i, category := range categories {
+		list.Entries[i] = models.PriceListEntry{CategoryID: category.ID, CategoryName: category.Name}
+		index[category.ID] = i
+	}
+
+	for i := range prices {
+		price := &prices[i]
+		pos, ok := index[price.CategoryID]
+		if !ok {
+			continue
+		}
+		entry := &list.Entries[pos]
+		if price.PriceType == models.PriceTypeBuy {
+			entry.BuyPrice = &price.UnitPrice
+			entry.BuyEffectiveFrom = &price.EffectiveFrom
+		} else {
+			entry.SellPrice = &price.UnitPrice
+			entry.SellEffectiveFrom = &price.EffectiveFrom
+		}
+	}
+	return list, nil
+}
+
+// currentPrice 获取分类在指定时间有效的价格，没有价格时返回 nil
+func (s *PriceService) currentPrice(categoryID uint, priceType string, at time.Time) (*float64, error) {
+	price, err := s.repo.GetEffective(categoryID, priceType, at)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &price.UnitPrice, nil
+}
+
+// recordBuyPrice 记录立即生效的收购价并同步分类单价，用于直接修改分类单价
+func recordBuyPrice(tx *repository.Repositories, categoryID uint, unitPrice float64, notes string, userID uint) error {
+	price := &models.CategoryPrice{
+		CategoryID:    categoryID,
+		PriceType:     models.PriceTypeBuy,
+		UnitPrice:     unitPrice,
+		EffectiveFrom: time.Now(),
+		Notes:         notes,
+		CreatedBy:     userID,
+	}
+	if err := tx.PriceRepo.Create(price); err != nil {
+		return err
+	}
+	return syncCategoryUnitPrice(tx, categoryID)
+}
+
+// syncCategoryUnitPrice 将分类单价同步为当前有效的收购价
+func syncCategoryUnitPrice(tx *repository.Repositories, categoryID uint) error {
+	price, err := tx.PriceRepo.GetEffective(categoryID, models.PriceTypeBuy, time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// 只有未来生效的价格
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return tx.CategoryRepo.UpdateUnitPrice(categoryID, price.UnitPrice)
+}
+
+// applyCurrentBuyPrices 以当前有效的收购价填充分类单价
+// 分类表中的单价只在写入价格时同步，未来生效的价格到期后不会回写，读取时以价格历史为准
+func applyCurrentBuyPrices(repos *repository.Repositories, categories []models.BatteryCategory) error {
+	prices, err := repos.PriceRepo.GetAllEffective(time.Now())
+	if err != nil {
+		return err
+	}
+	buyPrices := make(map[uint]float64, len(prices))
+	for _, price := range prices {
+		if price.PriceType == models.PriceTypeBuy {
+			buyPrices[price.CategoryID] = price.UnitPrice
+		}
+	}
+	for i := range categories {
+		if price, ok := buyPrices[categories[i].ID]; ok {
+			categories[i].UnitPrice = price
+		}
+	}
+	return nil
+}
+
+// resolveUnitPrice 取订单项单价和下单时的价目表价格，未填写单价时使用价目表价格
+// 返回的 listPrice 为 0 表示该分类在下单时没有价目
+func resolveUnitPrice(tx *repository.Repositories, categoryID uint, priceType string, at time.Time, unitPrice float64) (price, listPrice float64, err error) {
+	list, err := tx.PriceRepo.GetEffective(categoryID, priceType, at)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, 0, err
+	}
+	if list != nil {
+		listPrice = list.UnitPrice
+	}
+
+	if unitPrice > 0 {
+		return unitPrice, listPrice, nil
+	}
+	if listPrice <= 0 {
+		return 0, 0, fmt.Errorf("category %d has no %s price at %s, unit_price is required", categoryID, priceType, at.Format("2006-01-02 15:04"))
+	}
+	return listPrice, listPrice, nil
+}
+
+// priceDeviates 单价偏离价目表价格超过 thresholdPercent 时返回 true，没有价目或阈值为 0 时不检查
+func priceDeviates(unitPrice, listPrice, thresholdPercent float64) bool {
+	if thresholdPercent <= 0 || listPrice <= 0 {
+		return false
+	}
+	return math.Abs(unitPrice-listPrice)/listPrice*100 > thresholdPercent
+}
+
+// parseEffectiveFrom 解析生效时间，支持 YYYY-MM-DD (当天零点) 和 RFC3339
+func parseEffectiveFrom(value string) (time.Time, error) {
+	if day, err := time.ParseInLocation(reportDateLayout, value, time.Local); err == nil {
+		return day, nil
+	}
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid effective_from: %s", value)
+}
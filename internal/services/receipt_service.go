@@ -0,0 +1,304 @@
+package services
+
+import (
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/repository"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrCreditLimitExceeded 客户未收金额加新订单金额超过信用额度
+var ErrCreditLimitExceeded = errors.New("credit limit exceeded")
+
+// CustomerReceiptService 客户收款和应收账款服务
+type CustomerReceiptService struct {
+	repos *repository.Repositories
+	repo  *repository.CustomerReceiptRepository
+}
+
+// NewCustomerReceiptService 创建客户收款服务实例
+func NewCustomerReceiptService(repos *repository.Repositories) *CustomerReceiptService {
+	return &CustomerReceiptService{
+		repos: repos,
+		repo:  repos.ReceiptRepo,
+	}
+}
+
+// Create 登记收款并核销出库订单，支持部分收款
+// 核销的订单须已记账、属于同一客户，且核销金额不超过订单未收金额
+func (s *CustomerReceiptService) Create(req *models.CreateCustomerReceiptRequest, userID uint) (*models.CustomerReceipt, error) {
+	receivedAt := time.Now()
+	if req.ReceivedAt != "" {
+		day, err := time.ParseInLocation(reportDateLayout, req.ReceivedAt, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid received_at: %s", req.ReceivedAt)
+		}
+		receivedAt = day
+	}
+	receivedAt = time.Date(receivedAt.Year(), receivedAt.Month(), receivedAt.Day(), 0, 0, 0, 0, time.Local)
+
+	// 按订单ID顺序加锁，避免并发收款死锁
+	allocations := make([]models.PaymentAllocationInput, len(req.Allocations))
+	copy(allocations, req.Allocations)
+	sort.Slice(allocations, func(i, j int) bool { return allocations[i].OrderID < allocations[j].OrderID })
+	for i := 1; i < len(allocations); i++ {
+		if allocations[i].OrderID == allocations[i-1].OrderID {
+			return nil, fmt.Errorf("order %d is allocated more than once", allocations[i].OrderID)
+		}
+	}
+
+	var receipt *models.CustomerReceipt
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		orders := make([]*models.OutboundOrder, len(allocations))
+		var total float64
+		for i, allocation := range allocations {
+			order, err := tx.OutboundRepo.GetByIDForUpdate(allocation.OrderID)
+			if err != nil || order.IsDeleted == 1 {
+				return fmt.Errorf("order %d not found", allocation.OrderID)
+			}
+			if !isStockPosted(order.Status) {
+				return fmt.Errorf("order %s is %s and cannot be collected", order.OrderNo, order.Status)
+			}
+			if i > 0 && !sameCustomer(orders[0], order) {
+				return fmt.Errorf("order %s belongs to a different customer", order.OrderNo)
+			}
+
+			amount := roundAmount(allocation.Amount)
+			outstanding := roundAmount(order.TotalAmount - order.ReceivedAmount)
+			if amount > outstanding+amountTolerance {
+				return fmt.Errorf("receipt %.2f exceeds outstanding %.2f of order %s", amount, outstanding, order.OrderNo)
+			}
+			allocations[i].Amount = amount
+			orders[i] = order
+			total += amount
+		}
+
+		date := time.Now().Format("20060102")
+		seq, err := tx.SequenceRepo.Next("customer_receipt_" + date)
+		if err != nil {
+			return err
+		}
+
+		receipt = &models.CustomerReceipt{
+			ReceiptNo:    fmt.Sprintf("CR%s%04d", date, seq),
+			CustomerID:   orders[0].CustomerID,
+			CustomerName: orders[0].CustomerName,
+			Amount:       roundAmount(total),
+			Method:       req.Method,
+			ReceivedAt:   receivedAt,
+			Reference:    strings.TrimSpace(req.Reference),
+			Status:       models.PaymentStatusPosted,
+			Notes:        req.Notes,
+			CreatedBy:    userID,
+		}
+		if err := tx.ReceiptRepo.Create(receipt); err != nil {
+			return err
+		}
+
+		for i, order := range orders {
+			allocation := models.CustomerReceiptAllocation{
+				ReceiptID:       receipt.ID,
+				OutboundOrderID: order.ID,
+				OrderNo:         order.OrderNo,
+				Amount:          allocations[i].Amount,
+			}
+			if err := tx.ReceiptRepo.CreateAllocation(&allocation); err != nil {
+				return err
+			}
+			receipt.Allocations = append(receipt.Allocations, allocation)
+
+			if err := applyOrderReceipt(tx, order, allocation.Amount); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// GetByID 获取收款单及核销明细
+func (s *CustomerReceiptService) GetByID(id uint) (*models.CustomerReceipt, error) {
+	receipt, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("receipt not found")
+	}
+	allocations, err := s.repo.GetAllocations(id)
+	if err != nil {
+		return nil, err
+	}
+	receipt.Allocations = allocations
+	return receipt, nil
+}
+
+// GetAll 分页获取收款单
+func (s *CustomerReceiptService) GetAll(req *models.GetCustomerReceiptsRequest) (*models.GetCustomerReceiptsResponse, error) {
+	receipts, total, err := s.repo.GetAll(req)
+	if err != nil {
+		return nil, err
+	}
+	return &models.GetCustomerReceiptsResponse{
+		Receipts: receipts,
+		Total:    total,
+	}, nil
+}
+
+// Void 作废收款单，冲回各订单的已收金额
+func (s *CustomerReceiptService) Void(id uint, userID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		receipt, err := tx.ReceiptRepo.GetByIDForUpdate(id)
+		if err != nil {
+			return errors.New("receipt not found")
+		}
+		if receipt.Status != models.PaymentStatusPosted {
+			return fmt.Errorf("receipt is %s and cannot be voided", receipt.Status)
+		}
+
+		allocations, err := tx.ReceiptRepo.GetAllocations(id)
+		if err != nil {
+			return err
+		}
+		sort.Slice(allocations, func(i, j int) bool { return allocations[i].OutboundOrderID < allocations[j].OutboundOrderID })
+		for _, allocation := range allocations {
+			order, err := tx.OutboundRepo.GetByIDForUpdate(allocation.OutboundOrderID)
+			if err != nil {
+				return err
+			}
+			if err := applyOrderReceipt(tx, order, -allocation.Amount); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		return tx.ReceiptRepo.UpdateFields(id, map[string]interface{}{
+			"status":    models.PaymentStatusVoided,
+			"voided_by": userID,
+			"voided_at": now,
+		})
+	})
+}
+
+// GetCustomerBalance 获取客户的应收余额、剩余信用额度和未收清订单
+func (s *CustomerReceiptService) GetCustomerBalance(customerID uint) (*models.CustomerBalance, error) {
+	customer, err := s.repos.CustomerRepo.GetByID(customerID)
+	if err != nil {
+		return nil, errors.New("customer not found")
+	}
+
+	receivable, received, err := s.repos.OutboundRepo.GetCustomerReceivableTotals(customerID)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := s.repos.OutboundRepo.GetUnpaidByCustomer(customerID)
+	if err != nil {
+		return nil, err
+	}
+	lastReceiptAt, err := s.repo.GetLastReceiptAt(customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := &models.CustomerBalance{
+		Customer:        *customer,
+		TotalReceivable: roundAmount(receivable),
+		TotalReceived:   roundAmount(received),
+		Outstanding:     roundAmount(receivable - received),
+		CreditLimit:     customer.CreditLimit,
+		UnpaidOrders:    orders,
+		LastReceiptAt:   lastReceiptAt,
+	}
+	if customer.CreditLimit > 0 {
+		available := roundAmount(customer.CreditLimit - balance.Outstanding)
+		balance.AvailableCredit = &available
+	}
+	return balance, nil
+}
+
+// GetAging 应收账款账龄报表，按客户汇总截至 asOf 当天的未收金额
+// 账龄按订单创建日期计算，分为 0-30、31-60、61-90 和 90 天以上
+func (s *CustomerReceiptService) GetAging(req *models.GetAgingRequest) (*models.ReceivablesAgingReport, error) {
+	day, err := parseAsOfDay(req.AsOf)
+	if err != nil {
+		return nil, err
+	}
+
+	balances, err := s.repo.GetOpenReceivables(day.Format(reportDateLayout))
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ReceivablesAgingReport{
+		AsOf:      day.Format(reportDateLayout),
+		Customers: []models.CustomerAging{},
+	}
+	// 关联了客户的订单按客户汇总，其余按客户名称汇总
+	index := make(map[string]int)
+	for _, balance := range balances {
+		key := "name:" + balance.PartyName
+		if balance.PartyID != nil {
+			key = fmt.Sprintf("id:%d", *balance.PartyID)
+		}
+		i, ok := index[key]
+		if !ok {
+			report.Customers = append(report.Customers, models.CustomerAging{
+				CustomerID:   balance.PartyID,
+				CustomerName: balance.PartyName,
+			})
+			i = len(report.Customers) - 1
+			index[key] = i
+		}
+
+		days := agingDays(day, balance.CreatedAt)
+		report.Customers[i].OrderCount++
+		addAging(&report.Customers[i].AgingBuckets, days, balance.Outstanding)
+		addAging(&report.Total, days, balance.Outstanding)
+	}
+
+	sort.SliceStable(report.Customers, func(i, j int) bool {
+		return report.Customers[i].Outstanding > report.Customers[j].Outstanding
+	})
+	return report, nil
+}
+
+// checkCreditLimit 校验客户未收金额加新订单金额是否超过信用额度，信用额度为 0 时不限
+// 超过额度时返回 ErrCreditLimitExceeded
+func checkCreditLimit(tx *repository.Repositories, customer *models.Customer, amount float64) error {
+	if customer.CreditLimit <= 0 {
+		return nil
+	}
+	receivable, received, err := tx.OutboundRepo.GetCustomerReceivableTotals(customer.ID)
+	if err != nil {
+		return err
+	}
+	outstanding := roundAmount(receivable - received)
+	if outstanding+roundAmount(amount) > customer.CreditLimit+amountTolerance {
+		return fmt.Errorf("%w: outstanding %.2f plus order %.2f exceeds credit limit %.2f of customer %s",
+			ErrCreditLimitExceeded, outstanding, amount, customer.CreditLimit, customer.Name)
+	}
+	return nil
+}
+
+// applyOrderReceipt 把收款 (负数为冲回) 记入出库订单的已收金额并更新收款状态
+func applyOrderReceipt(tx *repository.Repositories, order *models.OutboundOrder, amount float64) error {
+	received := math.Max(roundAmount(order.ReceivedAmount+amount), 0)
+	order.ReceivedAmount = received
+	order.PaymentStatus = orderPaymentStatus(order.TotalAmount, received)
+	return tx.OutboundRepo.UpdateFields(order.ID, map[string]interface{}{
+		"received_amount": order.ReceivedAmount,
+		"payment_status":  order.PaymentStatus,
+	})
+}
+
+// sameCustomer 两张出库订单是否属于同一客户：关联了客户时比较客户，否则比较客户名称
+func sameCustomer(a, b *models.OutboundOrder) bool {
+	if a.CustomerID != nil || b.CustomerID != nil {
+		return a.CustomerID != nil && b.CustomerID != nil && *a.CustomerID == *b.CustomerID
+	}
+	return a.CustomerName == b.CustomerName
+}
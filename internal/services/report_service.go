@@ -3,9 +3,16 @@ package services
 import (
 	"battery-erp-backend/internal/models"
 	"battery-erp-backend/internal/repository"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
 	"time"
 )
 
+// reportDateLayout 报表日期参数格式
+const reportDateLayout = "2006-01-02"
+
 // ReportService 报表服务 (不再使用接口)
 type ReportService struct {
 	repos *repository.Repositories
@@ -18,19 +25,34 @@ func NewReportService(repos *repository.Repositories) *ReportService {
 	}
 }
 
-func (s *ReportService) GetSummary(startDate, endDate string) (*models.ReportSummary, error) {
+// GetSummary 获取库存总览和日期范围内的出入库统计，warehouseID 为 0 时为全部仓库合计
+func (s *ReportService) GetSummary(startDate, endDate string, warehouseID uint) (*models.ReportSummary, error) {
 	// 获取库存总览
-	inventories, err := s.repos.InventoryRepo.GetAll()
+	inventories, err := s.repos.InventoryRepo.GetAll(warehouseID)
 	if err != nil {
 		return nil, err
 	}
 
 	var totalWeight float64
 	var inventoryDetails []models.InventoryDetail
+	detailIndex := make(map[uint]int)
 
+	// 同一分类在多个仓库的库存合并为一条
 	for _, inv := range inventories {
 		totalWeight += inv.CurrentWeightKg
 
+		if i, ok := detailIndex[inv.CategoryID]; ok {
+			detail := &inventoryDetails[i]
+			detail.CurrentWeight += inv.CurrentWeightKg
+			if inv.LastInboundAt != nil && (detail.LastInboundAt == nil || inv.LastInboundAt.After(*detail.LastInboundAt)) {
+				detail.LastInboundAt = inv.LastInboundAt
+			}
+			if inv.LastOutboundAt != nil && (detail.LastOutboundAt == nil || inv.LastOutboundAt.After(*detail.LastOutboundAt)) {
+				detail.LastOutboundAt = inv.LastOutboundAt
+			}
+			continue
+		}
+
 		// 手动获取分类信息
 		category, err := s.repos.CategoryRepo.GetByID(inv.CategoryID)
 		categoryName := "Unknown"
@@ -46,26 +68,313 @@ func (s *ReportService) GetSummary(startDate, endDate string) (*models.ReportSum
 			LastOutboundAt: inv.LastOutboundAt,
 		}
 		inventoryDetails = append(inventoryDetails, detail)
+		detailIndex[inv.CategoryID] = len(inventoryDetails) - 1
 	}
 
 	// 构建报告摘要
 	summary := &models.ReportSummary{
 		TotalInventoryWeight: totalWeight,
-		InventoryCount:       len(inventories),
+		InventoryCount:       len(inventoryDetails),
 		InventoryDetails:     inventoryDetails,
 		ReportGeneratedAt:    time.Now(),
 	}
 
-	// 如果提供了日期范围，添加到报告中
-	if startDate != "" && endDate != "" {
+	// 如果提供了日期范围，添加入库/出库统计
+	if startDate != "" || endDate != "" {
+		start, end, err := parseDateRange(startDate, endDate)
+		if err != nil {
+			return nil, err
+		}
+		reportRepo := s.repos.ReportRepo.WithWarehouse(warehouseID)
 		summary.DateRange = &models.DateRange{
-			StartDate: startDate,
-			EndDate:   endDate,
+			StartDate: start.Format(reportDateLayout),
+			EndDate:   end.AddDate(0, 0, -1).Format(reportDateLayout),
 		}
 
-		// TODO: 可以在这里添加按日期范围的统计逻辑
-		// 比如指定日期范围内的入库/出库统计
+		if summary.InboundStats, err = reportRepo.GetInboundStats(start, end); err != nil {
+			return nil, err
+		}
+		if summary.OutboundStats, err = reportRepo.GetOutboundStats(start, end); err != nil {
+			return nil, err
+		}
+		if summary.InboundByCategory, err = reportRepo.GetInboundCategoryStats(start, end); err != nil {
+			return nil, err
+		}
+		if summary.OutboundByCategory, err = reportRepo.GetOutboundCategoryStats(start, end); err != nil {
+			return nil, err
+		}
 	}
 
 	return summary, nil
 }
+
+// parseDateRange 解析报表日期范围 (YYYY-MM-DD)，返回左闭右开区间 [start, end)
+// 结束日期包含当天；未指定开始日期时从结束日期所在月初开始，未指定结束日期时截至今天
+func parseDateRange(startDate, endDate string) (time.Time, time.Time, error) {
+	now := time.Now()
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if endDate != "" {
+		parsed, err := time.ParseInLocation(reportDateLayout, endDate, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid end_date, expected YYYY-MM-DD")
+		}
+		end = parsed
+	}
+
+	start := time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, time.Local)
+	if startDate != "" {
+		parsed, err := time.ParseInLocation(reportDateLayout, startDate, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid start_date, expected YYYY-MM-DD")
+		}
+		start = parsed
+	}
+
+	end = end.AddDate(0, 0, 1)
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, errors.New("start_date must not be after end_date")
+	}
+	return start, end, nil
+}
+
+// maxTrendPeriods 趋势报表最多返回的时间桶数量
+const maxTrendPeriods = 366
+
+// GetTrend 按日/ISO周/月汇总各分类的入库净重、出库重量、采购金额和销售金额
+// 未指定开始日期时默认取最近30天/12周/12个月
+func (s *ReportService) GetTrend(granularity, startDate, endDate string, categoryID, warehouseID uint) (*models.TrendReport, error) {
+	if granularity == "" {
+		granularity = "day"
+	}
+	if granularity != "day" && granularity != "week" && granularity != "month" {
+		return nil, errors.New("granularity must be one of day, week, month")
+	}
+
+	if startDate == "" {
+		_, end, err := parseDateRange("", endDate)
+		if err != nil {
+			return nil, err
+		}
+		last := end.AddDate(0, 0, -1)
+		switch granularity {
+		case "day":
+			startDate = last.AddDate(0, 0, -29).Format(reportDateLayout)
+		case "week":
+			startDate = last.AddDate(0, 0, -7*11).Format(reportDateLayout)
+		case "month":
+			startDate = time.Date(last.Year(), last.Month()-11, 1, 0, 0, 0, 0, time.Local).Format(reportDateLayout)
+		}
+	}
+
+	start, end, err := parseDateRange(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	periods := trendPeriods(granularity, start, end)
+	if len(periods) > maxTrendPeriods {
+		return nil, errors.New("date range too large for the requested granularity")
+	}
+
+	reportRepo := s.repos.ReportRepo.WithWarehouse(warehouseID)
+	inboundRows, err := reportRepo.GetInboundTrend(granularity, start, end, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	outboundRows, err := reportRepo.GetOutboundTrend(granularity, start, end, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 按分类组装序列，各点与 periods 对齐，无数据的时间桶补零
+	periodIndex := make(map[string]int, len(periods))
+	for i, period := range periods {
+		periodIndex[period] = i
+	}
+
+	var series []models.TrendSeries
+	seriesIndex := make(map[uint]int)
+	pointOf := func(row models.TrendRow) *models.TrendPoint {
+		idx, ok := seriesIndex[row.CategoryID]
+		if !ok {
+			points := make([]models.TrendPoint, len(periods))
+			for i, period := range periods {
+				points[i].Period = period
+			}
+			series = append(series, models.TrendSeries{
+				CategoryID:   row.CategoryID,
+				CategoryName: row.CategoryName,
+				Points:       points,
+			})
+			idx = len(series) - 1
+			seriesIndex[row.CategoryID] = idx
+		}
+		pi, ok := periodIndex[row.Period]
+		if !ok {
+			return nil
+		}
+		return &series[idx].Points[pi]
+	}
+
+	for _, row := range inboundRows {
+		if point := pointOf(row); point != nil {
+			point.InboundWeight += row.Weight
+			point.PurchaseAmount += row.Amount
+		}
+	}
+	for _, row := range outboundRows {
+		if point := pointOf(row); point != nil {
+			point.OutboundWeight += row.Weight
+			point.SalesAmount += row.Amount
+		}
+	}
+
+	sort.Slice(series, func(i, j int) bool { return series[i].CategoryID < series[j].CategoryID })
+
+	return &models.TrendReport{
+		Granularity: granularity,
+		DateRange: models.DateRange{
+			StartDate: start.Format(reportDateLayout),
+			EndDate:   end.AddDate(0, 0, -1).Format(reportDateLayout),
+		},
+		Periods: periods,
+		Series:  series,
+	}, nil
+}
+
+// trendPeriods 生成 [start, end) 内的全部时间桶标签，格式与 SQL DATE_FORMAT 结果一致
+func trendPeriods(granularity string, start, end time.Time) []string {
+	var periods []string
+	seen := make(map[string]bool)
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		var period string
+		switch granularity {
+		case "week":
+			year, week := day.ISOWeek()
+			period = fmt.Sprintf("%d-W%02d", year, week)
+		case "month":
+			period = day.Format("2006-01")
+		default:
+			period = day.Format(reportDateLayout)
+		}
+		if !seen[period] {
+			seen[period] = true
+			periods = append(periods, period)
+		}
+	}
+	return periods
+}
+
+// GetMargin 计算期间内各分类的销售收入、销售成本、毛利和毛利率
+// 销售成本取出库时库存流水记录的发货成本，没有成本记录的出库重量按截至期末全公司已记账入库的加权平均采购单价计算
+func (s *ReportService) GetMargin(startDate, endDate string, categoryID, warehouseID uint) (*models.MarginReport, error) {
+	start, end, err := parseDateRange(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	reportRepo := s.repos.ReportRepo.WithWarehouse(warehouseID)
+	sales, err := reportRepo.GetOutboundCategoryStats(start, end)
+	if err != nil {
+		return nil, err
+	}
+	issues, err := reportRepo.GetOutboundIssueCosts(start, end)
+	if err != nil {
+		return nil, err
+	}
+	// 仓库的库存可能全部来自调拨，没有成本记录的出库按全公司加权平均采购成本
+	receipts, err := s.repos.ReportRepo.GetInboundCategoryStats(time.Time{}, end)
+	if err != nil {
+		return nil, err
+	}
+
+	issueCosts := make(map[uint]repository.CategoryIssueCost, len(issues))
+	for _, issue := range issues {
+		issueCosts[issue.CategoryID] = issue
+	}
+	avgCosts := make(map[uint]float64, len(receipts))
+	for _, receipt := range receipts {
+		avgCosts[receipt.CategoryID] = receipt.AvgUnitPrice
+	}
+
+	report := &models.MarginReport{
+		DateRange: models.DateRange{
+			StartDate: start.Format(reportDateLayout),
+			EndDate:   end.AddDate(0, 0, -1).Format(reportDateLayout),
+		},
+		Categories: []models.CategoryMargin{},
+	}
+
+	for _, sale := range sales {
+		if categoryID != 0 && sale.CategoryID != categoryID {
+			continue
+		}
+
+		margin := models.CategoryMargin{
+			CategoryID:   sale.CategoryID,
+			CategoryName: sale.CategoryName,
+			SalesWeight:  sale.TotalWeight,
+			Revenue:      sale.TotalAmount,
+		}
+		issue := issueCosts[sale.CategoryID]
+		uncosted := math.Max(margin.SalesWeight-issue.Weight, 0)
+		margin.COGS = issue.CostAmount + uncosted*avgCosts[sale.CategoryID]
+		if margin.SalesWeight > 0 {
+			margin.AvgUnitCost = margin.COGS / margin.SalesWeight
+		}
+		margin.GrossProfit = margin.Revenue - margin.COGS
+		margin.MarginPct = marginPct(margin.GrossProfit, margin.Revenue)
+		report.Categories = append(report.Categories, margin)
+
+		report.Total.SalesWeight += margin.SalesWeight
+		report.Total.Revenue += margin.Revenue
+		report.Total.COGS += margin.COGS
+		report.Total.GrossProfit += margin.GrossProfit
+	}
+	report.Total.MarginPct = marginPct(report.Total.GrossProfit, report.Total.Revenue)
+
+	return report, nil
+}
+
+// marginPct 毛利率 (%)，收入为零时返回 0
+func marginPct(profit, revenue float64) float64 {
+	if revenue == 0 {
+		return 0
+	}
+	return profit / revenue * 100
+}
+
+// GetInventoryValuation 计算截至 asOf 当天结束时各分类的库存重量、平均成本和库存金额，asOf 默认为今天
+// warehouseID 为 0 时为全部仓库合计
+func (s *ReportService) GetInventoryValuation(asOf string, warehouseID uint) (*models.InventoryValuationReport, error) {
+	day := time.Now()
+	if asOf != "" {
+		var err error
+		day, err = time.ParseInLocation(reportDateLayout, asOf, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid as_of: %s", asOf)
+		}
+	}
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.Local)
+
+	valuations, err := s.repos.ReportRepo.WithWarehouse(warehouseID).GetInventoryValuation(day.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.InventoryValuationReport{
+		WarehouseID: warehouseID,
+		AsOf:        day.Format(reportDateLayout),
+		Categories:  []models.InventoryValuation{},
+	}
+	for _, valuation := range valuations {
+		if valuation.WeightKg >= weightTolerance {
+			valuation.AvgUnitCost = valuation.TotalValue / valuation.WeightKg
+		}
+		report.Categories = append(report.Categories, valuation)
+		report.TotalWeight += valuation.WeightKg
+		report.TotalValue += valuation.TotalValue
+	}
+
+	return report, nil
+}
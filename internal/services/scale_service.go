@@ -0,0 +1,156 @@
+package services
+
+import (
+	"battery-erp-backend/config"
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/repository"
+	"battery-erp-backend/internal/scale"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// ScaleService 地磅服务
+// 读取地磅当前读数，并把稳定读数写入草稿入库订单项的毛重或皮重
+type ScaleService struct {
+	repos   *repository.Repositories
+	scale   *scale.Scale // 未启用或配置错误时为 nil
+	initErr error
+}
+
+// NewScaleService 创建地磅服务实例，配置错误时记录日志，读数接口返回该错误
+func NewScaleService(repos *repository.Repositories, cfg config.ScaleConfig) *ScaleService {
+	s := &ScaleService{repos: repos}
+	if !cfg.Enabled {
+		s.initErr = errors.New("scale is not enabled")
+		return s
+	}
+
+	sc, err := scale.New(cfg)
+	if err != nil {
+		log.Printf("scale: %v", err)
+		s.initErr = fmt.Errorf("scale is misconfigured: %w", err)
+		return s
+	}
+	s.scale = sc
+	return s
+}
+
+// Start 开始在后台读取地磅数据，未启用时不做任何事
+func (s *ScaleService) Start() {
+	if s.scale != nil {
+		s.scale.Start()
+	}
+}
+
+// Close 停止读取地磅数据
+func (s *ScaleService) Close() error {
+	if s.scale != nil {
+		return s.scale.Close()
+	}
+	return nil
+}
+
+// Current 获取地磅当前读数
+func (s *ScaleService) Current() (*models.ScaleReading, error) {
+	if s.scale == nil {
+		return nil, s.initErr
+	}
+	reading, err := s.scale.Current()
+	if err != nil {
+		return nil, err
+	}
+	return &models.ScaleReading{
+		Weight:     reading.Weight,
+		Stable:     reading.Stable,
+		Motion:     reading.Motion,
+		ReceivedAt: reading.ReceivedAt,
+	}, nil
+}
+
+// stableReading 获取地磅当前稳定读数，读数未稳定或为负时返回错误
+func (s *ScaleService) stableReading() (*models.ScaleReading, error) {
+	reading, err := s.Current()
+	if err != nil {
+		return nil, err
+	}
+	if !reading.Stable {
+		return nil, errors.New("scale reading is not stable")
+	}
+	if reading.Weight < 0 {
+		return nil, errors.New("scale reading is negative, zero the scale first")
+	}
+	return reading, nil
+}
+
+// Capture 把地磅当前稳定读数写入入库订单项的毛重或皮重，并标记为地磅采集
+// 只能采集草稿订单，重新计算净重、结算重量、小计和订单总金额
+func (s *ScaleService) Capture(req *models.ScaleCaptureRequest, userID uint) (*models.ScaleCaptureResponse, error) {
+	reading, err := s.stableReading()
+	if err != nil {
+		return nil, err
+	}
+	weight := roundWeight(reading.Weight)
+
+	var captured models.InboundOrderItem
+	err = s.repos.Transaction(func(tx *repository.Repositories) error {
+		item, err := tx.InboundRepo.GetItemByID(req.ItemID)
+		if err != nil {
+			return errors.New("order item not found")
+		}
+		order, err := tx.InboundRepo.GetByIDForUpdate(item.OrderID)
+		if err != nil || order.IsDeleted == 1 {
+			return errors.New("order not found")
+		}
+		if order.Status != models.OrderStatusDraft {
+			return errors.New("only draft orders can capture weights from the scale")
+		}
+
+		switch req.Field {
+		case models.ScaleFieldGross:
+			if weight <= 0 {
+				return errors.New("gross weight from scale must be greater than 0")
+			}
+			item.GrossWeight = weight
+			item.GrossFromScale = true
+		case models.ScaleFieldTare:
+			item.TareWeight = weight
+			item.TareFromScale = true
+		}
+		if item.TareWeight >= item.GrossWeight {
+			return errors.New("tare_weight must be less than gross_weight")
+		}
+		if err := calcInboundItem(item); err != nil {
+			return err
+		}
+		if err := tx.InboundRepo.UpdateItem(item); err != nil {
+			return err
+		}
+
+		items, err := tx.InboundRepo.GetRawItemsByOrderID(order.ID)
+		if err != nil {
+			return err
+		}
+		var totalAmount float64
+		for _, it := range items {
+			totalAmount += it.SubTotal
+		}
+		if err := tx.InboundRepo.UpdateTotalAmount(order.ID, totalAmount); err != nil {
+			return err
+		}
+
+		captured = *item
+		return tx.ScaleRepo.Create(&models.ScaleCapture{
+			OrderID:    order.ID,
+			ItemID:     item.ID,
+			Field:      req.Field,
+			Weight:     weight,
+			CapturedBy: userID,
+			CapturedAt: reading.ReceivedAt,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.ScaleCaptureResponse{Reading: *reading, Item: captured}, nil
+}
@@ -3,16 +3,22 @@ package services
 import (
 	"battery-erp-backend/internal/models"
 	"battery-erp-backend/internal/repository"
+	"errors"
+	"strings"
 )
 
 // SellerService 卖家服务
 type SellerService struct {
-	repo *repository.SellerRepository
+	repos *repository.Repositories
+	repo  *repository.SellerRepository
 }
 
 // NewSellerService 创建卖家服务实例
-func NewSellerService(repo *repository.SellerRepository) *SellerService {
-	return &SellerService{repo: repo}
+func NewSellerService(repos *repository.Repositories) *SellerService {
+	return &SellerService{
+		repos: repos,
+		repo:  repos.SellerRepo,
+	}
 }
 
 // Create 创建卖家
@@ -25,8 +31,15 @@ func (s *SellerService) Update(id uint, updates map[string]interface{}) error {
 	return s.repo.Update(id, updates)
 }
 
-// Delete 删除卖家
+// Delete 删除卖家，已被入库订单引用的卖家不能删除
 func (s *SellerService) Delete(id uint) error {
+	count, err := s.repos.InboundRepo.CountBySellerID(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("seller is referenced by inbound orders")
+	}
 	return s.repo.Delete(id)
 }
 
@@ -44,3 +57,74 @@ func (s *SellerService) GetAll() ([]models.Seller, error) {
 func (s *SellerService) GetByName(name string) ([]models.Seller, error) {
 	return s.repo.GetByName(name)
 }
+
+// Search 根据名称或电话搜索卖家
+func (s *SellerService) Search(keyword string) ([]models.Seller, error) {
+	return s.repo.Search(strings.TrimSpace(keyword))
+}
+
+// MatchSuppliers 将未关联卖家的入库订单按供应商名称匹配到同名卖家
+// 名称唯一匹配时关联；没有同名卖家时按需创建；存在多个同名卖家时留待人工处理
+func (s *SellerService) MatchSuppliers(req *models.MatchSuppliersRequest) (*models.MatchSuppliersResponse, error) {
+	names, err := s.repos.InboundRepo.GetUnlinkedSupplierNames()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.MatchSuppliersResponse{
+		Matched:   []models.SupplierMatch{},
+		Unmatched: []string{},
+		Ambiguous: []string{},
+	}
+
+	for _, name := range names {
+		sellerName := strings.TrimSpace(name)
+		if sellerName == "" {
+			resp.Unmatched = append(resp.Unmatched, name)
+			continue
+		}
+
+		err := s.repos.Transaction(func(tx *repository.Repositories) error {
+			sellers, err := tx.SellerRepo.GetByExactName(sellerName)
+			if err != nil {
+				return err
+			}
+
+			match := models.SupplierMatch{SupplierName: name}
+			switch {
+			case len(sellers) == 1:
+				match.SellerID = sellers[0].ID
+			case len(sellers) > 1:
+				resp.Ambiguous = append(resp.Ambiguous, name)
+				return nil
+			case req.CreateMissing:
+				seller := &models.Seller{Name: sellerName}
+				if err := tx.SellerRepo.Create(seller); err != nil {
+					return err
+				}
+				match.SellerID = seller.ID
+				match.Created = true
+			default:
+				resp.Unmatched = append(resp.Unmatched, name)
+				return nil
+			}
+
+			count, err := tx.InboundRepo.LinkSupplierToSeller(name, match.SellerID)
+			if err != nil {
+				return err
+			}
+			// 这些订单的付款单一并关联到卖家
+			if err := tx.PaymentRepo.LinkSupplierToSeller(name, match.SellerID); err != nil {
+				return err
+			}
+			match.OrderCount = count
+			resp.Matched = append(resp.Matched, match)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
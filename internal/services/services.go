@@ -1,6 +1,7 @@
 package services
 
 import (
+	"battery-erp-backend/config"
 	"battery-erp-backend/internal/repository"
 
 	"gorm.io/gorm"
@@ -8,28 +9,60 @@ import (
 
 // Services holds all service instances (no interfaces)
 type Services struct {
-	UserService      *UserService
-	CategoryService  *CategoryService
-	InboundService   *InboundService
-	OutboundService  *OutboundService
-	InventoryService *InventoryService
-	SellerService    *SellerService
-	ReportService    *ReportService
-	Auth             *AuthService
-	DB               *gorm.DB
+	UserService        *UserService
+	CategoryService    *CategoryService
+	InboundService     *InboundService
+	OutboundService    *OutboundService
+	InventoryService   *InventoryService
+	SellerService      *SellerService
+	CustomerService    *CustomerService
+	ReportService      *ReportService
+	StocktakeService   *StocktakeService
+	AdjustmentService  *AdjustmentService
+	WarehouseService   *WarehouseService
+	TransferService    *TransferService
+	LotService         *LotService
+	TraceService       *TraceService
+	ManifestService    *ManifestService
+	PriceService       *PriceService
+	ScaleService       *ScaleService
+	WeighTicketService *WeighTicketService
+	VehicleService     *VehicleService
+	PaymentService     *SupplierPaymentService
+	ReceiptService     *CustomerReceiptService
+	Auth               *AuthService
+	DB                 *gorm.DB
 }
 
 // NewServices creates a new services instance
-func NewServices(repos *repository.Repositories) *Services {
+func NewServices(repos *repository.Repositories, cfg *config.Config) *Services {
+	inboundService := NewInboundService(repos, cfg.Pricing.PriceDeviationPercent)
+	outboundService := NewOutboundService(repos, cfg.Pricing.PriceDeviationPercent)
+	scaleService := NewScaleService(repos, cfg.Scale)
+
 	return &Services{
-		UserService:      NewUserService(repos.UserRepo),
-		CategoryService:  NewCategoryService(repos.CategoryRepo, repos.InventoryRepo),
-		InboundService:   NewInboundService(repos.InboundRepo, repos.InventoryRepo),
-		OutboundService:  NewOutboundService(repos.OutboundRepo, repos.InventoryRepo),
-		InventoryService: NewInventoryService(repos.InventoryRepo, repos.CategoryRepo),
-		SellerService:    NewSellerService(repos.SellerRepo),
-		ReportService:    NewReportService(repos),
-		Auth:             NewAuthService(repos.UserRepo),
-		DB:               repos.DB,
+		UserService:        NewUserService(repos.UserRepo),
+		CategoryService:    NewCategoryService(repos),
+		InboundService:     inboundService,
+		OutboundService:    outboundService,
+		InventoryService:   NewInventoryService(repos.InventoryRepo, repos.CategoryRepo, repos.WarehouseRepo),
+		SellerService:      NewSellerService(repos),
+		CustomerService:    NewCustomerService(repos),
+		ReportService:      NewReportService(repos),
+		StocktakeService:   NewStocktakeService(repos),
+		AdjustmentService:  NewAdjustmentService(repos, cfg.Inventory.AdjustmentApprovalThresholdKg),
+		WarehouseService:   NewWarehouseService(repos),
+		TransferService:    NewTransferService(repos),
+		LotService:         NewLotService(repos),
+		TraceService:       NewTraceService(repos),
+		ManifestService:    NewManifestService(repos, cfg.Company),
+		PriceService:       NewPriceService(repos),
+		ScaleService:       scaleService,
+		VehicleService:     NewVehicleService(repos),
+		PaymentService:     NewSupplierPaymentService(repos),
+		ReceiptService:     NewCustomerReceiptService(repos),
+		WeighTicketService: NewWeighTicketService(repos, scaleService, inboundService, outboundService, cfg.Scale.TareDeviationPercent),
+		Auth:               NewAuthService(repos.UserRepo),
+		DB:                 repos.DB,
 	}
 }
@@ -0,0 +1,302 @@
+package services
+
+import (
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/repository"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StocktakeService 盘点服务
+// 盘点单开单时快照账面重量，盘点期间盘点分类禁止出库，审核后按差异写入盘盈盘亏流水
+type StocktakeService struct {
+	repos *repository.Repositories
+	repo  *repository.StocktakeRepository
+}
+
+// NewStocktakeService 创建盘点服务实例
+func NewStocktakeService(repos *repository.Repositories) *StocktakeService {
+	return &StocktakeService{
+		repos: repos,
+		repo:  repos.StocktakeRepo,
+	}
+}
+
+// Create 开启盘点，未指定分类时盘点该仓库全部已有库存的分类
+func (s *StocktakeService) Create(req *models.CreateStocktakeRequest, userID uint) (*models.Stocktake, error) {
+	if _, err := s.repos.WarehouseRepo.GetByID(req.WarehouseID); err != nil {
+		return nil, errors.New("warehouse not found")
+	}
+
+	categoryIDs := req.CategoryIDs
+	if len(categoryIDs) == 0 {
+		inventories, err := s.repos.InventoryRepo.GetAll(req.WarehouseID)
+		if err != nil {
+			return nil, err
+		}
+		for _, inv := range inventories {
+			categoryIDs = append(categoryIDs, inv.CategoryID)
+		}
+	}
+	if len(categoryIDs) == 0 {
+		return nil, errors.New("no categories to count")
+	}
+	sort.Slice(categoryIDs, func(i, j int) bool { return categoryIDs[i] < categoryIDs[j] })
+
+	stocktakeNo, err := s.repo.GenerateStocktakeNo()
+	if err != nil {
+		return nil, err
+	}
+
+	stocktake := &models.Stocktake{
+		StocktakeNo: stocktakeNo,
+		WarehouseID: req.WarehouseID,
+		Status:      models.StocktakeStatusOpen,
+		Notes:       req.Notes,
+		CreatedBy:   userID,
+	}
+
+	err = s.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := checkStocktakeLock(tx, req.WarehouseID, categoryIDs); err != nil {
+			return err
+		}
+		if err := tx.StocktakeRepo.Create(stocktake); err != nil {
+			return err
+		}
+
+		items := make([]models.StocktakeItem, 0, len(categoryIDs))
+		seen := make(map[uint]bool, len(categoryIDs))
+		for _, categoryID := range categoryIDs {
+			if seen[categoryID] {
+				continue
+			}
+			seen[categoryID] = true
+
+			if _, err := tx.CategoryRepo.GetByID(categoryID); err != nil {
+				return fmt.Errorf("category %d not found", categoryID)
+			}
+			systemWeight, err := currentWeight(tx, req.WarehouseID, categoryID)
+			if err != nil {
+				return err
+			}
+			items = append(items, models.StocktakeItem{
+				StocktakeID:  stocktake.ID,
+				CategoryID:   categoryID,
+				SystemWeight: systemWeight,
+			})
+		}
+		if err := tx.StocktakeRepo.CreateItems(items); err != nil {
+			return err
+		}
+		stocktake.Items = items
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.fillCategoryNames(stocktake.Items); err != nil {
+		return nil, err
+	}
+	return stocktake, nil
+}
+
+// GetByID 根据ID获取盘点单 (包含明细)
+func (s *StocktakeService) GetByID(id uint) (*models.Stocktake, error) {
+	stocktake, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.repo.GetItems(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fillCategoryNames(items); err != nil {
+		return nil, err
+	}
+	stocktake.Items = items
+	return stocktake, nil
+}
+
+// GetAll 分页获取盘点单
+func (s *StocktakeService) GetAll(req *models.GetStocktakesRequest) (*models.GetStocktakesResponse, error) {
+	stocktakes, total, err := s.repo.GetAll(req)
+	if err != nil {
+		return nil, err
+	}
+	return &models.GetStocktakesResponse{
+		Stocktakes: stocktakes,
+		Total:      total,
+	}, nil
+}
+
+// RecordCounts 录入实盘重量，同时刷新账面重量并计算差异
+func (s *StocktakeService) RecordCounts(id uint, req *models.RecordStocktakeCountsRequest, userID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		stocktake, err := tx.StocktakeRepo.GetByIDForUpdate(id)
+		if err != nil {
+			return errors.New("stocktake not found")
+		}
+		if stocktake.Status != models.StocktakeStatusOpen {
+			return fmt.Errorf("stocktake is %s, counts can no longer be recorded", stocktake.Status)
+		}
+
+		items, err := tx.StocktakeRepo.GetItems(id)
+		if err != nil {
+			return err
+		}
+		itemsByCategory := make(map[uint]models.StocktakeItem, len(items))
+		for _, item := range items {
+			itemsByCategory[item.CategoryID] = item
+		}
+
+		now := time.Now()
+		for _, count := range req.Items {
+			item, ok := itemsByCategory[count.CategoryID]
+			if !ok {
+				return fmt.Errorf("category %d is not part of this stocktake", count.CategoryID)
+			}
+
+			systemWeight, err := currentWeight(tx, stocktake.WarehouseID, count.CategoryID)
+			if err != nil {
+				return err
+			}
+			reason := count.Reason
+			if reason == "" {
+				reason = models.AdjustmentReasonCorrection
+			}
+
+			// 显式更新盘点明细
+			err = tx.StocktakeRepo.UpdateItemFields(item.ID, map[string]interface{}{
+				"system_weight":  systemWeight,
+				"counted_weight": *count.CountedWeight,
+				"variance":       *count.CountedWeight - systemWeight,
+				"reason":         reason,
+				"notes":          count.Notes,
+				"counted_by":     userID,
+				"counted_at":     now,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return tx.StocktakeRepo.UpdateFields(id, map[string]interface{}{"updated_at": now})
+	})
+}
+
+// Approve 审核盘点单，按差异写入盘盈盘亏库存流水并解除出库锁定
+func (s *StocktakeService) Approve(id uint, userID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		stocktake, err := tx.StocktakeRepo.GetByIDForUpdate(id)
+		if err != nil {
+			return errors.New("stocktake not found")
+		}
+		if stocktake.Status != models.StocktakeStatusOpen {
+			return fmt.Errorf("stocktake is %s, cannot approve", stocktake.Status)
+		}
+
+		items, err := tx.StocktakeRepo.GetItems(id)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if item.CountedWeight == nil {
+				return fmt.Errorf("category %d has not been counted", item.CategoryID)
+			}
+		}
+
+		// 明细已按分类ID升序
+		for _, item := range items {
+			if math.Abs(item.Variance) < weightTolerance {
+				continue
+			}
+			source := models.MovementSource{
+				SourceType: models.MovementSourceStocktake,
+				SourceID:   id,
+				UserID:     userID,
+				Reason:     item.Reason,
+			}
+			if err := tx.InventoryRepo.UpdateWeight(stocktake.WarehouseID, item.CategoryID, math.Abs(item.Variance), item.Variance > 0, source); err != nil {
+				return fmt.Errorf("failed to post variance for category %d: %w", item.CategoryID, err)
+			}
+			// 盘亏按先进先出从批次中扣除
+			if item.Variance < 0 {
+				if err := allocateLots(tx, stocktake.WarehouseID, item.CategoryID, -item.Variance, source, item.ID); err != nil {
+					return err
+				}
+			}
+		}
+
+		now := time.Now()
+		return tx.StocktakeRepo.UpdateFields(id, map[string]interface{}{
+			"status":      models.StocktakeStatusApproved,
+			"approved_by": userID,
+			"approved_at": now,
+			"updated_at":  now,
+		})
+	})
+}
+
+// Cancel 取消盘点单，不调整库存
+func (s *StocktakeService) Cancel(id uint, userID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		stocktake, err := tx.StocktakeRepo.GetByIDForUpdate(id)
+		if err != nil {
+			return errors.New("stocktake not found")
+		}
+		if stocktake.Status != models.StocktakeStatusOpen {
+			return fmt.Errorf("stocktake is %s, cannot cancel", stocktake.Status)
+		}
+		return tx.StocktakeRepo.UpdateFields(id, map[string]interface{}{
+			"status":     models.StocktakeStatusCancelled,
+			"updated_at": time.Now(),
+		})
+	})
+}
+
+// fillCategoryNames 填充盘点明细的分类名称
+func (s *StocktakeService) fillCategoryNames(items []models.StocktakeItem) error {
+	categories, err := s.repos.CategoryRepo.GetAll()
+	if err != nil {
+		return err
+	}
+	names := make(map[uint]string, len(categories))
+	for _, category := range categories {
+		names[category.ID] = category.Name
+	}
+	for i := range items {
+		items[i].CategoryName = names[items[i].CategoryID]
+	}
+	return nil
+}
+
+// checkStocktakeLock 仓库中的分类处于进行中的盘点时返回错误
+func checkStocktakeLock(tx *repository.Repositories, warehouseID uint, categoryIDs []uint) error {
+	open, err := tx.StocktakeRepo.GetOpenByCategoryIDs(warehouseID, categoryIDs)
+	if err != nil {
+		return err
+	}
+	for _, categoryID := range categoryIDs {
+		if stocktakeNo, ok := open[categoryID]; ok {
+			return fmt.Errorf("category %d is locked by open stocktake %s", categoryID, stocktakeNo)
+		}
+	}
+	return nil
+}
+
+// currentWeight 获取分类在仓库的当前库存重量，没有库存记录时为 0
+func currentWeight(tx *repository.Repositories, warehouseID, categoryID uint) (float64, error) {
+	inventory, err := tx.InventoryRepo.GetByCategoryID(warehouseID, categoryID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return inventory.CurrentWeightKg, nil
+}
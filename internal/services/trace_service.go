@@ -0,0 +1,430 @@
+package services
+
+import (
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/repository"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// traceCodePattern 动力电池溯源编码格式: 24 位数字或大写字母
+var traceCodePattern = regexp.MustCompile(fmt.Sprintf(`^[0-9A-Z]{%d}$`, models.TraceCodeLength))
+
+// TraceService 电池溯源编码服务
+// 订单项采集的编码在订单记账时登记: 入库登记为在库，出库校验已在库后标记为已出库，冲回时恢复
+type TraceService struct {
+	repos *repository.Repositories
+	repo  *repository.TraceCodeRepository
+}
+
+// NewTraceService 创建溯源编码服务实例
+func NewTraceService(repos *repository.Repositories) *TraceService {
+	return &TraceService{
+		repos: repos,
+		repo:  repos.TraceCodeRepo,
+	}
+}
+
+// Lookup 查询溯源编码的当前状态和全部历史
+func (s *TraceService) Lookup(code string) (*models.TraceCodeHistory, error) {
+	code = normalizeTraceCode(code)
+	if !traceCodePattern.MatchString(code) {
+		return nil, fmt.Errorf("invalid trace code %s", code)
+	}
+
+	history := &models.TraceCodeHistory{Code: code}
+	current, err := s.repo.GetByCode(code)
+	if err == nil {
+		category, err := s.repos.CategoryRepo.GetByID(current.CategoryID)
+		if err == nil {
+			current.CategoryName = category.Name
+		}
+		history.Current = current
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	history.Events, err = s.repo.GetEvents(code)
+	if err != nil {
+		return nil, err
+	}
+	if history.Current == nil && len(history.Events) == 0 {
+		return nil, errors.New("trace code not found")
+	}
+	return history, nil
+}
+
+// normalizeTraceCode 去除空白并转为大写
+func normalizeTraceCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// saveItemTraceCodes 校验并保存订单项采集的溯源编码，入库编码不能是已入库的编码
+func saveItemTraceCodes(tx *repository.Repositories, sourceType string, orderID, itemID uint, codes []string) error {
+	if len(codes) == 0 {
+		return nil
+	}
+
+	captured, err := tx.TraceCodeRepo.GetItemCodes(sourceType, orderID)
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool, len(captured)+len(codes))
+	for _, c := range captured {
+		seen[c.Code] = true
+	}
+
+	rows := make([]models.OrderItemTraceCode, 0, len(codes))
+	normalized := make([]string, 0, len(codes))
+	for _, code := range codes {
+		code = normalizeTraceCode(code)
+		if !traceCodePattern.MatchString(code) {
+			return fmt.Errorf("invalid trace code %s: must be %d digits or uppercase letters", code, models.TraceCodeLength)
+		}
+		if seen[code] {
+			return fmt.Errorf("trace code %s appears more than once in the order", code)
+		}
+		seen[code] = true
+		normalized = append(normalized, code)
+		rows = append(rows, models.OrderItemTraceCode{
+			SourceType:   sourceType,
+			SourceID:     orderID,
+			SourceItemID: itemID,
+			Code:         code,
+		})
+	}
+
+	if sourceType == models.MovementSourceInbound {
+		registered, err := tx.TraceCodeRepo.GetRegistered(normalized)
+		if err != nil {
+			return err
+		}
+		if len(registered) > 0 {
+			return fmt.Errorf("trace code %s has already been received", registered[0].Code)
+		}
+	}
+	return tx.TraceCodeRepo.CreateItemCodes(rows)
+}
+
+// receiveTraceCodes 登记入库订单项采集的溯源编码，同一编码不能重复入库
+func receiveTraceCodes(tx *repository.Repositories, order *models.InboundOrder, items []models.InboundOrderItem, userID uint) error {
+	codes, err := tx.TraceCodeRepo.GetItemCodes(models.MovementSourceInbound, order.ID)
+	if err != nil {
+		return err
+	}
+	categories := make(map[uint]uint, len(items))
+	for _, item := range items {
+		categories[item.ID] = item.CategoryID
+	}
+
+	now := time.Now()
+	for _, c := range codes {
+		categoryID, ok := categories[c.SourceItemID]
+		if !ok {
+			continue
+		}
+		_, err := tx.TraceCodeRepo.GetByCodeForUpdate(c.Code)
+		if err == nil {
+			return fmt.Errorf("trace code %s has already been received", c.Code)
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		traceCode := &models.TraceCode{
+			Code:           c.Code,
+			CategoryID:     categoryID,
+			WarehouseID:    order.WarehouseID,
+			Status:         models.TraceCodeStatusInStock,
+			InboundOrderID: order.ID,
+			InboundItemID:  c.SourceItemID,
+			ReceivedAt:     now,
+		}
+		if err := tx.TraceCodeRepo.Create(traceCode); err != nil {
+			return err
+		}
+		event := &models.TraceCodeEvent{
+			Code:         c.Code,
+			EventType:    models.TraceEventReceived,
+			CategoryID:   categoryID,
+			WarehouseID:  order.WarehouseID,
+			SourceType:   models.MovementSourceInbound,
+			SourceID:     order.ID,
+			SourceItemID: c.SourceItemID,
+			DocumentNo:   order.OrderNo,
+			Counterparty: order.SupplierName,
+			CreatedBy:    userID,
+		}
+		if err := tx.TraceCodeRepo.CreateEvent(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reverseTraceCodeReceipt 冲回入库订单登记的溯源编码，itemID 非 0 时仅冲回该订单项；编码已出库时拒绝
+func reverseTraceCodeReceipt(tx *repository.Repositories, order *models.InboundOrder, itemID uint, userID uint) error {
+	codes, err := tx.TraceCodeRepo.GetItemCodes(models.MovementSourceInbound, order.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range codes {
+		if itemID != 0 && c.SourceItemID != itemID {
+			continue
+		}
+		traceCode, err := tx.TraceCodeRepo.GetByCodeForUpdate(c.Code)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if traceCode.InboundOrderID != order.ID {
+			continue
+		}
+		if traceCode.Status == models.TraceCodeStatusShipped {
+			return fmt.Errorf("trace code %s has already been shipped", c.Code)
+		}
+		if traceCode.Status == models.TraceCodeStatusInTransit || traceCode.WarehouseID != order.WarehouseID {
+			return fmt.Errorf("trace code %s has been transferred to another warehouse", c.Code)
+		}
+
+		if err := tx.TraceCodeRepo.Delete(traceCode.ID); err != nil {
+			return err
+		}
+		event := &models.TraceCodeEvent{
+			Code:         c.Code,
+			EventType:    models.TraceEventReceiptReversed,
+			CategoryID:   traceCode.CategoryID,
+			WarehouseID:  traceCode.WarehouseID,
+			SourceType:   models.MovementSourceInbound,
+			SourceID:     order.ID,
+			SourceItemID: c.SourceItemID,
+			DocumentNo:   order.OrderNo,
+			Counterparty: order.SupplierName,
+			CreatedBy:    userID,
+		}
+		if err := tx.TraceCodeRepo.CreateEvent(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shipTraceCodes 出库订单项采集的溯源编码须在出库仓在库且分类一致，登记为已出库
+func shipTraceCodes(tx *repository.Repositories, order *models.OutboundOrder, items []models.OutboundOrderItem, userID uint) error {
+	codes, err := tx.TraceCodeRepo.GetItemCodes(models.MovementSourceOutbound, order.ID)
+	if err != nil {
+		return err
+	}
+	categories := make(map[uint]uint, len(items))
+	for _, item := range items {
+		categories[item.ID] = item.CategoryID
+	}
+
+	now := time.Now()
+	for _, c := range codes {
+		categoryID, ok := categories[c.SourceItemID]
+		if !ok {
+			continue
+		}
+		traceCode, err := tx.TraceCodeRepo.GetByCodeForUpdate(c.Code)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("trace code %s has not been received", c.Code)
+		}
+		if err != nil {
+			return err
+		}
+		if err := checkTraceCodeInStock(traceCode, order.WarehouseID, categoryID); err != nil {
+			return err
+		}
+
+		err = tx.TraceCodeRepo.UpdateFields(traceCode.ID, map[string]interface{}{
+			"status":            models.TraceCodeStatusShipped,
+			"outbound_order_id": order.ID,
+			"outbound_item_id":  c.SourceItemID,
+			"shipped_at":        now,
+			"updated_at":        now,
+		})
+		if err != nil {
+			return err
+		}
+		event := &models.TraceCodeEvent{
+			Code:         c.Code,
+			EventType:    models.TraceEventShipped,
+			CategoryID:   categoryID,
+			WarehouseID:  order.WarehouseID,
+			SourceType:   models.MovementSourceOutbound,
+			SourceID:     order.ID,
+			SourceItemID: c.SourceItemID,
+			DocumentNo:   order.OrderNo,
+			Counterparty: order.CustomerName,
+			CreatedBy:    userID,
+		}
+		if err := tx.TraceCodeRepo.CreateEvent(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reverseTraceCodeShipment 退回出库订单时将其出库的溯源编码恢复为在库
+func reverseTraceCodeShipment(tx *repository.Repositories, order *models.OutboundOrder, userID uint) error {
+	codes, err := tx.TraceCodeRepo.GetItemCodes(models.MovementSourceOutbound, order.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range codes {
+		traceCode, err := tx.TraceCodeRepo.GetByCodeForUpdate(c.Code)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if traceCode.Status != models.TraceCodeStatusShipped || traceCode.OutboundOrderID == nil || *traceCode.OutboundOrderID != order.ID {
+			continue
+		}
+
+		err = tx.TraceCodeRepo.UpdateFields(traceCode.ID, map[string]interface{}{
+			"status":            models.TraceCodeStatusInStock,
+			"outbound_order_id": nil,
+			"outbound_item_id":  nil,
+			"shipped_at":        nil,
+			"updated_at":        time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		event := &models.TraceCodeEvent{
+			Code:         c.Code,
+			EventType:    models.TraceEventShipmentReversed,
+			CategoryID:   traceCode.CategoryID,
+			WarehouseID:  order.WarehouseID,
+			SourceType:   models.MovementSourceOutbound,
+			SourceID:     order.ID,
+			SourceItemID: c.SourceItemID,
+			DocumentNo:   order.OrderNo,
+			Counterparty: order.CustomerName,
+			CreatedBy:    userID,
+		}
+		if err := tx.TraceCodeRepo.CreateEvent(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkTraceCodeInStock 校验编码在指定仓库在库且分类一致
+func checkTraceCodeInStock(traceCode *models.TraceCode, warehouseID, categoryID uint) error {
+	switch traceCode.Status {
+	case models.TraceCodeStatusShipped:
+		return fmt.Errorf("trace code %s has already been shipped", traceCode.Code)
+	case models.TraceCodeStatusInTransit:
+		return fmt.Errorf("trace code %s is in transit", traceCode.Code)
+	}
+	if traceCode.WarehouseID != warehouseID {
+		return fmt.Errorf("trace code %s is in warehouse %d", traceCode.Code, traceCode.WarehouseID)
+	}
+	if traceCode.CategoryID != categoryID {
+		return fmt.Errorf("trace code %s was received as category %d", traceCode.Code, traceCode.CategoryID)
+	}
+	return nil
+}
+
+// shipTransferTraceCodes 调拨明细采集的溯源编码须在调出仓在库且分类一致，发货时登记为在途
+func shipTransferTraceCodes(tx *repository.Repositories, transfer *models.TransferOrder, items []models.TransferOrderItem, userID uint) error {
+	codes, err := tx.TraceCodeRepo.GetItemCodes(models.MovementSourceTransferOut, transfer.ID)
+	if err != nil {
+		return err
+	}
+	categories := make(map[uint]uint, len(items))
+	for _, item := range items {
+		categories[item.ID] = item.CategoryID
+	}
+
+	for _, c := range codes {
+		categoryID, ok := categories[c.SourceItemID]
+		if !ok {
+			continue
+		}
+		traceCode, err := tx.TraceCodeRepo.GetByCodeForUpdate(c.Code)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("trace code %s has not been received", c.Code)
+		}
+		if err != nil {
+			return err
+		}
+		if err := checkTraceCodeInStock(traceCode, transfer.FromWarehouseID, categoryID); err != nil {
+			return err
+		}
+
+		err = tx.TraceCodeRepo.UpdateFields(traceCode.ID, map[string]interface{}{
+			"status":     models.TraceCodeStatusInTransit,
+			"updated_at": time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		if err := createTransferTraceEvent(tx, transfer, c, categoryID, transfer.FromWarehouseID, models.TraceEventTransferShipped, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveTransferTraceCodes 把调拨在途的溯源编码登记为在 warehouseID 在库：收货时为调入仓，取消时退回调出仓
+func moveTransferTraceCodes(tx *repository.Repositories, transfer *models.TransferOrder, warehouseID uint, eventType string, userID uint) error {
+	codes, err := tx.TraceCodeRepo.GetItemCodes(models.MovementSourceTransferOut, transfer.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range codes {
+		traceCode, err := tx.TraceCodeRepo.GetByCodeForUpdate(c.Code)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if traceCode.Status != models.TraceCodeStatusInTransit {
+			continue
+		}
+
+		err = tx.TraceCodeRepo.UpdateFields(traceCode.ID, map[string]interface{}{
+			"status":       models.TraceCodeStatusInStock,
+			"warehouse_id": warehouseID,
+			"updated_at":   time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		if err := createTransferTraceEvent(tx, transfer, c, traceCode.CategoryID, warehouseID, eventType, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createTransferTraceEvent 记录调拨单的溯源编码事件
+func createTransferTraceEvent(tx *repository.Repositories, transfer *models.TransferOrder, c models.OrderItemTraceCode, categoryID, warehouseID uint, eventType string, userID uint) error {
+	return tx.TraceCodeRepo.CreateEvent(&models.TraceCodeEvent{
+		Code:         c.Code,
+		EventType:    eventType,
+		CategoryID:   categoryID,
+		WarehouseID:  warehouseID,
+		SourceType:   models.MovementSourceTransferOut,
+		SourceID:     transfer.ID,
+		SourceItemID: c.SourceItemID,
+		DocumentNo:   transfer.TransferNo,
+		CreatedBy:    userID,
+	})
+}
@@ -0,0 +1,312 @@
+package services
+
+import (
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/repository"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TransferService 调拨服务
+// 发货时扣减调出仓库存并记录发货成本，收货时按实收重量和发货成本记入调入仓，差额记为途损
+type TransferService struct {
+	repos *repository.Repositories
+	repo  *repository.TransferRepository
+}
+
+// NewTransferService 创建调拨服务实例
+func NewTransferService(repos *repository.Repositories) *TransferService {
+	return &TransferService{
+		repos: repos,
+		repo:  repos.TransferRepo,
+	}
+}
+
+// Create 创建调拨单，req.Ship 为 true 时在同一事务中发货
+func (s *TransferService) Create(req *models.CreateTransferOrderRequest, userID uint) (*models.TransferOrder, error) {
+	if _, err := s.repos.WarehouseRepo.GetByID(req.FromWarehouseID); err != nil {
+		return nil, errors.New("source warehouse not found")
+	}
+	if _, err := s.repos.WarehouseRepo.GetByID(req.ToWarehouseID); err != nil {
+		return nil, errors.New("destination warehouse not found")
+	}
+
+	seen := make(map[uint]bool, len(req.Items))
+	for _, item := range req.Items {
+		if seen[item.CategoryID] {
+			return nil, fmt.Errorf("category %d appears more than once", item.CategoryID)
+		}
+		seen[item.CategoryID] = true
+		if _, err := s.repos.CategoryRepo.GetByID(item.CategoryID); err != nil {
+			return nil, fmt.Errorf("category %d not found", item.CategoryID)
+		}
+	}
+
+	transferNo, err := s.repo.GenerateTransferNo()
+	if err != nil {
+		return nil, err
+	}
+
+	transfer := &models.TransferOrder{
+		TransferNo:      transferNo,
+		FromWarehouseID: req.FromWarehouseID,
+		ToWarehouseID:   req.ToWarehouseID,
+		CarNumber:       req.CarNumber,
+		DriverName:      req.DriverName,
+		DriverPhone:     req.DriverPhone,
+		Status:          models.TransferStatusDraft,
+		Notes:           req.Notes,
+		CreatedBy:       userID,
+	}
+
+	err = s.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.TransferRepo.Create(transfer); err != nil {
+			return err
+		}
+
+		items := make([]models.TransferOrderItem, 0, len(req.Items))
+		for _, item := range req.Items {
+			items = append(items, models.TransferOrderItem{
+				TransferID: transfer.ID,
+				CategoryID: item.CategoryID,
+				Weight:     item.Weight,
+			})
+		}
+		if err := tx.TransferRepo.CreateItems(items); err != nil {
+			return err
+		}
+		for i := range items {
+			if err := saveItemTraceCodes(tx, models.MovementSourceTransferOut, transfer.ID, items[i].ID, req.Items[i].TraceCodes); err != nil {
+				return err
+			}
+		}
+
+		if req.Ship {
+			return s.ship(tx, transfer, userID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetByID(transfer.ID)
+}
+
+// GetByID 根据ID获取调拨单 (包含明细)
+func (s *TransferService) GetByID(id uint) (*models.TransferOrder, error) {
+	transfer, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.repo.GetItems(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fillCategoryNames(items); err != nil {
+		return nil, err
+	}
+	transfer.Items = items
+	return transfer, nil
+}
+
+// GetAll 分页获取调拨单
+func (s *TransferService) GetAll(req *models.GetTransferOrdersRequest) (*models.GetTransferOrdersResponse, error) {
+	transfers, total, err := s.repo.GetAll(req)
+	if err != nil {
+		return nil, err
+	}
+	return &models.GetTransferOrdersResponse{
+		Transfers: transfers,
+		Total:     total,
+	}, nil
+}
+
+// GetInTransit 获取在途库存汇总，warehouseID 为 0 时统计全部仓库
+func (s *TransferService) GetInTransit(warehouseID uint) ([]models.InTransitStock, error) {
+	return s.repo.GetInTransit(warehouseID)
+}
+
+// Ship 调拨发货，扣减调出仓库存
+func (s *TransferService) Ship(id uint, userID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		transfer, err := tx.TransferRepo.GetByIDForUpdate(id)
+		if err != nil {
+			return errors.New("transfer not found")
+		}
+		if transfer.Status != models.TransferStatusDraft {
+			return fmt.Errorf("transfer is %s, cannot be shipped", transfer.Status)
+		}
+		return s.ship(tx, transfer, userID)
+	})
+}
+
+// Receive 调拨收货，按实收重量记入调入仓，发货与实收的差额记为途损
+func (s *TransferService) Receive(id uint, req *models.ReceiveTransferOrderRequest, userID uint) error {
+	received := make(map[uint]float64, len(req.Items))
+	for _, input := range req.Items {
+		received[input.CategoryID] = *input.ReceivedWeight
+	}
+
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		transfer, err := tx.TransferRepo.GetByIDForUpdate(id)
+		if err != nil {
+			return errors.New("transfer not found")
+		}
+		if transfer.Status != models.TransferStatusInTransit {
+			return fmt.Errorf("transfer is %s, cannot be received", transfer.Status)
+		}
+
+		items, err := tx.TransferRepo.GetItems(id)
+		if err != nil {
+			return err
+		}
+		if len(received) != len(items) {
+			return errors.New("received weight is required for every transferred category")
+		}
+
+		source := models.MovementSource{SourceType: models.MovementSourceTransferIn, SourceID: id, UserID: userID}
+		for _, item := range items {
+			weight, ok := received[item.CategoryID]
+			if !ok {
+				return fmt.Errorf("received weight is missing for category %d", item.CategoryID)
+			}
+			if weight > item.Weight+weightTolerance {
+				return fmt.Errorf("received weight %.3f exceeds shipped weight %.3f for category %d", weight, item.Weight, item.CategoryID)
+			}
+
+			// 按发货成本入账，途损金额由调拨单承担
+			if weight >= weightTolerance {
+				source.UnitCost = item.UnitCost
+				if err := tx.InventoryRepo.UpdateWeight(transfer.ToWarehouseID, item.CategoryID, weight, true, source); err != nil {
+					return err
+				}
+				if err := createTransferLots(tx, transfer, &item, weight); err != nil {
+					return err
+				}
+			}
+
+			loss := item.Weight - weight
+			err := tx.TransferRepo.UpdateItemFields(item.ID, map[string]interface{}{
+				"received_weight":     weight,
+				"transit_loss":        loss,
+				"transit_loss_amount": loss * item.UnitCost,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if err := moveTransferTraceCodes(tx, transfer, transfer.ToWarehouseID, models.TraceEventTransferReceived, userID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		return tx.TransferRepo.UpdateFields(id, map[string]interface{}{
+			"status":      models.TransferStatusReceived,
+			"received_by": userID,
+			"received_at": now,
+			"updated_at":  now,
+		})
+	})
+}
+
+// Cancel 取消调拨单，在途调拨按发货成本退回调出仓
+func (s *TransferService) Cancel(id uint, userID uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		transfer, err := tx.TransferRepo.GetByIDForUpdate(id)
+		if err != nil {
+			return errors.New("transfer not found")
+		}
+
+		switch transfer.Status {
+		case models.TransferStatusDraft:
+		case models.TransferStatusInTransit:
+			items, err := tx.TransferRepo.GetItems(id)
+			if err != nil {
+				return err
+			}
+			if err := releaseLots(tx, models.MovementSourceTransferOut, id); err != nil {
+				return err
+			}
+			source := models.MovementSource{SourceType: models.MovementSourceTransferIn, SourceID: id, UserID: userID}
+			for _, item := range items {
+				source.UnitCost = item.UnitCost
+				if err := tx.InventoryRepo.UpdateWeight(transfer.FromWarehouseID, item.CategoryID, item.Weight, true, source); err != nil {
+					return fmt.Errorf("failed to return stock for transfer %s: %w", transfer.TransferNo, err)
+				}
+			}
+			if err := moveTransferTraceCodes(tx, transfer, transfer.FromWarehouseID, models.TraceEventTransferReversed, userID); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("transfer is %s, cannot be cancelled", transfer.Status)
+		}
+
+		return tx.TransferRepo.UpdateFields(id, map[string]interface{}{
+			"status":     models.TransferStatusCancelled,
+			"updated_at": time.Now(),
+		})
+	})
+}
+
+// ship 扣减调出仓库存并记录各分类的发货成本，盘点中的分类不允许发货
+func (s *TransferService) ship(tx *repository.Repositories, transfer *models.TransferOrder, userID uint) error {
+	items, err := tx.TransferRepo.GetItems(transfer.ID)
+	if err != nil {
+		return err
+	}
+
+	// 明细按分类ID升序，与其他单据保持一致的加锁顺序
+	categoryIDs := make([]uint, 0, len(items))
+	for _, item := range items {
+		categoryIDs = append(categoryIDs, item.CategoryID)
+	}
+	if err := checkStocktakeLock(tx, transfer.FromWarehouseID, categoryIDs); err != nil {
+		return err
+	}
+
+	source := models.MovementSource{SourceType: models.MovementSourceTransferOut, SourceID: transfer.ID, UserID: userID}
+	for _, item := range items {
+		if err := tx.InventoryRepo.UpdateWeight(transfer.FromWarehouseID, item.CategoryID, item.Weight, false, source); err != nil {
+			return err
+		}
+		if err := allocateLots(tx, transfer.FromWarehouseID, item.CategoryID, item.Weight, source, item.ID); err != nil {
+			return err
+		}
+		unitCost, err := tx.InventoryRepo.GetSourceUnitCost(item.CategoryID, models.MovementSourceTransferOut, transfer.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.TransferRepo.UpdateItemFields(item.ID, map[string]interface{}{"unit_cost": unitCost}); err != nil {
+			return err
+		}
+	}
+	if err := shipTransferTraceCodes(tx, transfer, items, userID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	transfer.Status = models.TransferStatusInTransit
+	return tx.TransferRepo.UpdateFields(transfer.ID, map[string]interface{}{
+		"status":     models.TransferStatusInTransit,
+		"shipped_by": userID,
+		"shipped_at": now,
+		"updated_at": now,
+	})
+}
+
+// fillCategoryNames 填充调拨明细的分类名称
+func (s *TransferService) fillCategoryNames(items []models.TransferOrderItem) error {
+	categories, err := s.repos.CategoryRepo.GetAll()
+	if err != nil {
+		return err
+	}
+	names := make(map[uint]string, len(categories))
+	for _, category := range categories {
+		names[category.ID] = category.Name
+	}
+	for i := range items {
+		items[i].CategoryName = names[items[i].CategoryID]
+	}
+	return nil
+}
@@ -0,0 +1,335 @@
+package services
+
+import (
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/repository"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// VehicleService 车辆和司机档案服务
+type VehicleService struct {
+	repos *repository.Repositories
+	repo  *repository.VehicleRepository
+}
+
+// NewVehicleService 创建车辆服务实例
+func NewVehicleService(repos *repository.Repositories) *VehicleService {
+	return &VehicleService{
+		repos: repos,
+		repo:  repos.VehicleRepo,
+	}
+}
+
+// Create 登记车辆，车牌号已停用时重新启用并覆盖档案
+func (s *VehicleService) Create(req *models.CreateVehicleRequest) (*models.Vehicle, error) {
+	plate := normalizePlate(req.PlateNumber)
+	if plate == "" {
+		return nil, errors.New("plate_number is required")
+	}
+	if req.DefaultDriverID != nil {
+		if _, err := s.repo.GetDriverByID(*req.DefaultDriverID); err != nil {
+			return nil, errors.New("default driver not found")
+		}
+	}
+
+	existing, err := s.repo.GetByPlate(plate)
+	if err == nil {
+		if existing.IsActive {
+			return nil, fmt.Errorf("vehicle %s already exists", plate)
+		}
+		err := s.repo.UpdateFields(existing.ID, map[string]interface{}{
+			"vehicle_type":      req.VehicleType,
+			"tare_weight":       roundWeight(req.TareWeight),
+			"carrier":           req.Carrier,
+			"default_driver_id": req.DefaultDriverID,
+			"notes":             req.Notes,
+			"is_active":         true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return s.repo.GetByID(existing.ID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	vehicle := &models.Vehicle{
+		PlateNumber:     plate,
+		VehicleType:     req.VehicleType,
+		TareWeight:      roundWeight(req.TareWeight),
+		Carrier:         req.Carrier,
+		DefaultDriverID: req.DefaultDriverID,
+		Notes:           req.Notes,
+		IsActive:        true,
+	}
+	if err := s.repo.Create(vehicle); err != nil {
+		return nil, err
+	}
+	return vehicle, nil
+}
+
+// GetByID 根据ID获取车辆
+func (s *VehicleService) GetByID(id uint) (*models.Vehicle, error) {
+	vehicle, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("vehicle not found")
+	}
+	return vehicle, nil
+}
+
+// GetAll 获取启用的车辆
+func (s *VehicleService) GetAll(req *models.GetVehiclesRequest) ([]models.Vehicle, error) {
+	return s.repo.GetAll(req)
+}
+
+// Update 更新车辆档案，车牌号不可修改
+func (s *VehicleService) Update(id uint, req *models.UpdateVehicleRequest) (*models.Vehicle, error) {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return nil, errors.New("vehicle not found")
+	}
+
+	// 构建更新字段映射
+	updates := make(map[string]interface{})
+	if req.VehicleType != "" {
+		updates["vehicle_type"] = req.VehicleType
+	}
+	if req.TareWeight != nil {
+		updates["tare_weight"] = roundWeight(*req.TareWeight)
+	}
+	if req.Carrier != "" {
+		updates["carrier"] = req.Carrier
+	}
+	if req.DefaultDriverID != nil {
+		if _, err := s.repo.GetDriverByID(*req.DefaultDriverID); err != nil {
+			return nil, errors.New("default driver not found")
+		}
+		updates["default_driver_id"] = *req.DefaultDriverID
+	}
+	if req.Notes != "" {
+		updates["notes"] = req.Notes
+	}
+	if len(updates) == 0 {
+		return nil, errors.New("no fields to update")
+	}
+
+	if err := s.repo.UpdateFields(id, updates); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(id)
+}
+
+// Delete 停用车辆，历史单据保留车辆ID和车号
+func (s *VehicleService) Delete(id uint) error {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return errors.New("vehicle not found")
+	}
+	return s.repo.Delete(id)
+}
+
+// GetTrips 获取车辆的车次记录：磅单，以及未经磅单直接开具的出库订单
+// 按车辆ID或车牌号匹配，登记车辆前的单据也会列出
+func (s *VehicleService) GetTrips(id uint, req *models.GetVehicleTripsRequest) (*models.VehicleTripHistory, error) {
+	vehicle, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("vehicle not found")
+	}
+
+	tickets, err := s.repo.GetTicketTrips(vehicle.ID, vehicle.PlateNumber, req)
+	if err != nil {
+		return nil, err
+	}
+	trips, err := s.repo.GetOrderTrips(vehicle.ID, vehicle.PlateNumber, req)
+	if err != nil {
+		return nil, err
+	}
+	for _, ticket := range tickets {
+		trips = append(trips, models.VehicleTrip{
+			Source:        models.TripSourceWeighTicket,
+			SourceID:      ticket.ID,
+			DocumentNo:    ticket.TicketNo,
+			Direction:     ticket.Direction,
+			Status:        ticket.Status,
+			DriverName:    ticket.DriverName,
+			TripAt:        ticket.FirstWeighedAt,
+			NetWeight:     ticket.NetWeight,
+			TareWeight:    ticket.TareWeight,
+			TareDeviation: ticket.TareDeviation,
+			TareFlagged:   ticket.TareFlagged,
+			OrderNo:       ticket.OrderNo,
+		})
+	}
+	sort.SliceStable(trips, func(i, j int) bool {
+		return trips[i].TripAt.After(trips[j].TripAt)
+	})
+
+	history := &models.VehicleTripHistory{
+		Vehicle:   *vehicle,
+		Trips:     trips,
+		TripCount: len(trips),
+	}
+	for _, trip := range trips {
+		if trip.Status == models.OrderStatusCancelled || trip.Status == models.WeighTicketStatusCancelled {
+			continue
+		}
+		history.TotalNetWeight += trip.NetWeight
+		if trip.TareFlagged {
+			history.FlaggedCount++
+		}
+	}
+	return history, nil
+}
+
+// CreateDriver 登记司机，同一手机号只能登记一名启用的司机
+func (s *VehicleService) CreateDriver(req *models.CreateDriverRequest) (*models.Driver, error) {
+	phone := strings.TrimSpace(req.Phone)
+	if _, err := s.repo.GetDriverByPhone(phone); err == nil {
+		return nil, fmt.Errorf("driver with phone %s already exists", phone)
+	}
+
+	driver := &models.Driver{
+		Name:      strings.TrimSpace(req.Name),
+		Phone:     phone,
+		LicenseNo: req.LicenseNo,
+		Notes:     req.Notes,
+		IsActive:  true,
+	}
+	if err := s.repo.CreateDriver(driver); err != nil {
+		return nil, err
+	}
+	return driver, nil
+}
+
+// GetDriverByID 根据ID获取司机
+func (s *VehicleService) GetDriverByID(id uint) (*models.Driver, error) {
+	driver, err := s.repo.GetDriverByID(id)
+	if err != nil {
+		return nil, errors.New("driver not found")
+	}
+	return driver, nil
+}
+
+// GetAllDrivers 获取启用的司机
+func (s *VehicleService) GetAllDrivers(req *models.GetDriversRequest) ([]models.Driver, error) {
+	return s.repo.GetAllDrivers(req)
+}
+
+// UpdateDriver 更新司机档案
+func (s *VehicleService) UpdateDriver(id uint, req *models.UpdateDriverRequest) (*models.Driver, error) {
+	if _, err := s.repo.GetDriverByID(id); err != nil {
+		return nil, errors.New("driver not found")
+	}
+
+	// 构建更新字段映射
+	updates := make(map[string]interface{})
+	if req.Name != "" {
+		updates["name"] = strings.TrimSpace(req.Name)
+	}
+	if phone := strings.TrimSpace(req.Phone); phone != "" {
+		if other, err := s.repo.GetDriverByPhone(phone); err == nil && other.ID != id {
+			return nil, fmt.Errorf("driver with phone %s already exists", phone)
+		}
+		updates["phone"] = phone
+	}
+	if req.LicenseNo != "" {
+		updates["license_no"] = req.LicenseNo
+	}
+	if req.Notes != "" {
+		updates["notes"] = req.Notes
+	}
+	if len(updates) == 0 {
+		return nil, errors.New("no fields to update")
+	}
+
+	if err := s.repo.UpdateDriverFields(id, updates); err != nil {
+		return nil, err
+	}
+	return s.repo.GetDriverByID(id)
+}
+
+// DeleteDriver 停用司机
+func (s *VehicleService) DeleteDriver(id uint) error {
+	if _, err := s.repo.GetDriverByID(id); err != nil {
+		return errors.New("driver not found")
+	}
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		return tx.VehicleRepo.DeleteDriver(id)
+	})
+}
+
+// carrierInfo 单据上的车辆和司机信息
+type carrierInfo struct {
+	VehicleID   *uint
+	DriverID    *uint
+	CarNumber   string
+	DriverName  string
+	DriverPhone string
+	Vehicle     *models.Vehicle // 匹配到的车辆档案
+}
+
+// resolveCarrier 按车辆和司机档案补全单据的车号和司机信息
+// 指定档案ID时以档案为准，档案已停用时拒绝；只填写车号或手机号时按车牌号/手机号匹配已登记且启用的档案；
+// 车辆有常用司机 (启用中) 且未填写司机时带出常用司机
+func resolveCarrier(repos *repository.Repositories, vehicleID, driverID *uint, carNumber, driverName, driverPhone string) (*carrierInfo, error) {
+	info := &carrierInfo{
+		CarNumber:   strings.TrimSpace(carNumber),
+		DriverName:  strings.TrimSpace(driverName),
+		DriverPhone: strings.TrimSpace(driverPhone),
+	}
+
+	if vehicleID != nil {
+		vehicle, err := repos.VehicleRepo.GetByID(*vehicleID)
+		if err != nil {
+			return nil, errors.New("vehicle not found")
+		}
+		if !vehicle.IsActive {
+			return nil, fmt.Errorf("vehicle %s is inactive", vehicle.PlateNumber)
+		}
+		info.Vehicle = vehicle
+	} else if info.CarNumber != "" {
+		if vehicle, err := repos.VehicleRepo.GetByPlate(normalizePlate(info.CarNumber)); err == nil && vehicle.IsActive {
+			info.Vehicle = vehicle
+		}
+	}
+	if info.Vehicle != nil {
+		info.VehicleID = &info.Vehicle.ID
+		info.CarNumber = info.Vehicle.PlateNumber
+	}
+
+	var driver *models.Driver
+	if driverID != nil {
+		d, err := repos.VehicleRepo.GetDriverByID(*driverID)
+		if err != nil {
+			return nil, errors.New("driver not found")
+		}
+		driver = d
+	} else if info.Vehicle != nil && info.Vehicle.DefaultDriverID != nil && info.DriverName == "" && info.DriverPhone == "" {
+		// 常用司机已停用时不带出
+		if d, err := repos.VehicleRepo.GetDriverByID(*info.Vehicle.DefaultDriverID); err == nil && d.IsActive {
+			driver = d
+		}
+	} else if info.DriverPhone != "" {
+		if d, err := repos.VehicleRepo.GetDriverByPhone(info.DriverPhone); err == nil && d.IsActive && (info.DriverName == "" || d.Name == info.DriverName) {
+			driver = d
+		}
+	}
+	if driver != nil && !driver.IsActive {
+		return nil, fmt.Errorf("driver %s is inactive", driver.Name)
+	}
+	if driver != nil {
+		info.DriverID = &driver.ID
+		info.DriverName = driver.Name
+		info.DriverPhone = driver.Phone
+	}
+	return info, nil
+}
+
+// normalizePlate 车牌号去掉空格并转为大写
+func normalizePlate(plate string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(plate), ""))
+}
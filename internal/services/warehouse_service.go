@@ -0,0 +1,123 @@
+package services
+
+import (
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/repository"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// WarehouseService 仓库服务
+type WarehouseService struct {
+	repos *repository.Repositories
+	repo  *repository.WarehouseRepository
+}
+
+// NewWarehouseService 创建仓库服务实例
+func NewWarehouseService(repos *repository.Repositories) *WarehouseService {
+	return &WarehouseService{
+		repos: repos,
+		repo:  repos.WarehouseRepo,
+	}
+}
+
+// Create 创建仓库，并为已有分类初始化库存记录
+func (s *WarehouseService) Create(req *models.CreateWarehouseRequest) (*models.Warehouse, error) {
+	warehouseType := req.Type
+	if warehouseType == "" {
+		warehouseType = models.WarehouseTypeYard
+	}
+
+	warehouse := &models.Warehouse{
+		Code:     strings.ToUpper(strings.TrimSpace(req.Code)),
+		Name:     req.Name,
+		Type:     warehouseType,
+		Address:  req.Address,
+		IsActive: true,
+	}
+
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.WarehouseRepo.Create(warehouse); err != nil {
+			return err
+		}
+
+		categories, err := tx.CategoryRepo.GetAll()
+		if err != nil {
+			return err
+		}
+		for _, category := range categories {
+			inventory := &models.Inventory{
+				WarehouseID: warehouse.ID,
+				CategoryID:  category.ID,
+			}
+			if err := tx.InventoryRepo.Create(inventory); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return warehouse, nil
+}
+
+// GetByID 根据ID获取仓库
+func (s *WarehouseService) GetByID(id uint) (*models.Warehouse, error) {
+	return s.repo.GetByID(id)
+}
+
+// GetAll 获取所有启用的仓库
+func (s *WarehouseService) GetAll() ([]models.Warehouse, error) {
+	return s.repo.GetAll()
+}
+
+// Update 更新仓库信息
+func (s *WarehouseService) Update(id uint, req *models.UpdateWarehouseRequest) (*models.Warehouse, error) {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return nil, errors.New("warehouse not found")
+	}
+
+	// 构建更新字段映射
+	updates := make(map[string]interface{})
+	if req.Name != "" {
+		updates["name"] = req.Name
+	}
+	if req.Type != "" {
+		updates["type"] = req.Type
+	}
+	if req.Address != "" {
+		updates["address"] = req.Address
+	}
+	if len(updates) == 0 {
+		return nil, errors.New("no fields to update")
+	}
+
+	if err := s.repo.UpdateFields(id, updates); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(id)
+}
+
+// Delete 停用仓库，默认仓库和仍有库存的仓库不能停用
+func (s *WarehouseService) Delete(id uint) error {
+	warehouse, err := s.repo.GetByID(id)
+	if err != nil {
+		return errors.New("warehouse not found")
+	}
+	if warehouse.IsDefault {
+		return errors.New("default warehouse cannot be deleted")
+	}
+
+	inventories, err := s.repos.InventoryRepo.GetAll(id)
+	if err != nil {
+		return err
+	}
+	for _, inv := range inventories {
+		if inv.CurrentWeightKg >= weightTolerance {
+			return fmt.Errorf("warehouse still holds %.3f kg of category %d", inv.CurrentWeightKg, inv.CategoryID)
+		}
+	}
+	return s.repo.Delete(id)
+}
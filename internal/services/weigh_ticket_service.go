@@ -0,0 +1,357 @@
+package services
+
+import (
+	"battery-erp-backend/internal/models"
+	"battery-erp-backend/internal/repository"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WeighTicketService 磅单服务
+// 车辆进场首次称重开单，出场二次称重后计算净重，再转为入库或出库订单
+type WeighTicketService struct {
+	repos    *repository.Repositories
+	repo     *repository.WeighTicketRepository
+	scale    *ScaleService
+	inbound  *InboundService
+	outbound *OutboundService
+	// tareDeviationPercent 实测皮重偏离车辆登记皮重超过该百分比时标记磅单
+	tareDeviationPercent float64
+}
+
+// NewWeighTicketService 创建磅单服务实例，未填写重量的称重取自 scale，转订单复用入库/出库服务
+func NewWeighTicketService(repos *repository.Repositories, scale *ScaleService, inbound *InboundService, outbound *OutboundService, tareDeviationPercent float64) *WeighTicketService {
+	return &WeighTicketService{
+		repos:                repos,
+		repo:                 repos.WeighTicketRepo,
+		scale:                scale,
+		inbound:              inbound,
+		outbound:             outbound,
+		tareDeviationPercent: tareDeviationPercent,
+	}
+}
+
+// Create 首次称重，开具磅单
+// 指定车辆/司机档案时带出车号和司机信息；同一车辆只能有一张未二次称重的磅单
+func (s *WeighTicketService) Create(req *models.CreateWeighTicketRequest, userID uint) (*models.WeighTicket, error) {
+	carrier, err := resolveCarrier(s.repos, req.VehicleID, req.DriverID, req.CarNumber, req.DriverName, req.DriverPhone)
+	if err != nil {
+		return nil, err
+	}
+	if carrier.CarNumber == "" {
+		return nil, errors.New("car_number is required")
+	}
+
+	weight, fromScale, err := s.weigh(req.Weight)
+	if err != nil {
+		return nil, err
+	}
+
+	var ticket *models.WeighTicket
+	err = s.repos.Transaction(func(tx *repository.Repositories) error {
+		open, err := tx.WeighTicketRepo.GetOpenByCarNumber(carrier.CarNumber)
+		if err == nil {
+			return fmt.Errorf("vehicle %s already has open weigh ticket %s", carrier.CarNumber, open.TicketNo)
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		now := time.Now()
+		date := now.Format("20060102")
+		seq, err := tx.SequenceRepo.Next("weigh_ticket_" + date)
+		if err != nil {
+			return err
+		}
+
+		ticket = &models.WeighTicket{
+			TicketNo:       fmt.Sprintf("WT%s%04d", date, seq),
+			Direction:      req.Direction,
+			Status:         models.WeighTicketStatusOpen,
+			CarNumber:      carrier.CarNumber,
+			DriverName:     carrier.DriverName,
+			DriverPhone:    carrier.DriverPhone,
+			VehicleID:      carrier.VehicleID,
+			DriverID:       carrier.DriverID,
+			FirstWeight:    weight,
+			FirstFromScale: fromScale,
+			FirstWeighedBy: userID,
+			FirstWeighedAt: now,
+			Notes:          req.Notes,
+			CreatedBy:      userID,
+		}
+		return tx.WeighTicketRepo.Create(ticket)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ticket, nil
+}
+
+// SecondWeigh 二次称重，按磅单方向计算毛重、皮重和净重
+// 进场磅单二次称重须轻于首次，出场磅单须重于首次；车辆登记了皮重时核对实测皮重
+func (s *WeighTicketService) SecondWeigh(id uint, req *models.SecondWeighRequest, userID uint) (*models.WeighTicket, error) {
+	weight, fromScale, err := s.weigh(req.Weight)
+	if err != nil {
+		return nil, err
+	}
+
+	var ticket *models.WeighTicket
+	err = s.repos.Transaction(func(tx *repository.Repositories) error {
+		ticket, err = tx.WeighTicketRepo.GetByIDForUpdate(id)
+		if err != nil {
+			return errors.New("weigh ticket not found")
+		}
+		if ticket.Status != models.WeighTicketStatusOpen {
+			return fmt.Errorf("weigh ticket is %s, cannot weigh again", ticket.Status)
+		}
+
+		gross, tare := ticket.FirstWeight, weight
+		if ticket.Direction == models.WeighTicketDirectionOutbound {
+			gross, tare = weight, ticket.FirstWeight
+		}
+		if tare >= gross {
+			if ticket.Direction == models.WeighTicketDirectionOutbound {
+				return errors.New("second weighing of an outbound ticket must be heavier than the first")
+			}
+			return errors.New("second weighing of an inbound ticket must be lighter than the first")
+		}
+
+		now := time.Now()
+		ticket.SecondWeight = &weight
+		ticket.SecondFromScale = fromScale
+		ticket.SecondWeighedBy = &userID
+		ticket.SecondWeighedAt = &now
+		ticket.GrossWeight = gross
+		ticket.TareWeight = tare
+		ticket.NetWeight = roundWeight(gross - tare)
+		ticket.Status = models.WeighTicketStatusClosed
+		s.checkTare(tx, ticket)
+		return tx.WeighTicketRepo.UpdateFields(id, map[string]interface{}{
+			"second_weight":     weight,
+			"second_from_scale": fromScale,
+			"second_weighed_by": userID,
+			"second_weighed_at": now,
+			"gross_weight":      ticket.GrossWeight,
+			"tare_weight":       ticket.TareWeight,
+			"net_weight":        ticket.NetWeight,
+			"registered_tare":   ticket.RegisteredTare,
+			"tare_deviation":    ticket.TareDeviation,
+			"tare_flagged":      ticket.TareFlagged,
+			"status":            ticket.Status,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ticket, nil
+}
+
+// Convert 把已二次称重的磅单转为入库或出库订单，订单记录磅单ID，磅单记录订单号
+// 只有一项时该项重量取磅单净重 (入库还沿用磅单的毛重和皮重)，多项时各项重量之和须等于净重
+func (s *WeighTicketService) Convert(id uint, req *models.ConvertWeighTicketRequest, userID uint) (*models.WeighTicket, error) {
+	var ticket *models.WeighTicket
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		var err error
+		ticket, err = tx.WeighTicketRepo.GetByIDForUpdate(id)
+		if err != nil {
+			return errors.New("weigh ticket not found")
+		}
+		if ticket.Status != models.WeighTicketStatusClosed {
+			return fmt.Errorf("weigh ticket is %s, only closed tickets can be converted", ticket.Status)
+		}
+
+		weights, err := ticketItemWeights(ticket, req.Items)
+		if err != nil {
+			return err
+		}
+
+		var orderID uint
+		var orderNo string
+		if ticket.Direction == models.WeighTicketDirectionInbound {
+			if req.SellerID == nil && strings.TrimSpace(req.SupplierName) == "" {
+				return errors.New("seller_id or supplier_name is required for an inbound ticket")
+			}
+			order, err := s.inbound.create(tx, inboundRequestFromTicket(ticket, req, weights), userID)
+			if err != nil {
+				return err
+			}
+			orderID, orderNo = order.ID, order.OrderNo
+		} else {
+			order, err := s.outbound.create(tx, outboundRequestFromTicket(ticket, req, weights), userID)
+			if err != nil {
+				return err
+			}
+			orderID, orderNo = order.ID, order.OrderNo
+		}
+
+		ticket.Status = models.WeighTicketStatusConverted
+		ticket.OrderID = &orderID
+		ticket.OrderNo = orderNo
+		return tx.WeighTicketRepo.UpdateFields(id, map[string]interface{}{
+			"status":   ticket.Status,
+			"order_id": orderID,
+			"order_no": orderNo,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ticket, nil
+}
+
+// Cancel 作废未转订单的磅单
+func (s *WeighTicketService) Cancel(id uint) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		ticket, err := tx.WeighTicketRepo.GetByIDForUpdate(id)
+		if err != nil {
+			return errors.New("weigh ticket not found")
+		}
+		if ticket.Status != models.WeighTicketStatusOpen && ticket.Status != models.WeighTicketStatusClosed {
+			return fmt.Errorf("weigh ticket is %s, cannot cancel", ticket.Status)
+		}
+		return tx.WeighTicketRepo.UpdateFields(id, map[string]interface{}{"status": models.WeighTicketStatusCancelled})
+	})
+}
+
+// GetByID 根据ID获取磅单
+func (s *WeighTicketService) GetByID(id uint) (*models.WeighTicket, error) {
+	ticket, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("weigh ticket not found")
+	}
+	return ticket, nil
+}
+
+// GetAll 分页获取磅单
+func (s *WeighTicketService) GetAll(req *models.GetWeighTicketsRequest) (*models.GetWeighTicketsResponse, error) {
+	tickets, total, err := s.repo.GetAll(req)
+	if err != nil {
+		return nil, err
+	}
+	return &models.GetWeighTicketsResponse{
+		Tickets: tickets,
+		Total:   total,
+	}, nil
+}
+
+// weigh 取本次称重重量，未填写时取地磅当前稳定读数
+func (s *WeighTicketService) weigh(weight *float64) (float64, bool, error) {
+	if weight != nil {
+		return roundWeight(*weight), false, nil
+	}
+	reading, err := s.scale.stableReading()
+	if err != nil {
+		return 0, false, fmt.Errorf("weight is required when the scale is unavailable: %w", err)
+	}
+	if reading.Weight <= 0 {
+		return 0, false, errors.New("scale reading must be greater than 0")
+	}
+	return roundWeight(reading.Weight), true, nil
+}
+
+// checkTare 对比实测皮重和车辆档案的登记皮重，偏离超过阈值时标记磅单
+// 车辆未登记或未登记皮重时不检查
+func (s *WeighTicketService) checkTare(tx *repository.Repositories, ticket *models.WeighTicket) {
+	ticket.RegisteredTare, ticket.TareDeviation, ticket.TareFlagged = 0, 0, false
+	if ticket.VehicleID == nil {
+		return
+	}
+	vehicle, err := tx.VehicleRepo.GetByID(*ticket.VehicleID)
+	if err != nil || vehicle.TareWeight <= 0 {
+		return
+	}
+
+	ticket.RegisteredTare = vehicle.TareWeight
+	ticket.TareDeviation = roundWeight(ticket.TareWeight - vehicle.TareWeight)
+	ticket.TareFlagged = s.tareDeviationPercent > 0 &&
+		math.Abs(ticket.TareDeviation)/vehicle.TareWeight*100 > s.tareDeviationPercent
+}
+
+// ticketItemWeights 计算磅单转订单时各项的重量
+func ticketItemWeights(ticket *models.WeighTicket, items []models.ConvertWeighTicketItem) ([]float64, error) {
+	if len(items) == 1 && items[0].Weight == 0 {
+		return []float64{ticket.NetWeight}, nil
+	}
+
+	weights := make([]float64, len(items))
+	var total float64
+	for i, item := range items {
+		if item.Weight <= 0 {
+			return nil, errors.New("weight is required for every item when a ticket is split into several items")
+		}
+		weights[i] = item.Weight
+		total += item.Weight
+	}
+	if math.Abs(total-ticket.NetWeight) >= weightTolerance {
+		return nil, fmt.Errorf("item weights add up to %.3f kg but the ticket net weight is %.3f kg", total, ticket.NetWeight)
+	}
+	return weights, nil
+}
+
+// inboundRequestFromTicket 按磅单生成入库订单请求
+// 整车一项时沿用磅单毛重、皮重和地磅采集标记，拆分多项时各项毛重为分配重量、皮重为 0
+func inboundRequestFromTicket(ticket *models.WeighTicket, req *models.ConvertWeighTicketRequest, weights []float64) *models.CreateInboundOrderRequest {
+	ticketID := ticket.ID
+	result := &models.CreateInboundOrderRequest{
+		WarehouseID:   req.WarehouseID,
+		SellerID:      req.SellerID,
+		SupplierName:  req.SupplierName,
+		Status:        req.Status,
+		Notes:         req.Notes,
+		WeighTicketID: &ticketID,
+	}
+	for i, item := range req.Items {
+		orderItem := models.CreateInboundOrderItem{
+			CategoryID:        item.CategoryID,
+			GrossWeight:       weights[i],
+			UnitPrice:         item.UnitPrice,
+			Grade:             item.Grade,
+			InboundDeductions: item.InboundDeductions,
+			TraceCodes:        item.TraceCodes,
+		}
+		if len(req.Items) == 1 {
+			// 进场磅单首次称重为毛重，二次称重为皮重
+			orderItem.GrossWeight = ticket.GrossWeight
+			orderItem.TareWeight = ticket.TareWeight
+			orderItem.GrossFromScale = ticket.FirstFromScale
+			orderItem.TareFromScale = ticket.SecondFromScale
+		}
+		result.Items = append(result.Items, orderItem)
+	}
+	return result
+}
+
+// outboundRequestFromTicket 按磅单生成出库订单请求，车辆和司机取自磅单
+func outboundRequestFromTicket(ticket *models.WeighTicket, req *models.ConvertWeighTicketRequest, weights []float64) *models.CreateOutboundOrderRequest {
+	ticketID := ticket.ID
+	result := &models.CreateOutboundOrderRequest{
+		WarehouseID:     req.WarehouseID,
+		CustomerID:      req.CustomerID,
+		DeliveryAddress: req.DeliveryAddress,
+		VehicleID:       ticket.VehicleID,
+		CarNumber:       ticket.CarNumber,
+		DriverID:        ticket.DriverID,
+		DriverName:      ticket.DriverName,
+		DriverPhone:     ticket.DriverPhone,
+		Status:          req.Status,
+		Notes:           req.Notes,
+		CreditOverride:  req.CreditOverride,
+		WeighTicketID:   &ticketID,
+	}
+	for i, item := range req.Items {
+		result.Items = append(result.Items, models.CreateOutboundOrderItem{
+			CategoryID: item.CategoryID,
+			Weight:     weights[i],
+			UnitPrice:  item.UnitPrice,
+			Lots:       item.Lots,
+			TraceCodes: item.TraceCodes,
+		})
+	}
+	return result
+}
@@ -51,9 +51,16 @@ func main() {
 
 	// Initialize repositories
 	repos := repository.NewRepositories(db)
+	if err := repos.AutoMigrate(); err != nil {
+		log.Fatal("Failed to migrate database:", err)
+	}
 
 	// Initialize services
-	services := services.NewServices(repos)
+	services := services.NewServices(repos, cfg)
+
+	// Start reading the weighbridge in the background (no-op when disabled)
+	services.ScaleService.Start()
+	defer services.ScaleService.Close()
 
 	gin.SetMode(cfg.Server.Mode)
 	// Initialize router